@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"sync/atomic"
 
 	"golang.org/x/crypto/bcrypt"
 
@@ -15,6 +16,8 @@ import (
 type Handler struct {
 	store *store.Store
 	jwt   *jwtjw.Service
+	// draining is set once the service is shutting down
+	draining atomic.Bool
 }
 
 func New(s *store.Store, j *jwtjw.Service) *Handler {
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+type readyResp struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// Healthz reports that the process is up.
+func (h *Handler) Healthz(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("ok"))
+}
+
+// Readyz reports whether the service can take requests: not once it is
+// shutting down, nor while Postgres can't be reached.
+func (h *Handler) Readyz(w http.ResponseWriter, r *http.Request) {
+	resp := readyResp{Status: "ok", Checks: map[string]string{"postgres": "ok"}}
+	status := http.StatusOK
+
+	if h.draining.Load() {
+		resp.Status = "shutting down"
+		status = http.StatusServiceUnavailable
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+	defer cancel()
+	if err := h.store.Ping(ctx); err != nil {
+		resp.Status = "unavailable"
+		resp.Checks["postgres"] = err.Error()
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// Drain fails the readiness checks from now on, so no new requests are
+// routed to the service while it shuts down.
+func (h *Handler) Drain() {
+	h.draining.Store(true)
+}
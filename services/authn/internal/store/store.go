@@ -23,6 +23,11 @@ func New(db *pgxpool.Pool) *Store {
 	return &Store{db: db}
 }
 
+// Ping checks that the database can be reached.
+func (s *Store) Ping(ctx context.Context) error {
+	return s.db.Ping(ctx)
+}
+
 func InitDB(ctx context.Context, db *pgxpool.Pool) error {
 	q := `CREATE TABLE IF NOT EXISTS users (
 		id BIGSERIAL PRIMARY KEY,
@@ -2,9 +2,12 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/Farzan-Kh/guddy-cn/services/authn/internal/handler"
@@ -55,14 +58,40 @@ func main() {
 	r.HandleFunc("/signup", h.SignUp).Methods("POST")
 	r.HandleFunc("/login", h.Login).Methods("POST")
 	r.HandleFunc("/validate", h.Validate).Methods("GET")
+	r.HandleFunc("/healthz", h.Healthz).Methods("GET")
+	r.HandleFunc("/readyz", h.Readyz).Methods("GET")
 
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8084"
 	}
 
-	log.Printf("listening on :%s", port)
-	if err := http.ListenAndServe(":"+port, r); err != nil {
-		log.Fatalf("server: %v", err)
+	// Time given to in-flight requests once SIGTERM is received
+	shutdownTimeout := 15 * time.Second
+	if v := os.Getenv("AUTHN_SHUTDOWN_TIMEOUT"); v != "" {
+		shutdownTimeout, err = time.ParseDuration(v)
+		if err != nil || shutdownTimeout <= 0 {
+			log.Fatalf("AUTHN_SHUTDOWN_TIMEOUT must be a positive duration, e.g. 15s")
+		}
+	}
+
+	srv := &http.Server{Addr: ":" + port, Handler: r}
+	go func() {
+		log.Printf("listening on :%s", port)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("server: %v", err)
+		}
+	}()
+
+	stop, cancel := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+	<-stop.Done()
+
+	log.Printf("shutting down, draining requests for up to %s", shutdownTimeout)
+	h.Drain()
+	shutdownCtx, cancelShutdown := context.WithTimeout(ctx, shutdownTimeout)
+	defer cancelShutdown()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("shutdown: %v", err)
 	}
 }
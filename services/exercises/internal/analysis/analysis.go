@@ -0,0 +1,200 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/models"
+)
+
+const (
+	// secondaryWeight is how much a set counts for a muscle it trains
+	// secondarily.
+	secondaryWeight = 0.5
+	// minGroupSets is the fewest weighted sets a major group should get over
+	// the program before it's flagged as undertrained.
+	minGroupSets = 4
+	// maxRatio bounds the push:pull and quadriceps:hamstrings ratios before
+	// the program is flagged as unbalanced, either way.
+	maxRatio = 1.5
+)
+
+// Warning codes
+const (
+	Untrained      = "untrained"
+	Undertrained   = "undertrained"
+	PushHeavy      = "push_heavy"
+	PullHeavy      = "pull_heavy"
+	QuadDominant   = "quad_dominant"
+	HamstringHeavy = "hamstring_heavy"
+)
+
+// group is a major muscle group as the catalog names its muscles.
+type group struct {
+	name    string
+	muscles []string
+}
+
+var majorGroups = []group{
+	{"chest", []string{"chest"}},
+	{"back", []string{"lats", "middle back"}},
+	{"shoulders", []string{"shoulders"}},
+	{"quadriceps", []string{"quadriceps"}},
+	{"hamstrings", []string{"hamstrings"}},
+	{"glutes", []string{"glutes"}},
+	{"core", []string{"abdominals"}},
+}
+
+// Analyze measures the balance of the items of a program. Exercises without
+// any muscle flagged as primary, imported before the flag existed, count
+// every muscle as primary.
+func Analyze(rows []db.GetProgramAnalysisItemsRow) models.ProgramAnalysis {
+	var result models.ProgramAnalysis
+	volumes := make(map[string]*models.MuscleSets)
+	volume := func(muscle string) *models.MuscleSets {
+		if volumes[muscle] == nil {
+			volumes[muscle] = &models.MuscleSets{Muscle: muscle}
+		}
+		return volumes[muscle]
+	}
+	equipment := make(map[db.EquipmentT]bool)
+
+	for _, row := range rows {
+		sets := int(row.Sets)
+		result.TotalSets += sets
+
+		primary, secondary := row.PrimaryMuscles, row.SecondaryMuscles
+		if len(primary) == 0 {
+			primary, secondary = secondary, nil
+		}
+		for _, m := range primary {
+			volume(m).PrimarySets += sets
+		}
+		for _, m := range secondary {
+			if !slices.Contains(primary, m) {
+				volume(m).SecondarySets += sets
+			}
+		}
+
+		if row.Force.Valid {
+			switch row.Force.ForceT {
+			case db.ForceTPush:
+				result.PushPull.PushSets += sets
+			case db.ForceTPull:
+				result.PushPull.PullSets += sets
+			case db.ForceTStatic:
+				result.PushPull.StaticSets += sets
+			}
+		}
+		if row.Mechanic.Valid {
+			switch row.Mechanic.MechanicT {
+			case db.MechanicTCompound:
+				result.Mechanics.CompoundSets += sets
+			case db.MechanicTIsolation:
+				result.Mechanics.IsolationSets += sets
+			}
+		}
+		if row.Equipment.Valid {
+			equipment[row.Equipment.EquipmentT] = true
+		}
+	}
+
+	result.Muscles = make([]models.MuscleSets, 0, len(volumes))
+	for _, v := range volumes {
+		v.WeightedSets = float64(v.PrimarySets) + secondaryWeight*float64(v.SecondarySets)
+		result.Muscles = append(result.Muscles, *v)
+	}
+	sort.Slice(result.Muscles, func(i, j int) bool {
+		a, b := result.Muscles[i], result.Muscles[j]
+		if a.WeightedSets != b.WeightedSets {
+			return a.WeightedSets > b.WeightedSets
+		}
+		return a.Muscle < b.Muscle
+	})
+
+	result.PushPull.Ratio = ratio(result.PushPull.PushSets, result.PushPull.PullSets)
+	result.Mechanics.Ratio = ratio(result.Mechanics.CompoundSets, result.Mechanics.IsolationSets)
+
+	result.Equipment = make([]db.EquipmentT, 0, len(equipment))
+	for eq := range equipment {
+		result.Equipment = append(result.Equipment, eq)
+	}
+	slices.Sort(result.Equipment)
+
+	result.Warnings = warnings(result, volumes)
+	return result
+}
+
+func warnings(result models.ProgramAnalysis, volumes map[string]*models.MuscleSets) []models.AnalysisWarning {
+	found := make([]models.AnalysisWarning, 0)
+	groupSets := make(map[string]float64, len(majorGroups))
+	for _, g := range majorGroups {
+		var sets float64
+		for _, m := range g.muscles {
+			if v := volumes[m]; v != nil {
+				sets += v.WeightedSets
+			}
+		}
+		groupSets[g.name] = sets
+
+		switch {
+		case sets == 0:
+			found = append(found, models.AnalysisWarning{
+				Code:    Untrained,
+				Group:   g.name,
+				Message: fmt.Sprintf("no exercise trains the %s", g.name),
+			})
+		case sets < minGroupSets:
+			found = append(found, models.AnalysisWarning{
+				Code:    Undertrained,
+				Group:   g.name,
+				Message: fmt.Sprintf("only %s weighted sets train the %s, at least %d are recommended", number(sets), g.name, minGroupSets),
+			})
+		}
+	}
+
+	push, pull := float64(result.PushPull.PushSets), float64(result.PushPull.PullSets)
+	switch {
+	case push > 0 && push > maxRatio*pull:
+		found = append(found, models.AnalysisWarning{
+			Code:    PushHeavy,
+			Message: fmt.Sprintf("push-heavy: %d push sets for %d pull sets", result.PushPull.PushSets, result.PushPull.PullSets),
+		})
+	case pull > 0 && pull > maxRatio*push:
+		found = append(found, models.AnalysisWarning{
+			Code:    PullHeavy,
+			Message: fmt.Sprintf("pull-heavy: %d pull sets for %d push sets", result.PushPull.PullSets, result.PushPull.PushSets),
+		})
+	}
+
+	// Both untrained is already reported above
+	quads, hams := groupSets["quadriceps"], groupSets["hamstrings"]
+	switch {
+	case hams > 0 && quads > maxRatio*hams:
+		found = append(found, models.AnalysisWarning{
+			Code:    QuadDominant,
+			Message: fmt.Sprintf("quad-dominant: %s weighted sets for the quadriceps and %s for the hamstrings", number(quads), number(hams)),
+		})
+	case quads > 0 && hams > maxRatio*quads:
+		found = append(found, models.AnalysisWarning{
+			Code:    HamstringHeavy,
+			Message: fmt.Sprintf("hamstring-heavy: %s weighted sets for the hamstrings and %s for the quadriceps", number(hams), number(quads)),
+		})
+	}
+	return found
+}
+
+func ratio(a, b int) *float64 {
+	if b == 0 {
+		return nil
+	}
+	r := math.Round(float64(a)/float64(b)*100) / 100
+	return &r
+}
+
+func number(f float64) string {
+	return fmt.Sprintf("%g", f)
+}
@@ -0,0 +1,161 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+)
+
+// exerciseBest is the best performance on one exercise within a workout.
+type exerciseBest struct {
+	epley, brzycki float64
+	records        map[db.RecordTypeT]candidate
+}
+
+type candidate struct {
+	value float64
+	setID int64
+}
+
+// ProcessWorkout folds a finished workout into the cached analytics: the
+// weekly volume per muscle, the estimated 1RM per exercise and the personal
+// records, returning the records it broke. A workout is only ever processed
+// once, later calls are no-ops, so q should run inside a transaction for the
+// claim and the updates to be atomic.
+func ProcessWorkout(ctx context.Context, q *db.Queries, workout db.Workout) ([]db.PersonalRecord, error) {
+	claimed, err := q.MarkWorkoutAnalyzed(ctx, workout.ID)
+	if err != nil {
+		return nil, fmt.Errorf("claiming workout: %w", err)
+	}
+	if claimed == 0 {
+		return nil, nil
+	}
+
+	if err := q.AddWorkoutMuscleVolume(ctx, workout.ID); err != nil {
+		return nil, fmt.Errorf("adding muscle volume: %w", err)
+	}
+
+	sets, err := q.GetWorkoutSets(ctx, workout.ID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching workout sets: %w", err)
+	}
+
+	bests := make(map[int32]*exerciseBest)
+	for _, set := range sets {
+		if !set.Reps.Valid || !set.WeightKg.Valid {
+			continue
+		}
+		reps, weight := int(set.Reps.Int32), set.WeightKg.Float64
+		if reps < 1 || weight <= 0 {
+			continue
+		}
+
+		best, ok := bests[set.ExerciseID]
+		if !ok {
+			best = &exerciseBest{records: make(map[db.RecordTypeT]candidate)}
+			bests[set.ExerciseID] = best
+		}
+		best.offer(db.RecordTypeTWeight, weight, set.ID)
+		best.offer(db.RecordTypeTVolume, weight*float64(reps), set.ID)
+		if e1rm, ok := Epley.Estimate(weight, reps); ok {
+			best.offer(db.RecordTypeTE1rm, e1rm, set.ID)
+			best.epley = max(best.epley, e1rm)
+		}
+		if e1rm, ok := Brzycki.Estimate(weight, reps); ok {
+			best.brzycki = max(best.brzycki, e1rm)
+		}
+	}
+	if len(bests) == 0 {
+		return nil, nil
+	}
+
+	exerciseIDs := make([]int32, 0, len(bests))
+	for id, best := range bests {
+		exerciseIDs = append(exerciseIDs, id)
+		if best.epley == 0 {
+			continue
+		}
+		err := q.UpsertExerciseE1rm(ctx, db.UpsertExerciseE1rmParams{
+			WorkoutID:   workout.ID,
+			ExerciseID:  id,
+			UserID:      workout.UserID,
+			PerformedAt: workout.StartedAt,
+			EpleyKg:     best.epley,
+			BrzyckiKg:   best.brzycki,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("storing e1rm: %w", err)
+		}
+	}
+
+	previousRows, err := q.GetBestRecords(ctx, db.GetBestRecordsParams{UserID: workout.UserID, ExerciseIds: exerciseIDs})
+	if err != nil {
+		return nil, fmt.Errorf("fetching records: %w", err)
+	}
+	previous := make(map[int32]map[db.RecordTypeT]float64)
+	for _, row := range previousRows {
+		if previous[row.ExerciseID] == nil {
+			previous[row.ExerciseID] = make(map[db.RecordTypeT]float64)
+		}
+		previous[row.ExerciseID][row.RecordType] = row.Best
+	}
+
+	var broken []db.PersonalRecord
+	for id, best := range bests {
+		for recordType, c := range best.records {
+			params := db.InsertPersonalRecordParams{
+				UserID:       workout.UserID,
+				ExerciseID:   id,
+				RecordType:   recordType,
+				Value:        c.value,
+				WorkoutID:    workout.ID,
+				WorkoutSetID: c.setID,
+				AchievedAt:   workout.FinishedAt,
+			}
+			if old, ok := previous[id][recordType]; ok {
+				if c.value <= old {
+					continue
+				}
+				params.PreviousValue.Float64, params.PreviousValue.Valid = old, true
+			}
+			record, err := q.InsertPersonalRecord(ctx, params)
+			if err != nil {
+				return nil, fmt.Errorf("storing record: %w", err)
+			}
+			broken = append(broken, record)
+		}
+	}
+	return broken, nil
+}
+
+// CatchUp processes the finished workouts of a user the analytics haven't
+// seen yet, e.g. ones finished before the analytics existed. Each workout is
+// processed in its own transaction.
+func CatchUp(ctx context.Context, userID int64) error {
+	pending, err := db.Queriez.GetUnanalyzedWorkouts(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, workout := range pending {
+		tx, err := db.GetPool().Begin(ctx)
+		if err != nil {
+			return err
+		}
+		if _, err := ProcessWorkout(ctx, db.Queriez.WithTx(tx), workout); err != nil {
+			tx.Rollback(ctx)
+			return err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *exerciseBest) offer(recordType db.RecordTypeT, value float64, setID int64) {
+	if c, ok := b.records[recordType]; !ok || value > c.value {
+		b.records[recordType] = candidate{value: value, setID: setID}
+	}
+}
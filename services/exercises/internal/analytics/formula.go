@@ -0,0 +1,70 @@
+package analytics
+
+import "fmt"
+
+// Formula estimates a one repetition maximum from a set of reps at a weight.
+type Formula string
+
+const (
+	Epley   Formula = "epley"
+	Brzycki Formula = "brzycki"
+)
+
+// MaxEstimateReps bounds the sets used for estimates, the formulas drift
+// quickly past a dozen reps.
+const MaxEstimateReps = 12
+
+func ParseFormula(s string) (Formula, error) {
+	switch Formula(s) {
+	case "", Epley:
+		return Epley, nil
+	case Brzycki:
+		return Brzycki, nil
+	default:
+		return "", fmt.Errorf("unknown formula %q, expected epley or brzycki", s)
+	}
+}
+
+// Estimate returns the estimated 1RM for reps at weightKg, and false when the
+// set can't be used for an estimate.
+func (f Formula) Estimate(weightKg float64, reps int) (float64, bool) {
+	return f.EstimateWithReserve(weightKg, reps, 0)
+}
+
+// EstimateWithReserve is Estimate for a set stopped with reserve reps left,
+// which count as done. The reserve is fractional for half-step RPEs, e.g.
+// 1.5 at RPE 8.5.
+func (f Formula) EstimateWithReserve(weightKg float64, reps int, reserve float64) (float64, bool) {
+	total := float64(reps) + reserve
+	if weightKg <= 0 || reps < 1 || reserve < 0 || total > MaxEstimateReps {
+		return 0, false
+	}
+	if total == 1 {
+		return weightKg, true
+	}
+	switch f {
+	case Brzycki:
+		return weightKg * 36 / (37 - total), true
+	default:
+		return weightKg * (1 + total/30), true
+	}
+}
+
+// Weight returns the weight that can be lifted for reps with reserve reps
+// left given a 1RM of oneRMKg, the inverse of EstimateWithReserve, and false
+// when the reps are out of the range estimates are made for.
+func (f Formula) Weight(oneRMKg float64, reps int, reserve float64) (float64, bool) {
+	total := float64(reps) + reserve
+	if oneRMKg <= 0 || reps < 1 || reserve < 0 || total > MaxEstimateReps {
+		return 0, false
+	}
+	if total == 1 {
+		return oneRMKg, true
+	}
+	switch f {
+	case Brzycki:
+		return oneRMKg * (37 - total) / 36, true
+	default:
+		return oneRMKg / (1 + total/30), true
+	}
+}
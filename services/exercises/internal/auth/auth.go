@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+// UserIDHeader carries the ID of the authenticated user. The gateway
+// validates the bearer token against the authn service and sets it, any
+// value sent by the client is dropped there. The HTTP API must therefore
+// only be reachable through the gateway.
+const UserIDHeader = "X-User-ID"
+
+type contextKey struct{}
+
+// Middleware stores the forwarded user ID, if any, in the request context.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if header := r.Header.Get(UserIDHeader); header != "" {
+			id, err := strconv.ParseInt(header, 10, 64)
+			if err != nil {
+				http.Error(w, "Invalid user ID", http.StatusUnauthorized)
+				return
+			}
+			r = r.WithContext(WithUserID(r.Context(), id))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireUser rejects requests without an authenticated user.
+func RequireUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := UserID(r.Context()); !ok {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// WithUserID returns a copy of ctx carrying the authenticated user.
+func WithUserID(ctx context.Context, id int64) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// UserID returns the authenticated user of the request, if any.
+func UserID(ctx context.Context) (int64, bool) {
+	id, ok := ctx.Value(contextKey{}).(int64)
+	return id, ok
+}
@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ErrInvalidToken is returned for bearer tokens the authn service rejects.
+var ErrInvalidToken = errors.New("invalid token")
+
+// TokenValidator resolves bearer tokens into user IDs with the /validate
+// endpoint of the authn service, as the gateway does for HTTP requests.
+type TokenValidator struct {
+	URL    string
+	Client *http.Client
+}
+
+func NewTokenValidator(authnURL string) *TokenValidator {
+	return &TokenValidator{
+		URL:    strings.TrimSuffix(authnURL, "/") + "/validate",
+		Client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// UserID returns the user the Authorization value authenticates.
+func (v *TokenValidator) UserID(ctx context.Context, authorization string) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.URL, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Authorization", authorization)
+
+	res, err := v.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusUnauthorized {
+		return 0, ErrInvalidToken
+	}
+	if res.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("unexpected authn status: %s", res.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(res.Body, 64))
+	if err != nil {
+		return 0, err
+	}
+	id, err := strconv.ParseInt(strings.TrimSpace(string(body)), 10, 64)
+	if err != nil {
+		return 0, ErrInvalidToken
+	}
+	return id, nil
+}
+
+// UnaryInterceptor is Middleware for gRPC calls. gRPC doesn't go through
+// the gateway, so the bearer token in the authorization metadata is
+// validated here and x-user-id metadata is never trusted. Calls without a
+// token are anonymous.
+func (v *TokenValidator) UnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 && values[0] != "" {
+			id, err := v.UserID(ctx, values[0])
+			if errors.Is(err, ErrInvalidToken) {
+				return nil, status.Error(codes.Unauthenticated, "Invalid token")
+			}
+			if err != nil {
+				log.Printf("Error at validating gRPC token: %v, method: %s", err, info.FullMethod)
+				return nil, status.Error(codes.Unavailable, "Failed to validate token")
+			}
+			ctx = WithUserID(ctx, id)
+		}
+	}
+	return handler(ctx, req)
+}
@@ -0,0 +1,166 @@
+package calendar
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	// The runtime image ships without a zoneinfo database
+	_ "time/tzdata"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/models"
+)
+
+const (
+	DateLayout = "2006-01-02"
+	TimeLayout = "15:04"
+
+	defaultDurationMinutes = 60
+	maxDurationMinutes     = 600
+	maxWeeks               = 52
+)
+
+// Schedule is a validated program schedule.
+type Schedule struct {
+	StartDate time.Time // midnight UTC of the first date
+	Weekdays  []time.Weekday
+	Weeks     int
+	StartTime *time.Duration // time of day, all-day sessions when nil
+	Duration  time.Duration
+	Location  *time.Location
+}
+
+// Session is one scheduled occurrence of a program day. Sessions are
+// numbered from 1 in date order.
+type Session struct {
+	Number int
+	Day    int
+	Date   time.Time // midnight UTC of the date
+	Start  *time.Time
+	End    *time.Time
+}
+
+// NewSchedule validates a request and fills in the defaults: sessions of 60
+// minutes in UTC.
+func NewSchedule(req models.ScheduleRequest) (Schedule, error) {
+	s := Schedule{Weeks: req.Weeks, Location: time.UTC}
+
+	start, err := time.Parse(DateLayout, req.StartDate)
+	if err != nil {
+		return s, fmt.Errorf("startDate must be a date such as 2026-11-02")
+	}
+	s.StartDate = start
+
+	if len(req.Weekdays) == 0 {
+		return s, fmt.Errorf("at least one weekday is required")
+	}
+	for _, name := range req.Weekdays {
+		day, ok := ParseWeekday(name)
+		if !ok {
+			return s, fmt.Errorf("unknown weekday %q", name)
+		}
+		if !slices.Contains(s.Weekdays, day) {
+			s.Weekdays = append(s.Weekdays, day)
+		}
+	}
+	slices.Sort(s.Weekdays)
+
+	if s.Weeks < 1 || s.Weeks > maxWeeks {
+		return s, fmt.Errorf("weeks must be between 1 and %d", maxWeeks)
+	}
+
+	if req.StartTime != nil {
+		t, err := time.Parse(TimeLayout, *req.StartTime)
+		if err != nil {
+			return s, fmt.Errorf("startTime must be a time such as 18:30")
+		}
+		offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+		s.StartTime = &offset
+	}
+
+	minutes := req.DurationMinutes
+	if minutes == 0 {
+		minutes = defaultDurationMinutes
+	}
+	if minutes < 1 || minutes > maxDurationMinutes {
+		return s, fmt.Errorf("durationMinutes must be between 1 and %d", maxDurationMinutes)
+	}
+	s.Duration = time.Duration(minutes) * time.Minute
+
+	if req.Timezone != "" {
+		loc, err := time.LoadLocation(req.Timezone)
+		if err != nil {
+			return s, fmt.Errorf("unknown timezone %q", req.Timezone)
+		}
+		s.Location = loc
+	}
+	return s, nil
+}
+
+// ScheduleFromRow reads a stored schedule.
+func ScheduleFromRow(row db.ProgramSchedule) (Schedule, error) {
+	s := Schedule{
+		StartDate: row.StartDate.Time,
+		Weeks:     int(row.Weeks),
+		Duration:  time.Duration(row.DurationMinutes) * time.Minute,
+	}
+	for _, day := range row.Weekdays {
+		s.Weekdays = append(s.Weekdays, time.Weekday(day))
+	}
+	if row.StartTime.Valid {
+		offset := time.Duration(row.StartTime.Microseconds) * time.Microsecond
+		s.StartTime = &offset
+	}
+	loc, err := time.LoadLocation(row.Timezone)
+	if err != nil {
+		return s, err
+	}
+	s.Location = loc
+	return s, nil
+}
+
+// ParseWeekday reads a weekday name such as "monday" or "Mon".
+func ParseWeekday(name string) (time.Weekday, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if len(name) < 3 {
+		return 0, false
+	}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.HasPrefix(strings.ToLower(day.String()), name) {
+			return day, true
+		}
+	}
+	return 0, false
+}
+
+// Sessions lays out every session of the schedule, walking through the
+// given program days in order and starting over after the last one.
+func (s Schedule) Sessions(days []int) []Session {
+	sessions := make([]Session, 0)
+	if len(days) == 0 {
+		return sessions
+	}
+
+	for offset := 0; offset < s.Weeks*7; offset++ {
+		date := s.StartDate.AddDate(0, 0, offset)
+		if !slices.Contains(s.Weekdays, date.Weekday()) {
+			continue
+		}
+		session := Session{
+			Number: len(sessions) + 1,
+			Day:    days[len(sessions)%len(days)],
+			Date:   date,
+		}
+		if s.StartTime != nil {
+			// Built from the wall clock so sessions keep their time of day
+			// across daylight saving changes.
+			clock := *s.StartTime
+			start := time.Date(date.Year(), date.Month(), date.Day(), int(clock.Hours()), int(clock.Minutes())%60, 0, 0, s.Location)
+			end := start.Add(s.Duration)
+			session.Start, session.End = &start, &end
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions
+}
@@ -0,0 +1,266 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds the settings of the service. They are read from the
+// defaults, then the YAML file if any, then the environment, each
+// overriding the one before.
+type Config struct {
+	HTTPAddr string `yaml:"http_addr"`
+	GRPCAddr string `yaml:"grpc_addr"`
+	// AuthnURL is the authn service the bearer tokens of gRPC calls are
+	// validated with, HTTP requests come validated by the gateway.
+	AuthnURL string `yaml:"authn_url"`
+	// ShutdownTimeout is the time in-flight requests get to finish once
+	// the service is told to stop.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	LogLevel        string        `yaml:"log_level"`
+	RedisAddr       string        `yaml:"redis_addr"`
+	Database        Database      `yaml:"database"`
+	CORS            CORS          `yaml:"cors"`
+	Features        Features      `yaml:"features"`
+}
+
+type Database struct {
+	URL      string `yaml:"url"`
+	MinConns int32  `yaml:"min_conns"`
+	MaxConns int32  `yaml:"max_conns"`
+	// StatementTimeout cancels the statements running longer, 0 disables
+	// it.
+	StatementTimeout time.Duration `yaml:"statement_timeout"`
+}
+
+// CORS lists the origins browsers may call the API from, "*" allows any.
+// Cross-origin requests are refused when it is empty.
+type CORS struct {
+	AllowedOrigins []string      `yaml:"allowed_origins"`
+	MaxAge         time.Duration `yaml:"max_age"`
+}
+
+// Features turns the optional parts of the API on and off.
+type Features struct {
+	GraphQL      bool `yaml:"graphql"`
+	GRPC         bool `yaml:"grpc"`
+	Webhooks     bool `yaml:"webhooks"`
+	LiveSessions bool `yaml:"live_sessions"`
+	Sync         bool `yaml:"sync"`
+}
+
+func Default() Config {
+	return Config{
+		HTTPAddr:        ":8081",
+		GRPCAddr:        ":9081",
+		AuthnURL:        "http://authn-service:8084",
+		ShutdownTimeout: 15 * time.Second,
+		LogLevel:        "info",
+		Database: Database{
+			MinConns: 0,
+			MaxConns: 10,
+		},
+		CORS: CORS{MaxAge: 10 * time.Minute},
+		Features: Features{
+			GraphQL:      true,
+			GRPC:         true,
+			Webhooks:     true,
+			LiveSessions: true,
+			Sync:         true,
+		},
+	}
+}
+
+// Load reads the config from the YAML file at path, unless path is empty,
+// and the environment, and validates it. Every problem found is reported
+// in the error.
+func Load(path string) (Config, error) {
+	cfg := Default()
+	if path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return cfg, fmt.Errorf("couldn't read config file: %w", err)
+		}
+		defer f.Close()
+		dec := yaml.NewDecoder(f)
+		// Misspelled settings would be ignored silently otherwise
+		dec.KnownFields(true)
+		if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+			return cfg, fmt.Errorf("invalid config file %s: %w", path, err)
+		}
+	}
+
+	errs := cfg.fromEnv(os.LookupEnv)
+	errs = append(errs, cfg.Validate()...)
+	return cfg, errors.Join(errs...)
+}
+
+// fromEnv overrides the settings whose variable is set. DATABASE_URL and
+// REDIS_ADDR are shared with the other services, the rest is prefixed.
+func (c *Config) fromEnv(lookup func(string) (string, bool)) []error {
+	var errs []error
+	str := func(name string, dst *string) {
+		if v, ok := lookup(name); ok {
+			*dst = v
+		}
+	}
+	conns := func(name string, dst *int32) {
+		if v, ok := lookup(name); ok {
+			n, err := strconv.ParseInt(v, 10, 32)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s must be an integer", name))
+				return
+			}
+			*dst = int32(n)
+		}
+	}
+	duration := func(name string, dst *time.Duration) {
+		if v, ok := lookup(name); ok {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s must be a duration, e.g. 30s", name))
+				return
+			}
+			*dst = d
+		}
+	}
+	toggle := func(name string, dst *bool) {
+		if v, ok := lookup(name); ok {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s must be true or false", name))
+				return
+			}
+			*dst = b
+		}
+	}
+
+	str("EXERCISES_HTTP_ADDR", &c.HTTPAddr)
+	str("EXERCISES_GRPC_ADDR", &c.GRPCAddr)
+	str("EXERCISES_AUTHN_URL", &c.AuthnURL)
+	duration("EXERCISES_SHUTDOWN_TIMEOUT", &c.ShutdownTimeout)
+	str("EXERCISES_LOG_LEVEL", &c.LogLevel)
+	str("REDIS_ADDR", &c.RedisAddr)
+	str("DATABASE_URL", &c.Database.URL)
+	conns("EXERCISES_DB_MIN_CONNS", &c.Database.MinConns)
+	conns("EXERCISES_DB_MAX_CONNS", &c.Database.MaxConns)
+	duration("EXERCISES_DB_STATEMENT_TIMEOUT", &c.Database.StatementTimeout)
+	if v, ok := lookup("EXERCISES_CORS_ALLOWED_ORIGINS"); ok {
+		c.CORS.AllowedOrigins = nil
+		for _, origin := range strings.Split(v, ",") {
+			if origin = strings.TrimSpace(origin); origin != "" {
+				c.CORS.AllowedOrigins = append(c.CORS.AllowedOrigins, origin)
+			}
+		}
+	}
+	duration("EXERCISES_CORS_MAX_AGE", &c.CORS.MaxAge)
+	toggle("EXERCISES_FEATURE_GRAPHQL", &c.Features.GraphQL)
+	toggle("EXERCISES_FEATURE_GRPC", &c.Features.GRPC)
+	toggle("EXERCISES_FEATURE_WEBHOOKS", &c.Features.Webhooks)
+	toggle("EXERCISES_FEATURE_LIVE_SESSIONS", &c.Features.LiveSessions)
+	toggle("EXERCISES_FEATURE_SYNC", &c.Features.Sync)
+	return errs
+}
+
+// Validate returns every invalid setting.
+func (c Config) Validate() []error {
+	var errs []error
+	if err := validateAddr(c.HTTPAddr); err != nil {
+		errs = append(errs, fmt.Errorf("http_addr: %w", err))
+	}
+	if c.Features.GRPC {
+		if err := validateAddr(c.GRPCAddr); err != nil {
+			errs = append(errs, fmt.Errorf("grpc_addr: %w", err))
+		}
+		if u, err := url.Parse(c.AuthnURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, errors.New("authn_url must be an absolute http or https URL"))
+		}
+	}
+	if c.ShutdownTimeout <= 0 {
+		errs = append(errs, errors.New("shutdown_timeout must be positive"))
+	}
+	if _, err := zapcore.ParseLevel(c.LogLevel); err != nil {
+		errs = append(errs, errors.New("log_level must be debug, info, warn or error"))
+	}
+	if c.RedisAddr == "" {
+		errs = append(errs, errors.New("redis_addr is required, domain events are published to Redis"))
+	}
+
+	if c.Database.URL == "" {
+		errs = append(errs, errors.New("database.url is required, set it or DATABASE_URL"))
+	} else if _, err := pgxpool.ParseConfig(c.Database.URL); err != nil {
+		errs = append(errs, errors.New("database.url is not a valid connection string"))
+	}
+	if c.Database.MinConns < 0 {
+		errs = append(errs, errors.New("database.min_conns can't be negative"))
+	}
+	if c.Database.MaxConns < 1 {
+		errs = append(errs, errors.New("database.max_conns must be at least 1"))
+	}
+	if c.Database.MinConns > c.Database.MaxConns {
+		errs = append(errs, errors.New("database.min_conns can't be more than database.max_conns"))
+	}
+	if c.Database.StatementTimeout < 0 {
+		errs = append(errs, errors.New("database.statement_timeout can't be negative"))
+	}
+
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin == "*" {
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || (u.Path != "" && u.Path != "/") {
+			errs = append(errs, fmt.Errorf("cors.allowed_origins: %q must be * or a scheme and host, e.g. https://app.example.com", origin))
+		}
+	}
+	if c.CORS.MaxAge < 0 {
+		errs = append(errs, errors.New("cors.max_age can't be negative"))
+	}
+	return errs
+}
+
+func validateAddr(addr string) error {
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("%q must be host:port, e.g. :8081", addr)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+		return fmt.Errorf("%q has an invalid port", addr)
+	}
+	return nil
+}
+
+// Redacted returns the config with its secrets masked, for printing.
+func (c Config) Redacted() Config {
+	if c.Database.URL != "" {
+		if u, err := url.Parse(c.Database.URL); err == nil && u.Scheme != "" {
+			c.Database.URL = u.Redacted()
+		} else {
+			// A key/value connection string, the password can be anywhere
+			c.Database.URL = "xxxxx"
+		}
+	}
+	return c
+}
+
+// YAML renders the config in the format of the config file.
+func (c Config) YAML() ([]byte, error) {
+	var b strings.Builder
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(c); err != nil {
+		return nil, err
+	}
+	return []byte(b.String()), enc.Close()
+}
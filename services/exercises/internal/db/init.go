@@ -3,9 +3,11 @@ package db
 import (
 	"context"
 	"log"
-	"os"
+	"strconv"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/config"
 )
 
 var (
@@ -13,19 +15,20 @@ var (
 	Queriez *Queries
 )
 
-func InitFromEnv() {
-	dsn := os.Getenv("DATABASE_URL")
-	if dsn == "" {
-		log.Fatal("DATABASE_URL is not set")
-	}
-	Init(dsn)
-}
-
-func Init(dsn string) {
-	var err error
+func Init(cfg config.Database) {
 	ctx := context.Background()
 
-	pool, err = pgxpool.New(ctx, dsn)
+	poolConfig, err := pgxpool.ParseConfig(cfg.URL)
+	if err != nil {
+		log.Fatalf("invalid DB connection string: %v", err)
+	}
+	poolConfig.MinConns = cfg.MinConns
+	poolConfig.MaxConns = cfg.MaxConns
+	if cfg.StatementTimeout > 0 {
+		poolConfig.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(cfg.StatementTimeout.Milliseconds(), 10)
+	}
+
+	pool, err = pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
 		log.Fatalf("failed to connect to DB: %v", err)
 	}
@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS program_sets;
+ALTER TABLE programs DROP CONSTRAINT IF EXISTS programs_pkey;
+-- Programs may repeat an exercise at several positions since this
+-- migration, which the old key can't hold. Only the first item of each
+-- exercise is kept, the later ones are deleted along with the per-set
+-- prescriptions of every item.
+DELETE FROM programs p
+USING programs first
+WHERE p.id = first.id
+  AND p.exercise_id = first.exercise_id
+  AND p.idx > first.idx;
+ALTER TABLE programs ADD PRIMARY KEY (id, exercise_id);
+ALTER TABLE exercises DROP COLUMN IF EXISTS category;
+DROP TYPE IF EXISTS category_t;
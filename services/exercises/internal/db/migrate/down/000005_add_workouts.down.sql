@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS workout_sets;
+DROP TABLE IF EXISTS workouts;
+ALTER TABLE programs DROP COLUMN IF EXISTS day;
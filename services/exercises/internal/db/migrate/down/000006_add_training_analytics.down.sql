@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS personal_records;
+DROP TABLE IF EXISTS exercise_e1rm;
+DROP TABLE IF EXISTS weekly_muscle_volume;
+ALTER TABLE workouts DROP COLUMN IF EXISTS analyzed_at;
+DROP TYPE IF EXISTS record_type_t;
@@ -0,0 +1,7 @@
+ALTER TABLE exercise_muscle DROP COLUMN IF EXISTS is_primary;
+ALTER TABLE exercises DROP COLUMN IF EXISTS force;
+ALTER TABLE exercises DROP COLUMN IF EXISTS mechanic;
+ALTER TABLE exercises DROP COLUMN IF EXISTS level;
+DROP TYPE IF EXISTS force_t;
+DROP TYPE IF EXISTS mechanic_t;
+DROP TYPE IF EXISTS level_t;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS program_share_tokens;
+DROP TABLE IF EXISTS program_access;
+DROP TYPE IF EXISTS visibility_t;
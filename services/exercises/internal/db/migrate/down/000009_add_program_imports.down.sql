@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS program_imports;
+DROP TYPE IF EXISTS import_status_t;
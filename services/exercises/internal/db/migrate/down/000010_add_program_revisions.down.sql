@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS program_revisions;
+DROP TYPE IF EXISTS revision_source_t;
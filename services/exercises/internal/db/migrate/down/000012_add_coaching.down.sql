@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS program_assignments;
+DROP TABLE IF EXISTS coach_clients;
+DROP TABLE IF EXISTS coach_invitations;
+DROP TABLE IF EXISTS user_roles;
+DROP TYPE IF EXISTS role_t;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS collection_exercises;
+DROP TABLE IF EXISTS exercise_collections;
+DROP TABLE IF EXISTS exercise_favorites;
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS exercise_submissions;
+DROP TYPE IF EXISTS submission_status_t;
+
+DROP INDEX IF EXISTS exercise_names_exercise_id_name_key;
+ALTER TABLE exercise_names ADD CONSTRAINT exercise_names_name_key UNIQUE (name);
+
+DROP INDEX IF EXISTS exercises_owner_id_idx;
+ALTER TABLE exercises DROP COLUMN IF EXISTS owner_id;
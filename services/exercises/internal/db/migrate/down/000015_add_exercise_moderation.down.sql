@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS exercise_submission_comments;
+
+-- Enum values can't be dropped, drafts are removed and admins lose their
+-- role instead
+DELETE FROM exercise_submissions WHERE status = 'draft';
+DELETE FROM user_roles WHERE role = 'admin';
+
+DROP INDEX IF EXISTS exercise_submissions_open_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS exercise_submissions_pending_idx ON exercise_submissions (exercise_id) WHERE status = 'pending';
+
+ALTER TABLE exercise_submissions DROP COLUMN IF EXISTS published_exercise_id;
+ALTER TABLE exercise_submissions DROP COLUMN IF EXISTS reviewed_at;
+ALTER TABLE exercise_submissions DROP COLUMN IF EXISTS reviewed_by;
+ALTER TABLE exercise_submissions DROP COLUMN IF EXISTS snapshot;
@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS sync_tombstones;
+
+DROP INDEX IF EXISTS workout_sets_client_idx;
+ALTER TABLE workout_sets DROP COLUMN IF EXISTS client_id;
+
+DROP INDEX IF EXISTS workouts_user_updated_idx;
+DROP INDEX IF EXISTS program_access_owner_updated_idx;
+DROP INDEX IF EXISTS exercises_updated_at_idx;
+
+ALTER TABLE workouts DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE program_access DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE exercises DROP COLUMN IF EXISTS updated_at;
@@ -0,0 +1,43 @@
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_type
+        WHERE typname = 'category_t'
+    ) THEN
+        CREATE TYPE category_t
+        AS ENUM(
+            'strength',
+            'stretching',
+            'plyometrics',
+            'powerlifting',
+            'olympic weightlifting',
+            'strongman',
+            'cardio'
+        );
+    END IF;
+END $$;
+
+ALTER TABLE exercises ADD COLUMN IF NOT EXISTS category category_t;
+
+-- A program item is identified by its position, so the same exercise can
+-- appear more than once in a program.
+ALTER TABLE programs DROP CONSTRAINT IF EXISTS programs_pkey;
+ALTER TABLE programs ADD PRIMARY KEY (id, idx);
+
+CREATE TABLE IF NOT EXISTS program_sets (
+  program_id UUID NOT NULL,
+  idx INT NOT NULL,
+  set_number INT NOT NULL,
+  reps INT,
+  weight_kg DOUBLE PRECISION,
+  one_rm_percent DOUBLE PRECISION,
+  rpe DOUBLE PRECISION,
+  rir INT,
+  tempo VARCHAR(16),
+  rest_seconds INT,
+  duration_seconds INT,
+  distance_meters DOUBLE PRECISION,
+  PRIMARY KEY (program_id, idx, set_number),
+  FOREIGN KEY (program_id, idx) REFERENCES programs (id, idx) ON DELETE CASCADE
+);
@@ -0,0 +1,32 @@
+ALTER TABLE programs ADD COLUMN IF NOT EXISTS day INT NOT NULL DEFAULT 1;
+
+CREATE TABLE IF NOT EXISTS workouts (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  user_id BIGINT NOT NULL,
+  program_id UUID NOT NULL,
+  day INT NOT NULL,
+  started_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  finished_at TIMESTAMPTZ,
+  notes TEXT
+);
+
+CREATE INDEX IF NOT EXISTS workouts_user_id_idx ON workouts (user_id, started_at);
+
+CREATE TABLE IF NOT EXISTS workout_sets (
+  id BIGSERIAL PRIMARY KEY,
+  workout_id UUID NOT NULL,
+  program_id UUID,
+  program_idx INT,
+  exercise_id INT NOT NULL,
+  set_number INT NOT NULL,
+  reps INT,
+  weight_kg DOUBLE PRECISION,
+  rpe DOUBLE PRECISION,
+  duration_seconds INT,
+  distance_meters DOUBLE PRECISION,
+  notes TEXT,
+  performed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  FOREIGN KEY (workout_id) REFERENCES workouts (id) ON DELETE CASCADE,
+  FOREIGN KEY (program_id, program_idx) REFERENCES programs (id, idx) ON DELETE SET NULL,
+  FOREIGN KEY (exercise_id) REFERENCES exercises (id)
+);
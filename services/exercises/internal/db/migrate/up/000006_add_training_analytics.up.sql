@@ -0,0 +1,60 @@
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_type
+        WHERE typname = 'record_type_t'
+    ) THEN
+        CREATE TYPE record_type_t
+        AS ENUM(
+            'weight',
+            'e1rm',
+            'volume'
+        );
+    END IF;
+END $$;
+
+-- Finished workouts are folded into the analytics tables below exactly once,
+-- analyzed_at marks the ones that already were.
+ALTER TABLE workouts ADD COLUMN IF NOT EXISTS analyzed_at TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS weekly_muscle_volume (
+  user_id BIGINT NOT NULL,
+  week_start DATE NOT NULL,
+  muscle_id INT NOT NULL,
+  sets INT NOT NULL,
+  reps INT NOT NULL,
+  tonnage_kg DOUBLE PRECISION NOT NULL,
+  PRIMARY KEY (user_id, week_start, muscle_id),
+  FOREIGN KEY (muscle_id) REFERENCES muscles (id)
+);
+
+CREATE TABLE IF NOT EXISTS exercise_e1rm (
+  workout_id UUID NOT NULL,
+  exercise_id INT NOT NULL,
+  user_id BIGINT NOT NULL,
+  performed_at TIMESTAMPTZ NOT NULL,
+  epley_kg DOUBLE PRECISION NOT NULL,
+  brzycki_kg DOUBLE PRECISION NOT NULL,
+  PRIMARY KEY (workout_id, exercise_id),
+  FOREIGN KEY (workout_id) REFERENCES workouts (id) ON DELETE CASCADE,
+  FOREIGN KEY (exercise_id) REFERENCES exercises (id)
+);
+
+CREATE INDEX IF NOT EXISTS exercise_e1rm_user_idx ON exercise_e1rm (user_id, exercise_id, performed_at);
+
+CREATE TABLE IF NOT EXISTS personal_records (
+  id BIGSERIAL PRIMARY KEY,
+  user_id BIGINT NOT NULL,
+  exercise_id INT NOT NULL,
+  record_type record_type_t NOT NULL,
+  value DOUBLE PRECISION NOT NULL,
+  previous_value DOUBLE PRECISION,
+  workout_id UUID NOT NULL,
+  workout_set_id BIGINT NOT NULL,
+  achieved_at TIMESTAMPTZ NOT NULL,
+  FOREIGN KEY (workout_id) REFERENCES workouts (id) ON DELETE CASCADE,
+  FOREIGN KEY (exercise_id) REFERENCES exercises (id)
+);
+
+CREATE INDEX IF NOT EXISTS personal_records_user_idx ON personal_records (user_id, exercise_id, record_type);
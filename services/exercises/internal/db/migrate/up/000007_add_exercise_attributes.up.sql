@@ -0,0 +1,46 @@
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_type
+        WHERE typname = 'level_t'
+    ) THEN
+        CREATE TYPE level_t
+        AS ENUM(
+            'beginner',
+            'intermediate',
+            'expert'
+        );
+    END IF;
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_type
+        WHERE typname = 'mechanic_t'
+    ) THEN
+        CREATE TYPE mechanic_t
+        AS ENUM(
+            'compound',
+            'isolation'
+        );
+    END IF;
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_type
+        WHERE typname = 'force_t'
+    ) THEN
+        CREATE TYPE force_t
+        AS ENUM(
+            'push',
+            'pull',
+            'static'
+        );
+    END IF;
+END $$;
+
+ALTER TABLE exercises ADD COLUMN IF NOT EXISTS level level_t;
+ALTER TABLE exercises ADD COLUMN IF NOT EXISTS mechanic mechanic_t;
+ALTER TABLE exercises ADD COLUMN IF NOT EXISTS force force_t;
+
+-- Muscles used to be imported as one list, primary ones are flagged by the
+-- import script's backfill.
+ALTER TABLE exercise_muscle ADD COLUMN IF NOT EXISTS is_primary BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,42 @@
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_type
+        WHERE typname = 'visibility_t'
+    ) THEN
+        CREATE TYPE visibility_t
+        AS ENUM(
+            'private',
+            'link',
+            'public'
+        );
+    END IF;
+END $$;
+
+-- One row per program. Programs created before sharing existed have no
+-- owner and stay public.
+CREATE TABLE IF NOT EXISTS program_access (
+  program_id UUID PRIMARY KEY,
+  owner_id BIGINT,
+  visibility visibility_t NOT NULL DEFAULT 'public',
+  forked_from UUID,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+INSERT INTO program_access (program_id)
+SELECT DISTINCT id FROM programs
+ON CONFLICT DO NOTHING;
+
+CREATE INDEX IF NOT EXISTS program_access_owner_idx ON program_access (owner_id);
+
+CREATE TABLE IF NOT EXISTS program_share_tokens (
+  token VARCHAR(64) PRIMARY KEY,
+  program_id UUID NOT NULL,
+  created_by BIGINT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  revoked_at TIMESTAMPTZ,
+  FOREIGN KEY (program_id) REFERENCES program_access (program_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS program_share_tokens_program_idx ON program_share_tokens (program_id);
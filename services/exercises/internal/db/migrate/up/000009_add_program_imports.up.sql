@@ -0,0 +1,30 @@
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_type
+        WHERE typname = 'import_status_t'
+    ) THEN
+        CREATE TYPE import_status_t
+        AS ENUM(
+            'pending',
+            'committed'
+        );
+    END IF;
+END $$;
+
+-- Spreadsheet imports waiting for unmatched exercises to be resolved. The
+-- parsed entries and their matches are kept as JSON until the program is
+-- created.
+CREATE TABLE IF NOT EXISTS program_imports (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  user_id BIGINT NOT NULL,
+  filename VARCHAR(255) NOT NULL,
+  status import_status_t NOT NULL DEFAULT 'pending',
+  entries JSONB NOT NULL,
+  program_id UUID,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS program_imports_user_idx ON program_imports (user_id, created_at);
@@ -0,0 +1,80 @@
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_type
+        WHERE typname = 'revision_source_t'
+    ) THEN
+        CREATE TYPE revision_source_t
+        AS ENUM(
+            'created',
+            'updated',
+            'progression',
+            'restored',
+            'generated',
+            'imported',
+            'forked'
+        );
+    END IF;
+END $$;
+
+-- Immutable snapshots of the items of a program, one per change. Items are
+-- kept as the JSON of the API so a revision reads back exactly as it was.
+CREATE TABLE IF NOT EXISTS program_revisions (
+  program_id UUID NOT NULL,
+  revision INT NOT NULL,
+  source revision_source_t NOT NULL,
+  restored_from INT,
+  created_by BIGINT,
+  items JSONB NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (program_id, revision),
+  FOREIGN KEY (program_id) REFERENCES program_access (program_id) ON DELETE CASCADE
+);
+
+-- Existing programs start with their current items as the first revision
+INSERT INTO program_revisions (program_id, revision, source, created_by, items)
+SELECT
+  p.id,
+  1,
+  'created',
+  a.owner_id,
+  jsonb_agg(
+    jsonb_build_object(
+      'exerciseId', p.exercise_id,
+      'idx', p.idx,
+      'day', p.day,
+      'sets', p.sets,
+      'reps', p.reps
+    ) || CASE WHEN s.prescriptions IS NULL THEN '{}'::jsonb ELSE jsonb_build_object('prescriptions', s.prescriptions) END
+    ORDER BY p.idx
+  )
+FROM
+  programs p
+  JOIN program_access a ON a.program_id = p.id
+  LEFT JOIN (
+    SELECT
+      program_id,
+      idx,
+      jsonb_agg(
+        jsonb_strip_nulls(jsonb_build_object(
+          'reps', reps,
+          'weightKg', weight_kg,
+          'oneRmPercent', one_rm_percent,
+          'rpe', rpe,
+          'rir', rir,
+          'tempo', tempo,
+          'restSeconds', rest_seconds,
+          'durationSeconds', duration_seconds,
+          'distanceMeters', distance_meters
+        ))
+        ORDER BY set_number
+      ) AS prescriptions
+    FROM
+      program_sets
+    GROUP BY
+      program_id, idx
+  ) s ON s.program_id = p.id AND s.idx = p.idx
+GROUP BY
+  p.id, a.owner_id
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,25 @@
+-- Program days laid out on the calendar of a user. Weekdays are numbered
+-- from Sunday (0) to Saturday (6); sessions without a start time are all-day.
+CREATE TABLE IF NOT EXISTS program_schedules (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  user_id BIGINT NOT NULL,
+  program_id UUID NOT NULL,
+  start_date DATE NOT NULL,
+  weekdays INT[] NOT NULL,
+  weeks INT NOT NULL,
+  start_time TIME,
+  duration_minutes INT NOT NULL DEFAULT 60,
+  timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  FOREIGN KEY (program_id) REFERENCES program_access (program_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS program_schedules_user_idx ON program_schedules (user_id);
+
+-- The secret token of the iCalendar feed of a user. Calendar apps can't send
+-- a bearer token, so the token in the URL is the credential.
+CREATE TABLE IF NOT EXISTS calendar_feeds (
+  user_id BIGINT PRIMARY KEY,
+  token VARCHAR(64) NOT NULL UNIQUE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
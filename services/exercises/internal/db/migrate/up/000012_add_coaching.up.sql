@@ -0,0 +1,67 @@
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_type
+        WHERE typname = 'role_t'
+    ) THEN
+        CREATE TYPE role_t
+        AS ENUM(
+            'coach',
+            'client'
+        );
+    END IF;
+END $$;
+
+-- Roles of the users authenticated by the gateway. Users are managed by the
+-- authn service, so only their IDs are stored here.
+CREATE TABLE IF NOT EXISTS user_roles (
+  user_id BIGINT NOT NULL,
+  role role_t NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (user_id, role)
+);
+
+-- Single use codes a coach hands to a client to link their accounts
+CREATE TABLE IF NOT EXISTS coach_invitations (
+  code VARCHAR(64) PRIMARY KEY,
+  coach_id BIGINT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  expires_at TIMESTAMPTZ NOT NULL,
+  accepted_by BIGINT,
+  accepted_at TIMESTAMPTZ,
+  revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS coach_invitations_coach_idx ON coach_invitations (coach_id, created_at);
+
+-- Links between a coach and a client, with what the client consents to.
+-- Ended links are kept for history and can be started again by a new
+-- invitation.
+CREATE TABLE IF NOT EXISTS coach_clients (
+  coach_id BIGINT NOT NULL,
+  client_id BIGINT NOT NULL,
+  share_workouts BOOLEAN NOT NULL DEFAULT TRUE,
+  allow_assignments BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  ended_at TIMESTAMPTZ,
+  PRIMARY KEY (coach_id, client_id)
+);
+
+CREATE INDEX IF NOT EXISTS coach_clients_client_idx ON coach_clients (client_id);
+
+-- Programs a coach assigned to a client, which the client can read whatever
+-- their visibility while the link lasts
+CREATE TABLE IF NOT EXISTS program_assignments (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  coach_id BIGINT NOT NULL,
+  client_id BIGINT NOT NULL,
+  program_id UUID NOT NULL,
+  assigned_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  unassigned_at TIMESTAMPTZ,
+  FOREIGN KEY (coach_id, client_id) REFERENCES coach_clients (coach_id, client_id),
+  FOREIGN KEY (program_id) REFERENCES program_access (program_id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS program_assignments_active_idx ON program_assignments (coach_id, client_id, program_id) WHERE unassigned_at IS NULL;
+CREATE INDEX IF NOT EXISTS program_assignments_client_idx ON program_assignments (client_id, program_id);
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS exercise_favorites (
+  user_id BIGINT NOT NULL,
+  exercise_id INT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (user_id, exercise_id),
+  FOREIGN KEY (exercise_id) REFERENCES exercises (id) ON DELETE CASCADE
+);
+
+-- Named sets of exercises of a user, e.g. "home gym" or "rehab"
+CREATE TABLE IF NOT EXISTS exercise_collections (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  user_id BIGINT NOT NULL,
+  name VARCHAR(100) NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (user_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS collection_exercises (
+  collection_id UUID NOT NULL,
+  exercise_id INT NOT NULL,
+  added_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (collection_id, exercise_id),
+  FOREIGN KEY (collection_id) REFERENCES exercise_collections (id) ON DELETE CASCADE,
+  FOREIGN KEY (exercise_id) REFERENCES exercises (id) ON DELETE CASCADE
+);
@@ -0,0 +1,42 @@
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_type
+        WHERE typname = 'submission_status_t'
+    ) THEN
+        CREATE TYPE submission_status_t
+        AS ENUM(
+            'pending',
+            'approved',
+            'rejected'
+        );
+    END IF;
+END $$;
+
+-- Custom exercises are owned by the user who created them, catalog
+-- exercises have no owner
+ALTER TABLE exercises ADD COLUMN IF NOT EXISTS owner_id BIGINT;
+
+-- The catalog is imported with explicit ids, move the sequence past them so
+-- custom exercises get free ones
+SELECT setval('exercises_id_seq', (SELECT max(id) FROM exercises));
+CREATE INDEX IF NOT EXISTS exercises_owner_id_idx ON exercises (owner_id) WHERE owner_id IS NOT NULL;
+
+-- Custom exercises may reuse the names of catalog exercises or of the
+-- exercises of other users, names are only unique per exercise
+ALTER TABLE exercise_names DROP CONSTRAINT IF EXISTS exercise_names_name_key;
+CREATE UNIQUE INDEX IF NOT EXISTS exercise_names_exercise_id_name_key ON exercise_names (exercise_id, name);
+
+-- Requests to promote a custom exercise to the public catalog
+CREATE TABLE IF NOT EXISTS exercise_submissions (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  exercise_id INT NOT NULL,
+  user_id BIGINT NOT NULL,
+  status submission_status_t NOT NULL DEFAULT 'pending',
+  note TEXT NOT NULL DEFAULT '',
+  submitted_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  FOREIGN KEY (exercise_id) REFERENCES exercises (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS exercise_submissions_pending_idx ON exercise_submissions (exercise_id) WHERE status = 'pending';
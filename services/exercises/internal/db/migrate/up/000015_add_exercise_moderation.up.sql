@@ -0,0 +1,22 @@
+ALTER TYPE role_t ADD VALUE IF NOT EXISTS 'admin';
+ALTER TYPE submission_status_t ADD VALUE IF NOT EXISTS 'draft' BEFORE 'pending';
+
+-- Submissions keep the exercise as it was submitted, so later edits of the
+-- custom exercise don't change what reviewers approve
+ALTER TABLE exercise_submissions ADD COLUMN IF NOT EXISTS snapshot JSONB;
+ALTER TABLE exercise_submissions ADD COLUMN IF NOT EXISTS reviewed_by BIGINT;
+ALTER TABLE exercise_submissions ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMPTZ;
+ALTER TABLE exercise_submissions ADD COLUMN IF NOT EXISTS published_exercise_id INT REFERENCES exercises (id);
+
+-- An exercise has at most one open (draft or pending) submission
+DROP INDEX IF EXISTS exercise_submissions_pending_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS exercise_submissions_open_idx ON exercise_submissions (exercise_id) WHERE status NOT IN ('approved', 'rejected');
+
+CREATE TABLE IF NOT EXISTS exercise_submission_comments (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  submission_id UUID NOT NULL,
+  author_id BIGINT NOT NULL,
+  body TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  FOREIGN KEY (submission_id) REFERENCES exercise_submissions (id) ON DELETE CASCADE
+);
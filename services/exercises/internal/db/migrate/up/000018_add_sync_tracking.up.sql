@@ -0,0 +1,27 @@
+-- When the rows offline clients sync last changed
+ALTER TABLE exercises ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+ALTER TABLE program_access ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+ALTER TABLE workouts ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+CREATE INDEX IF NOT EXISTS exercises_updated_at_idx ON exercises (updated_at);
+CREATE INDEX IF NOT EXISTS program_access_owner_updated_idx ON program_access (owner_id, updated_at);
+CREATE INDEX IF NOT EXISTS workouts_user_updated_idx ON workouts (user_id, updated_at);
+
+-- Sets logged offline carry the ID the client gave them, so pushing them
+-- again doesn't log them twice
+ALTER TABLE workout_sets ADD COLUMN IF NOT EXISTS client_id UUID;
+
+CREATE UNIQUE INDEX IF NOT EXISTS workout_sets_client_idx ON workout_sets (workout_id, client_id);
+
+-- Deleted rows, for clients to drop them on their next sync. Rows without a
+-- user concern every client.
+CREATE TABLE IF NOT EXISTS sync_tombstones (
+  id BIGSERIAL PRIMARY KEY,
+  user_id BIGINT,
+  entity VARCHAR(50) NOT NULL,
+  entity_id TEXT NOT NULL,
+  deleted_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS sync_tombstones_deleted_at_idx ON sync_tombstones (deleted_at);
+CREATE INDEX IF NOT EXISTS sync_tombstones_entity_idx ON sync_tombstones (user_id, entity, entity_id);
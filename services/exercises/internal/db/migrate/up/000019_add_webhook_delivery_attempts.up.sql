@@ -0,0 +1,13 @@
+-- Every attempt at sending a delivery, the delivery row only keeps the
+-- outcome of the last one
+CREATE TABLE IF NOT EXISTS webhook_delivery_attempts (
+  id BIGSERIAL PRIMARY KEY,
+  delivery_id UUID NOT NULL REFERENCES webhook_deliveries(id) ON DELETE CASCADE,
+  attempt INT NOT NULL,
+  response_status INT,
+  error TEXT,
+  duration_ms INT NOT NULL,
+  attempted_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS webhook_delivery_attempts_delivery_idx ON webhook_delivery_attempts (delivery_id, attempt);
@@ -1,9 +1,12 @@
--- Fetch Exercises with name, equipment and muscle filters (all optional)
+-- Fetch Exercises with name, equipment, muscle, collection and favorites
+-- filters (all optional). Custom exercises are only listed for their owner
+-- and the clients of their owner.
 -- name: GetExercises :many
 SELECT 
     e.id,
     string_agg(DISTINCT e_names.name, ', ') AS names_grouped,
     e.equipment,
+    e.owner_id,
     string_agg(DISTINCT m.name, ', ') AS muscles_grouped,
     string_agg(DISTINCT v.path, ', ') AS visuals_grouped
 FROM exercises e
@@ -15,7 +18,10 @@ WHERE
   (coalesce(sqlc.narg('name')) IS NULL OR e_names.name ILIKE '%' || @name::text || '%') AND
   (coalesce(sqlc.narg('equipment')) IS NULL OR e.equipment = @equipment::equipment_t) AND
   (coalesce(sqlc.narg('muscle')) IS NULL OR m.name = @muscle::text) AND
-  (coalesce(sqlc.narg('exercise_id')) IS NULL OR e.id = @exercise_id::int)
+  (coalesce(sqlc.narg('exercise_id')) IS NULL OR e.id = @exercise_id::int) AND
+  (coalesce(sqlc.narg('collection_id')) IS NULL OR e.id IN (SELECT exercise_id FROM collection_exercises WHERE collection_id = @collection_id::uuid)) AND
+  (coalesce(sqlc.narg('favorites_of')) IS NULL OR e.id IN (SELECT exercise_id FROM exercise_favorites WHERE user_id = @favorites_of::bigint)) AND
+  (e.owner_id IS NULL OR e.owner_id = sqlc.narg('viewer_id')::bigint OR e.owner_id IN (SELECT coach_id FROM coach_clients WHERE client_id = sqlc.narg('viewer_id')::bigint AND ended_at IS NULL))
 GROUP BY
     e.id
 ORDER BY e.id
@@ -23,6 +29,56 @@ LIMIT coalesce(sqlc.narg('limit'), 50)
 OFFSET coalesce(sqlc.narg('offset'), 0);
 
 
+-- Fetch the category of each of the given exercises the viewer can use
+-- name: GetExerciseCategories :many
+SELECT
+    e.id,
+    e.category
+FROM
+    exercises e
+WHERE
+    e.id = ANY(@exercise_ids::int[]) AND
+    (e.owner_id IS NULL OR e.owner_id = sqlc.narg('viewer_id')::bigint OR e.owner_id IN (SELECT coach_id FROM coach_clients WHERE client_id = sqlc.narg('viewer_id')::bigint AND ended_at IS NULL));
+
+
+-- Fetch every categorised exercise with the attributes and primary muscles
+-- the program generator picks from
+-- name: GetGeneratorCandidates :many
+SELECT
+  e.id,
+  e.equipment,
+  e.category,
+  e.level,
+  e.mechanic,
+  e.force,
+  (array_agg(DISTINCT m.name) FILTER (WHERE e_m.is_primary))::text[] AS primary_muscles
+FROM
+  exercises e
+  INNER JOIN exercise_muscle e_m ON e_m.exercise_id = e.id
+  INNER JOIN muscles m ON m.id = e_m.muscle_id
+WHERE
+  e.category IS NOT NULL AND e.owner_id IS NULL
+GROUP BY
+  e.id
+ORDER BY
+  e.id;
+
+
+-- Fetch every name of every exercise the viewer can use, for matching
+-- imported names. Catalog names come first.
+-- name: GetExerciseNames :many
+SELECT
+  e_names.exercise_id,
+  e_names.name
+FROM
+  exercise_names e_names
+  INNER JOIN exercises e ON e.id = e_names.exercise_id
+WHERE
+  (e.owner_id IS NULL OR e.owner_id = sqlc.narg('viewer_id')::bigint OR e.owner_id IN (SELECT coach_id FROM coach_clients WHERE client_id = sqlc.narg('viewer_id')::bigint AND ended_at IS NULL))
+ORDER BY
+  e.owner_id IS NOT NULL, e_names.exercise_id, e_names.id;
+
+
 -- Fetch all Muscles
 -- name: GetMuscles :many
 SELECT
@@ -44,16 +100,24 @@ FROM
 --   e.enumsortorder;
 
 
--- Fetch Full Program by id
+-- Fetch Full Program by id. The import script stores instructions as an
+-- array literal, older rows may hold plain text.
 -- name: GetFullProgramById :many
 SELECT
   idx,
+  day,
+  e.id AS exercise_id,
   string_agg(DISTINCT e_names.name, ', ') AS names_grouped,
   e.equipment,
   sets,
   reps,
   string_agg(DISTINCT m.name, ', ') AS muscles_grouped,
-  string_agg(DISTINCT v.path, ', ') AS visuals_grouped
+  string_agg(DISTINCT v.path, ', ') AS visuals_grouped,
+  (CASE
+    WHEN e.instructions IS NULL THEN '{}'::text[]
+    WHEN e.instructions LIKE '{%}' THEN e.instructions::text[]
+    ELSE ARRAY[e.instructions]
+  END)::text[] AS instructions
 FROM 
   programs p
   INNER JOIN exercises e ON e.id = p.exercise_id
@@ -64,7 +128,7 @@ FROM
 WHERE
   p.id = @program_id::uuid
 GROUP BY
-  e.id, idx, sets, reps
+  e.id, idx, day, sets, reps
 ORDER BY
   idx;
 
@@ -72,6 +136,7 @@ ORDER BY
 -- name: GetProgramById :many
 SELECT
   idx,
+  day,
   e.id AS exercise_id,
   sets,
   reps
@@ -81,11 +146,59 @@ FROM
 WHERE
   p.id = @program_id::uuid
 GROUP BY
-  e.id, idx, sets, reps
+  e.id, idx, day, sets, reps
 ORDER BY
   idx;
 
 
+-- Fetch the per-set prescriptions of a program
+-- name: GetProgramSetsById :many
+SELECT
+  idx,
+  set_number,
+  reps,
+  weight_kg,
+  one_rm_percent,
+  rpe,
+  rir,
+  tempo,
+  rest_seconds,
+  duration_seconds,
+  distance_meters
+FROM
+  program_sets
+WHERE
+  program_id = @program_id::uuid
+ORDER BY
+  idx, set_number;
+
+
+-- Fetch the items of a program with the attributes and muscles of their
+-- exercises, for the balance analysis
+-- name: GetProgramAnalysisItems :many
+SELECT
+  p.idx,
+  p.day,
+  p.exercise_id,
+  p.sets,
+  e.equipment,
+  e.mechanic,
+  e.force,
+  COALESCE((array_agg(DISTINCT m.name) FILTER (WHERE e_m.is_primary)), '{}')::text[] AS primary_muscles,
+  COALESCE((array_agg(DISTINCT m.name) FILTER (WHERE NOT e_m.is_primary)), '{}')::text[] AS secondary_muscles
+FROM
+  programs p
+  INNER JOIN exercises e ON e.id = p.exercise_id
+  LEFT JOIN exercise_muscle e_m ON e_m.exercise_id = e.id
+  LEFT JOIN muscles m ON m.id = e_m.muscle_id
+WHERE
+  p.id = @program_id::uuid
+GROUP BY
+  p.idx, p.day, p.exercise_id, p.sets, e.id
+ORDER BY
+  p.idx;
+
+
 -- Insert into exercise_names
 -- name: InsertToExerciseNames :one
 INSERT INTO 
@@ -127,6 +240,1357 @@ RETURNING id;
 
 -- name: InsertToProgramsById :exec
 INSERT INTO
-  programs(id, idx, exercise_id, sets, reps)
+  programs(id, idx, day, exercise_id, sets, reps)
+VALUES
+  (@id::uuid, @idx::int, @day::int, @exercise_id::int, @sets::int, @reps::int);
+
+-- name: InsertToProgramSets :exec
+INSERT INTO
+  program_sets(program_id, idx, set_number, reps, weight_kg, one_rm_percent, rpe, rir, tempo, rest_seconds, duration_seconds, distance_meters)
+VALUES
+  (@program_id::uuid, @idx::int, @set_number::int, sqlc.narg('reps'), sqlc.narg('weight_kg'), sqlc.narg('one_rm_percent'), sqlc.narg('rpe'), sqlc.narg('rir'), sqlc.narg('tempo'), sqlc.narg('rest_seconds'), sqlc.narg('duration_seconds'), sqlc.narg('distance_meters'));
+
+
+-- Start a workout from a program day. Workouts started offline carry their
+-- ID and start time, pushing them again returns nothing.
+-- name: InsertWorkout :one
+INSERT INTO
+  workouts(id, user_id, program_id, day, started_at)
+VALUES
+  (coalesce(sqlc.narg('id')::uuid, uuid_generate_v4()), @user_id::bigint, @program_id::uuid, @day::int, coalesce(sqlc.narg('started_at')::timestamptz, now()))
+ON CONFLICT (id) DO NOTHING
+RETURNING *;
+
+-- Fetch a workout by id
+-- name: GetWorkoutById :one
+SELECT
+  *
+FROM
+  workouts
+WHERE
+  id = @id::uuid;
+
+-- Fetch the workouts of a user, most recent first
+-- name: GetWorkoutsByUser :many
+SELECT
+  w.id,
+  w.program_id,
+  w.day,
+  w.started_at,
+  w.finished_at,
+  w.notes,
+  count(ws.id) AS set_count
+FROM
+  workouts w
+  LEFT JOIN workout_sets ws ON ws.workout_id = w.id
+WHERE
+  w.user_id = @user_id::bigint
+GROUP BY
+  w.id
+ORDER BY
+  w.started_at DESC
+LIMIT sqlc.arg('limit')::int
+OFFSET sqlc.arg('offset')::int;
+
+-- Mark a workout as finished, only once
+-- name: FinishWorkout :one
+UPDATE
+  workouts
+SET
+  finished_at = coalesce(sqlc.narg('finished_at')::timestamptz, now()),
+  notes = coalesce(sqlc.narg('notes')::text, notes),
+  updated_at = now()
+WHERE
+  id = @id::uuid AND finished_at IS NULL
+RETURNING *;
+
+-- Fetch the logged sets of a workout
+-- name: GetWorkoutSets :many
+SELECT
+  *
+FROM
+  workout_sets
+WHERE
+  workout_id = @workout_id::uuid
+ORDER BY
+  performed_at, id;
+
+-- Log a set of a workout. Sets logged offline carry the ID the client gave
+-- them and the time they were performed, pushing them again returns nothing.
+-- name: InsertWorkoutSet :one
+INSERT INTO
+  workout_sets(workout_id, program_id, program_idx, exercise_id, set_number, reps, weight_kg, rpe, duration_seconds, distance_meters, notes, client_id, performed_at)
+VALUES
+  (@workout_id::uuid, sqlc.narg('program_id')::uuid, sqlc.narg('program_idx')::int, @exercise_id::int, @set_number::int, sqlc.narg('reps')::int, sqlc.narg('weight_kg')::float8, sqlc.narg('rpe')::float8, sqlc.narg('duration_seconds')::int, sqlc.narg('distance_meters')::float8, sqlc.narg('notes')::text,
+   sqlc.narg('client_id')::uuid, coalesce(sqlc.narg('performed_at')::timestamptz, now()))
+ON CONFLICT (workout_id, client_id) DO NOTHING
+RETURNING *;
+
+-- name: TouchWorkout :exec
+UPDATE
+  workouts
+SET
+  updated_at = now()
+WHERE
+  id = @id::uuid;
+
+
+-- Claim a finished workout for the analytics, returns 0 rows once it was
+-- already folded in
+-- name: MarkWorkoutAnalyzed :execrows
+UPDATE
+  workouts
+SET
+  analyzed_at = now()
+WHERE
+  id = @id::uuid AND finished_at IS NOT NULL AND analyzed_at IS NULL;
+
+-- Fetch the finished workouts of a user the analytics haven't seen yet
+-- name: GetUnanalyzedWorkouts :many
+SELECT
+  *
+FROM
+  workouts
+WHERE
+  user_id = @user_id::bigint AND finished_at IS NOT NULL AND analyzed_at IS NULL
+ORDER BY
+  finished_at;
+
+-- Add the sets of a workout to the weekly volume of every muscle they train
+-- name: AddWorkoutMuscleVolume :exec
+INSERT INTO
+  weekly_muscle_volume(user_id, week_start, muscle_id, sets, reps, tonnage_kg)
+SELECT
+  w.user_id,
+  date_trunc('week', w.started_at)::date,
+  e_m.muscle_id,
+  count(*),
+  coalesce(sum(ws.reps), 0),
+  coalesce(sum(ws.reps * ws.weight_kg), 0)
+FROM
+  workouts w
+  INNER JOIN workout_sets ws ON ws.workout_id = w.id
+  INNER JOIN exercise_muscle e_m ON e_m.exercise_id = ws.exercise_id
+WHERE
+  w.id = @workout_id::uuid
+GROUP BY
+  w.user_id, date_trunc('week', w.started_at)::date, e_m.muscle_id
+ON CONFLICT (user_id, week_start, muscle_id) DO UPDATE SET
+  sets = weekly_muscle_volume.sets + EXCLUDED.sets,
+  reps = weekly_muscle_volume.reps + EXCLUDED.reps,
+  tonnage_kg = weekly_muscle_volume.tonnage_kg + EXCLUDED.tonnage_kg;
+
+-- Fetch the weekly volume per muscle of a user
+-- name: GetWeeklyMuscleVolume :many
+SELECT
+  v.week_start,
+  m.name AS muscle,
+  v.sets,
+  v.reps,
+  v.tonnage_kg
+FROM
+  weekly_muscle_volume v
+  INNER JOIN muscles m ON m.id = v.muscle_id
+WHERE
+  v.user_id = @user_id::bigint AND v.week_start >= @since::date
+ORDER BY
+  v.week_start, m.name;
+
+-- Store the best estimated 1RM of an exercise in a workout
+-- name: UpsertExerciseE1rm :exec
+INSERT INTO
+  exercise_e1rm(workout_id, exercise_id, user_id, performed_at, epley_kg, brzycki_kg)
+VALUES
+  (@workout_id::uuid, @exercise_id::int, @user_id::bigint, @performed_at::timestamptz, @epley_kg::float8, @brzycki_kg::float8)
+ON CONFLICT (workout_id, exercise_id) DO UPDATE SET
+  epley_kg = EXCLUDED.epley_kg,
+  brzycki_kg = EXCLUDED.brzycki_kg;
+
+-- Fetch the estimated 1RM history of an exercise for a user
+-- name: GetE1rmTrend :many
+SELECT
+  workout_id,
+  performed_at,
+  epley_kg,
+  brzycki_kg
+FROM
+  exercise_e1rm
+WHERE
+  user_id = @user_id::bigint AND exercise_id = @exercise_id::int
+ORDER BY
+  performed_at;
+
+-- Fetch the current best of every record type for the given exercises
+-- name: GetBestRecords :many
+SELECT
+  exercise_id,
+  record_type,
+  max(value)::float8 AS best
+FROM
+  personal_records
+WHERE
+  user_id = @user_id::bigint AND exercise_id = ANY(@exercise_ids::int[])
+GROUP BY
+  exercise_id, record_type;
+
+-- name: InsertPersonalRecord :one
+INSERT INTO
+  personal_records(user_id, exercise_id, record_type, value, previous_value, workout_id, workout_set_id, achieved_at)
+VALUES
+  (@user_id::bigint, @exercise_id::int, @record_type::record_type_t, @value::float8, sqlc.narg('previous_value')::float8, @workout_id::uuid, @workout_set_id::bigint, @achieved_at::timestamptz)
+RETURNING *;
+
+-- Fetch the current personal records of a user, optionally for one exercise
+-- name: GetPersonalRecords :many
+SELECT DISTINCT ON (exercise_id, record_type)
+  *
+FROM
+  personal_records
+WHERE
+  user_id = @user_id::bigint AND (sqlc.narg('exercise_id')::int IS NULL OR exercise_id = sqlc.narg('exercise_id')::int)
+ORDER BY
+  exercise_id, record_type, value DESC, achieved_at;
+
+
+-- Fetch the last finished workout of a user on a program day
+-- name: GetLastWorkoutForProgramDay :one
+SELECT
+  *
+FROM
+  workouts
+WHERE
+  user_id = @user_id::bigint AND program_id = @program_id::uuid AND day = @day::int AND finished_at IS NOT NULL
+ORDER BY
+  finished_at DESC
+LIMIT 1;
+
+-- Drop the set prescriptions of a program item before rewriting them
+-- name: DeleteProgramSetsByIdx :exec
+DELETE FROM
+  program_sets
+WHERE
+  program_id = @program_id::uuid AND idx = @idx::int;
+
+-- Update the summary sets and reps of a program item
+-- name: UpdateProgramItemVolume :exec
+UPDATE
+  programs
+SET
+  sets = @sets::int,
+  reps = @reps::int
+WHERE
+  id = @program_id::uuid AND idx = @idx::int;
+
+
+-- Record who owns a new program and who can read it
+-- name: InsertProgramAccess :one
+INSERT INTO
+  program_access(program_id, owner_id, visibility, forked_from)
+VALUES
+  (@program_id::uuid, sqlc.narg('owner_id')::bigint, @visibility::visibility_t, sqlc.narg('forked_from')::uuid)
+RETURNING *;
+
+-- name: GetProgramAccess :one
+SELECT
+  *
+FROM
+  program_access
+WHERE
+  program_id = @program_id::uuid;
+
+-- name: UpdateProgramVisibility :one
+UPDATE
+  program_access
+SET
+  visibility = @visibility::visibility_t,
+  updated_at = now()
+WHERE
+  program_id = @program_id::uuid
+RETURNING *;
+
+-- name: InsertShareToken :one
+INSERT INTO
+  program_share_tokens(token, program_id, created_by)
+VALUES
+  (@token::text, @program_id::uuid, @created_by::bigint)
+RETURNING *;
+
+-- Fetch the share tokens of a program that weren't revoked
+-- name: GetShareTokens :many
+SELECT
+  *
+FROM
+  program_share_tokens
+WHERE
+  program_id = @program_id::uuid AND revoked_at IS NULL
+ORDER BY
+  created_at;
+
+-- name: HasShareToken :one
+SELECT EXISTS (
+  SELECT
+    1
+  FROM
+    program_share_tokens
+  WHERE
+    program_id = @program_id::uuid AND token = @token::text AND revoked_at IS NULL
+);
+
+-- name: RevokeShareToken :execrows
+UPDATE
+  program_share_tokens
+SET
+  revoked_at = now()
+WHERE
+  program_id = @program_id::uuid AND token = @token::text AND revoked_at IS NULL;
+
+-- Copy the items of a program into a new one
+-- name: CopyProgramItems :exec
+INSERT INTO
+  programs(id, idx, day, exercise_id, sets, reps)
+SELECT
+  @new_id::uuid, idx, day, exercise_id, sets, reps
+FROM
+  programs
+WHERE
+  id = @source_id::uuid;
+
+-- Copy the set prescriptions of a program into a new one
+-- name: CopyProgramSets :exec
+INSERT INTO
+  program_sets(program_id, idx, set_number, reps, weight_kg, one_rm_percent, rpe, rir, tempo, rest_seconds, duration_seconds, distance_meters)
+SELECT
+  @new_id::uuid, idx, set_number, reps, weight_kg, one_rm_percent, rpe, rir, tempo, rest_seconds, duration_seconds, distance_meters
+FROM
+  program_sets
+WHERE
+  program_id = @source_id::uuid;
+
+
+-- name: InsertProgramImport :one
+INSERT INTO
+  program_imports(user_id, filename, status, entries, program_id)
+VALUES
+  (@user_id::bigint, @filename::text, @status::import_status_t, @entries::jsonb, sqlc.narg('program_id')::uuid)
+RETURNING *;
+
+-- Fetch an import of a user
+-- name: GetProgramImport :one
+SELECT
+  *
+FROM
+  program_imports
+WHERE
+  id = @id::uuid AND user_id = @user_id::bigint;
+
+-- Update a pending import, none is returned once it was committed
+-- name: UpdateProgramImport :one
+UPDATE
+  program_imports
+SET
+  status = @status::import_status_t,
+  entries = @entries::jsonb,
+  program_id = sqlc.narg('program_id')::uuid,
+  updated_at = now()
+WHERE
+  id = @id::uuid AND
+  status = 'pending'
+RETURNING *;
+
+
+-- Insert or overwrite a program item, keeping the logged sets that point at it
+-- name: UpsertProgramItem :exec
+INSERT INTO
+  programs(id, idx, day, exercise_id, sets, reps)
+VALUES
+  (@id::uuid, @idx::int, @day::int, @exercise_id::int, @sets::int, @reps::int)
+ON CONFLICT (id, idx) DO UPDATE SET
+  day = EXCLUDED.day,
+  exercise_id = EXCLUDED.exercise_id,
+  sets = EXCLUDED.sets,
+  reps = EXCLUDED.reps;
+
+-- Drop the items of a program that aren't part of its new version
+-- name: DeleteProgramItemsExcept :exec
+DELETE FROM
+  programs
+WHERE
+  id = @program_id::uuid AND NOT (idx = ANY(@idxs::int[]));
+
+-- Snapshot the items of a program as its next revision
+-- name: InsertProgramRevision :one
+INSERT INTO
+  program_revisions(program_id, revision, source, restored_from, created_by, items)
+VALUES
+  (
+    @program_id::uuid,
+    (SELECT COALESCE(MAX(revision), 0) + 1 FROM program_revisions WHERE program_id = @program_id::uuid),
+    @source::revision_source_t,
+    sqlc.narg('restored_from')::int,
+    sqlc.narg('created_by')::bigint,
+    @items::jsonb
+  )
+RETURNING *;
+
+-- List the revisions of a program without their items, newest first
+-- name: GetProgramRevisions :many
+SELECT
+  program_id,
+  revision,
+  source,
+  restored_from,
+  created_by,
+  created_at,
+  jsonb_array_length(items)::int AS item_count
+FROM
+  program_revisions
+WHERE
+  program_id = @program_id::uuid
+ORDER BY
+  revision DESC;
+
+-- name: GetProgramRevision :one
+SELECT
+  *
+FROM
+  program_revisions
+WHERE
+  program_id = @program_id::uuid AND revision = @revision::int;
+
+
+-- name: InsertProgramSchedule :one
+INSERT INTO
+  program_schedules(user_id, program_id, start_date, weekdays, weeks, start_time, duration_minutes, timezone)
 VALUES
-  (@id::uuid, @idx::int, @exercise_id::int, @sets::int, @reps::int);
+  (@user_id::bigint, @program_id::uuid, @start_date::date, @weekdays::int[], @weeks::int, sqlc.narg('start_time')::time, @duration_minutes::int, @timezone::text)
+RETURNING *;
+
+-- name: GetProgramSchedules :many
+SELECT
+  *
+FROM
+  program_schedules
+WHERE
+  user_id = @user_id::bigint
+ORDER BY
+  start_date, created_at;
+
+-- name: DeleteProgramSchedule :execrows
+DELETE FROM
+  program_schedules
+WHERE
+  id = @id::uuid AND user_id = @user_id::bigint;
+
+-- Create the calendar feed of a user, or replace its token
+-- name: UpsertCalendarFeed :one
+INSERT INTO
+  calendar_feeds(user_id, token)
+VALUES
+  (@user_id::bigint, @token::text)
+ON CONFLICT (user_id) DO UPDATE SET
+  token = EXCLUDED.token,
+  created_at = now()
+RETURNING *;
+
+-- name: GetCalendarFeed :one
+SELECT
+  *
+FROM
+  calendar_feeds
+WHERE
+  user_id = @user_id::bigint;
+
+-- name: GetCalendarFeedByToken :one
+SELECT
+  *
+FROM
+  calendar_feeds
+WHERE
+  token = @token::text;
+
+
+-- Fetch the programs a user owns, newest first
+-- name: GetUserPrograms :many
+SELECT
+  *
+FROM
+  program_access
+WHERE
+  owner_id = @owner_id::bigint
+ORDER BY
+  created_at DESC;
+
+
+-- Fetch the items of every program of a user at once, to summarize them
+-- name: GetUserProgramItems :many
+SELECT
+  p.id AS program_id,
+  p.idx,
+  p.day,
+  p.exercise_id,
+  p.sets,
+  p.reps
+FROM
+  programs p
+  INNER JOIN program_access a ON a.program_id = p.id
+WHERE
+  a.owner_id = @owner_id::bigint
+ORDER BY
+  p.id, p.idx;
+
+
+-- Fetch the per-set prescriptions of every program of a user at once
+-- name: GetUserProgramSets :many
+SELECT
+  s.program_id,
+  s.idx,
+  s.set_number,
+  s.reps,
+  s.weight_kg,
+  s.one_rm_percent,
+  s.rpe,
+  s.rir,
+  s.tempo,
+  s.rest_seconds,
+  s.duration_seconds,
+  s.distance_meters
+FROM
+  program_sets s
+  INNER JOIN program_access a ON a.program_id = s.program_id
+WHERE
+  a.owner_id = @owner_id::bigint
+ORDER BY
+  s.program_id, s.idx, s.set_number;
+
+
+-- name: AddUserRole :exec
+INSERT INTO
+  user_roles(user_id, role)
+VALUES
+  (@user_id::bigint, @role::role_t)
+ON CONFLICT DO NOTHING;
+
+-- name: GetUserRoles :many
+SELECT
+  role
+FROM
+  user_roles
+WHERE
+  user_id = @user_id::bigint
+ORDER BY
+  role;
+
+-- name: HasUserRole :one
+SELECT EXISTS (
+  SELECT
+    1
+  FROM
+    user_roles
+  WHERE
+    user_id = @user_id::bigint AND role = @role::role_t
+);
+
+-- name: InsertCoachInvitation :one
+INSERT INTO
+  coach_invitations(code, coach_id, expires_at)
+VALUES
+  (@code::text, @coach_id::bigint, @expires_at::timestamptz)
+RETURNING *;
+
+-- name: GetCoachInvitations :many
+SELECT
+  *
+FROM
+  coach_invitations
+WHERE
+  coach_id = @coach_id::bigint
+ORDER BY
+  created_at DESC;
+
+-- name: RevokeCoachInvitation :execrows
+UPDATE
+  coach_invitations
+SET
+  revoked_at = now()
+WHERE
+  code = @code::text AND coach_id = @coach_id::bigint AND accepted_at IS NULL AND revoked_at IS NULL;
+
+-- Use up an invitation that is still valid. Coaches can't invite themselves.
+-- name: AcceptCoachInvitation :one
+UPDATE
+  coach_invitations
+SET
+  accepted_by = @client_id::bigint,
+  accepted_at = now()
+WHERE
+  code = @code::text AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > now() AND coach_id <> @client_id::bigint
+RETURNING *;
+
+-- Link a coach and a client, starting an ended link again with fresh consent
+-- name: UpsertCoachClient :one
+INSERT INTO
+  coach_clients(coach_id, client_id)
+VALUES
+  (@coach_id::bigint, @client_id::bigint)
+ON CONFLICT (coach_id, client_id) DO UPDATE SET
+  share_workouts = TRUE,
+  allow_assignments = TRUE,
+  created_at = now(),
+  ended_at = NULL
+RETURNING *;
+
+-- name: GetCoachClient :one
+SELECT
+  *
+FROM
+  coach_clients
+WHERE
+  coach_id = @coach_id::bigint AND client_id = @client_id::bigint AND ended_at IS NULL;
+
+-- name: GetCoachClients :many
+SELECT
+  *
+FROM
+  coach_clients
+WHERE
+  coach_id = @coach_id::bigint AND ended_at IS NULL
+ORDER BY
+  created_at;
+
+-- name: GetClientCoaches :many
+SELECT
+  *
+FROM
+  coach_clients
+WHERE
+  client_id = @client_id::bigint AND ended_at IS NULL
+ORDER BY
+  created_at;
+
+-- name: UpdateClientConsent :one
+UPDATE
+  coach_clients
+SET
+  share_workouts = @share_workouts::boolean,
+  allow_assignments = @allow_assignments::boolean
+WHERE
+  coach_id = @coach_id::bigint AND client_id = @client_id::bigint AND ended_at IS NULL
+RETURNING *;
+
+-- name: EndCoachClient :execrows
+UPDATE
+  coach_clients
+SET
+  ended_at = now()
+WHERE
+  coach_id = @coach_id::bigint AND client_id = @client_id::bigint AND ended_at IS NULL;
+
+-- Drop the assignments of a link that ended
+-- name: UnassignClientPrograms :exec
+UPDATE
+  program_assignments
+SET
+  unassigned_at = now()
+WHERE
+  coach_id = @coach_id::bigint AND client_id = @client_id::bigint AND unassigned_at IS NULL;
+
+-- name: InsertProgramAssignment :one
+INSERT INTO
+  program_assignments(coach_id, client_id, program_id)
+VALUES
+  (@coach_id::bigint, @client_id::bigint, @program_id::uuid)
+ON CONFLICT (coach_id, client_id, program_id) WHERE unassigned_at IS NULL DO UPDATE SET
+  assigned_at = program_assignments.assigned_at
+RETURNING *;
+
+-- name: GetClientAssignments :many
+SELECT
+  *
+FROM
+  program_assignments
+WHERE
+  coach_id = @coach_id::bigint AND client_id = @client_id::bigint AND unassigned_at IS NULL
+ORDER BY
+  assigned_at DESC;
+
+-- Fetch the programs assigned to a client by any coach
+-- name: GetAssignmentsOfClient :many
+SELECT
+  *
+FROM
+  program_assignments
+WHERE
+  client_id = @client_id::bigint AND unassigned_at IS NULL
+ORDER BY
+  assigned_at DESC;
+
+-- name: UnassignProgram :execrows
+UPDATE
+  program_assignments
+SET
+  unassigned_at = now()
+WHERE
+  id = @id::uuid AND coach_id = @coach_id::bigint AND client_id = @client_id::bigint AND unassigned_at IS NULL;
+
+-- name: IsProgramAssigned :one
+SELECT EXISTS (
+  SELECT
+    1
+  FROM
+    program_assignments
+  WHERE
+    program_id = @program_id::uuid AND client_id = @client_id::bigint AND unassigned_at IS NULL
+);
+
+
+-- name: AddFavorite :exec
+INSERT INTO
+  exercise_favorites(user_id, exercise_id)
+VALUES
+  (@user_id::bigint, @exercise_id::int)
+ON CONFLICT DO NOTHING;
+
+-- name: RemoveFavorite :execrows
+DELETE FROM
+  exercise_favorites
+WHERE
+  user_id = @user_id::bigint AND exercise_id = @exercise_id::int;
+
+-- Create a collection, returning nothing when the user already has one with
+-- the same name
+-- name: InsertExerciseCollection :one
+INSERT INTO
+  exercise_collections(user_id, name)
+VALUES
+  (@user_id::bigint, @name::text)
+ON CONFLICT (user_id, name) DO NOTHING
+RETURNING *;
+
+-- name: GetExerciseCollections :many
+SELECT
+  c.id,
+  c.name,
+  c.created_at,
+  COUNT(c_e.exercise_id)::int AS exercise_count
+FROM
+  exercise_collections c
+  LEFT JOIN collection_exercises c_e ON c_e.collection_id = c.id
+WHERE
+  c.user_id = @user_id::bigint
+GROUP BY
+  c.id
+ORDER BY
+  c.name;
+
+-- name: GetExerciseCollection :one
+SELECT
+  *
+FROM
+  exercise_collections
+WHERE
+  id = @id::uuid AND user_id = @user_id::bigint;
+
+-- name: GetCollectionExerciseIds :many
+SELECT
+  exercise_id
+FROM
+  collection_exercises
+WHERE
+  collection_id = @collection_id::uuid
+ORDER BY
+  added_at, exercise_id;
+
+-- name: RenameExerciseCollection :one
+UPDATE
+  exercise_collections
+SET
+  name = @name::text
+WHERE
+  id = @id::uuid AND user_id = @user_id::bigint
+RETURNING *;
+
+-- name: DeleteExerciseCollection :execrows
+DELETE FROM
+  exercise_collections
+WHERE
+  id = @id::uuid AND user_id = @user_id::bigint;
+
+-- name: AddCollectionExercise :exec
+INSERT INTO
+  collection_exercises(collection_id, exercise_id)
+VALUES
+  (@collection_id::uuid, @exercise_id::int)
+ON CONFLICT DO NOTHING;
+
+-- name: RemoveCollectionExercise :execrows
+DELETE FROM
+  collection_exercises
+WHERE
+  collection_id = @collection_id::uuid AND exercise_id = @exercise_id::int;
+
+
+-- name: InsertCustomExercise :one
+INSERT INTO
+  exercises(owner_id, equipment, category, level, mechanic, force, instructions, visuals_id)
+VALUES
+  (@owner_id::bigint, @equipment::equipment_t, sqlc.narg('category')::category_t, sqlc.narg('level')::level_t,
+   sqlc.narg('mechanic')::mechanic_t, sqlc.narg('force')::force_t, @instructions::text[]::text, sqlc.narg('visuals_id')::int)
+RETURNING id;
+
+-- name: UpdateCustomExercise :execrows
+UPDATE
+  exercises
+SET
+  equipment = @equipment::equipment_t,
+  category = sqlc.narg('category')::category_t,
+  level = sqlc.narg('level')::level_t,
+  mechanic = sqlc.narg('mechanic')::mechanic_t,
+  force = sqlc.narg('force')::force_t,
+  instructions = @instructions::text[]::text,
+  visuals_id = sqlc.narg('visuals_id')::int,
+  updated_at = now()
+WHERE
+  id = @id::int AND owner_id = @owner_id::bigint;
+
+-- Fetch the custom exercises of a user with their names, muscles and the
+-- status of their latest submission
+-- name: GetCustomExercises :many
+SELECT
+  e.id,
+  e.equipment,
+  e.category,
+  e.level,
+  e.mechanic,
+  e.force,
+  e.visuals_id,
+  v.path AS visuals,
+  (CASE
+    WHEN e.instructions IS NULL THEN '{}'::text[]
+    WHEN e.instructions LIKE '{%}' THEN e.instructions::text[]
+    ELSE ARRAY[e.instructions]
+  END)::text[] AS instructions,
+  (SELECT coalesce(array_agg(n.name ORDER BY n.id), '{}') FROM exercise_names n WHERE n.exercise_id = e.id)::text[] AS names,
+  (SELECT coalesce(array_agg(m.name ORDER BY m.name), '{}') FROM exercise_muscle e_m INNER JOIN muscles m ON m.id = e_m.muscle_id
+    WHERE e_m.exercise_id = e.id AND e_m.is_primary)::text[] AS primary_muscles,
+  (SELECT coalesce(array_agg(m.name ORDER BY m.name), '{}') FROM exercise_muscle e_m INNER JOIN muscles m ON m.id = e_m.muscle_id
+    WHERE e_m.exercise_id = e.id AND NOT e_m.is_primary)::text[] AS secondary_muscles,
+  (SELECT s.status FROM exercise_submissions s WHERE s.exercise_id = e.id ORDER BY s.submitted_at DESC LIMIT 1)::submission_status_t AS submission_status
+FROM
+  exercises e
+  LEFT JOIN visuals v ON v.id = e.visuals_id
+WHERE
+  e.owner_id = @owner_id::bigint AND
+  (coalesce(sqlc.narg('exercise_id')) IS NULL OR e.id = @exercise_id::int)
+ORDER BY
+  e.id;
+
+-- name: InsertExerciseMuscle :exec
+INSERT INTO
+  exercise_muscle(exercise_id, muscle_id, is_primary)
+VALUES
+  (@exercise_id::int, @muscle_id::int, @is_primary::boolean);
+
+-- name: DeleteExerciseNames :exec
+DELETE FROM
+  exercise_names
+WHERE
+  exercise_id = @exercise_id::int;
+
+-- name: DeleteExerciseMuscles :exec
+DELETE FROM
+  exercise_muscle
+WHERE
+  exercise_id = @exercise_id::int;
+
+-- name: DeleteVisual :exec
+DELETE FROM
+  visuals
+WHERE
+  id = @id::int;
+
+-- Delete a custom exercise, fails on exercises that programs or workouts
+-- still refer to
+-- name: DeleteCustomExercise :execrows
+DELETE FROM
+  exercises
+WHERE
+  id = @id::int AND owner_id = @owner_id::bigint;
+
+-- Submit a custom exercise or start a draft of a submission, returning
+-- nothing while an earlier submission is still open
+-- name: InsertExerciseSubmission :one
+INSERT INTO
+  exercise_submissions(exercise_id, user_id, note, status, snapshot)
+VALUES
+  (@exercise_id::int, @user_id::bigint, @note::text, @status::submission_status_t, @snapshot::jsonb)
+ON CONFLICT (exercise_id) WHERE status NOT IN ('approved', 'rejected') DO NOTHING
+RETURNING *;
+
+-- name: GetExerciseSubmissions :many
+SELECT
+  *
+FROM
+  exercise_submissions
+WHERE
+  exercise_id = @exercise_id::int
+ORDER BY
+  submitted_at DESC;
+
+-- name: GetExerciseSubmission :one
+SELECT
+  *
+FROM
+  exercise_submissions
+WHERE
+  id = @id::uuid;
+
+-- Move a submission from one status to the next, returning nothing when it
+-- isn't in the expected status anymore. Moving to pending takes a new
+-- snapshot and submission time.
+-- name: TransitionExerciseSubmission :one
+UPDATE
+  exercise_submissions
+SET
+  status = @to_status::submission_status_t,
+  snapshot = coalesce(sqlc.narg('snapshot')::jsonb, snapshot),
+  submitted_at = (CASE WHEN @to_status::submission_status_t = 'pending' THEN now() ELSE submitted_at END),
+  reviewed_by = coalesce(sqlc.narg('reviewed_by')::bigint, reviewed_by),
+  reviewed_at = (CASE WHEN sqlc.narg('reviewed_by')::bigint IS NULL THEN reviewed_at ELSE now() END),
+  published_exercise_id = coalesce(sqlc.narg('published_exercise_id')::int, published_exercise_id)
+WHERE
+  id = @id::uuid AND status = @from_status::submission_status_t
+RETURNING *;
+
+-- Fetch the submissions in a status, oldest first, for the review queue
+-- name: GetSubmissionQueue :many
+SELECT
+  *
+FROM
+  exercise_submissions
+WHERE
+  status = @status::submission_status_t
+ORDER BY
+  submitted_at, id
+LIMIT sqlc.arg('limit')::int
+OFFSET sqlc.arg('offset')::int;
+
+-- name: GetUserSubmissions :many
+SELECT
+  *
+FROM
+  exercise_submissions
+WHERE
+  user_id = @user_id::bigint
+ORDER BY
+  submitted_at DESC;
+
+-- name: InsertSubmissionComment :one
+INSERT INTO
+  exercise_submission_comments(submission_id, author_id, body)
+VALUES
+  (@submission_id::uuid, @author_id::bigint, @body::text)
+RETURNING *;
+
+-- name: GetSubmissionComments :many
+SELECT
+  *
+FROM
+  exercise_submission_comments
+WHERE
+  submission_id = @submission_id::uuid
+ORDER BY
+  created_at, id;
+
+-- Insert an exercise into the public catalog
+-- name: InsertCatalogExercise :one
+INSERT INTO
+  exercises(equipment, category, level, mechanic, force, instructions, visuals_id)
+VALUES
+  (@equipment::equipment_t, sqlc.narg('category')::category_t, sqlc.narg('level')::level_t,
+   sqlc.narg('mechanic')::mechanic_t, sqlc.narg('force')::force_t, @instructions::text[]::text, sqlc.narg('visuals_id')::int)
+RETURNING id;
+
+-- Fetch the catalog names among the given lower case names
+-- name: GetCatalogNameConflicts :many
+SELECT
+  e_names.name
+FROM
+  exercise_names e_names
+  INNER JOIN exercises e ON e.id = e_names.exercise_id
+WHERE
+  e.owner_id IS NULL AND lower(e_names.name) = ANY(@names::text[])
+ORDER BY
+  e_names.name;
+
+
+-- Fetch the details of the given exercises the viewer can use, for the
+-- batched loaders of the GraphQL API
+-- name: GetExercisesByIds :many
+SELECT
+  e.id,
+  e.equipment,
+  e.category,
+  e.level,
+  e.mechanic,
+  e.force,
+  e.owner_id,
+  v.path AS visuals,
+  (CASE
+    WHEN e.instructions IS NULL THEN '{}'::text[]
+    WHEN e.instructions LIKE '{%}' THEN e.instructions::text[]
+    ELSE ARRAY[e.instructions]
+  END)::text[] AS instructions,
+  (SELECT coalesce(array_agg(n.name ORDER BY n.id), '{}') FROM exercise_names n WHERE n.exercise_id = e.id)::text[] AS names
+FROM
+  exercises e
+  LEFT JOIN visuals v ON v.id = e.visuals_id
+WHERE
+  e.id = ANY(@exercise_ids::int[]) AND
+  (e.owner_id IS NULL OR e.owner_id = sqlc.narg('viewer_id')::bigint OR e.owner_id IN (SELECT coach_id FROM coach_clients WHERE client_id = sqlc.narg('viewer_id')::bigint AND ended_at IS NULL));
+
+-- Fetch the muscles of the given exercises, primary muscles first
+-- name: GetMusclesByExerciseIds :many
+SELECT
+  e_m.exercise_id,
+  m.id,
+  m.name,
+  e_m.is_primary
+FROM
+  exercise_muscle e_m
+  INNER JOIN muscles m ON m.id = e_m.muscle_id
+WHERE
+  e_m.exercise_id = ANY(@exercise_ids::int[])
+ORDER BY
+  e_m.exercise_id, e_m.is_primary DESC, m.name;
+
+-- Fetch the logged sets of the given workouts
+-- name: GetWorkoutSetsByWorkoutIds :many
+SELECT
+  *
+FROM
+  workout_sets
+WHERE
+  workout_id = ANY(@workout_ids::uuid[])
+ORDER BY
+  workout_id, performed_at, id;
+
+
+-- Record a domain event in the outbox
+-- name: InsertOutboxEvent :one
+INSERT INTO
+  outbox_events(event_type, aggregate_id, payload)
+VALUES
+  (@event_type::text, @aggregate_id::text, @payload::jsonb)
+RETURNING *;
+
+-- Lock the oldest events due for publishing. Rows locked by another relay
+-- are skipped.
+-- name: ClaimOutboxEvents :many
+SELECT
+  *
+FROM
+  outbox_events
+WHERE
+  published_at IS NULL AND next_attempt_at <= now()
+ORDER BY
+  created_at, id
+LIMIT sqlc.arg('limit')::int
+FOR UPDATE SKIP LOCKED;
+
+-- name: MarkOutboxEventPublished :exec
+UPDATE
+  outbox_events
+SET
+  published_at = now(),
+  attempts = attempts + 1,
+  last_error = NULL
+WHERE
+  id = @id::uuid;
+
+-- Put an event back for a later attempt, backing off exponentially up to
+-- five minutes
+-- name: MarkOutboxEventFailed :exec
+UPDATE
+  outbox_events
+SET
+  attempts = attempts + 1,
+  last_error = @last_error::text,
+  next_attempt_at = now() + make_interval(secs => least(power(2, attempts), 300))
+WHERE
+  id = @id::uuid;
+
+-- name: InsertWebhook :one
+INSERT INTO
+  webhooks(user_id, url, secret, events)
+VALUES
+  (@user_id::bigint, @url::text, @secret::text, @events::text[])
+RETURNING *;
+
+-- name: GetWebhooks :many
+SELECT
+  *
+FROM
+  webhooks
+WHERE
+  user_id = @user_id::bigint
+ORDER BY
+  created_at;
+
+-- name: GetWebhook :one
+SELECT
+  *
+FROM
+  webhooks
+WHERE
+  id = @id::uuid AND user_id = @user_id::bigint;
+
+-- name: DeleteWebhook :execrows
+DELETE FROM
+  webhooks
+WHERE
+  id = @id::uuid AND user_id = @user_id::bigint;
+
+-- Webhooks of a user subscribed to an event type
+-- name: GetWebhooksForEvent :many
+SELECT
+  *
+FROM
+  webhooks
+WHERE
+  user_id = @user_id::bigint AND @event_type::text = ANY(events);
+
+-- Queue an event for a webhook. An event handed over again by the relay is
+-- dropped.
+-- name: InsertWebhookDelivery :exec
+INSERT INTO
+  webhook_deliveries(webhook_id, event_id, event_type, payload)
+VALUES
+  (@webhook_id::uuid, @event_id::uuid, @event_type::text, @payload::jsonb)
+ON CONFLICT (webhook_id, event_id) DO NOTHING;
+
+-- Lease the oldest deliveries due for an attempt, with the URL and secret
+-- of their webhook: they aren't due again until the lease ends, so other
+-- senders skip them while they're sent outside of any transaction. A sender
+-- that dies mid-batch leaves them to be retried once the lease ends.
+-- name: ClaimWebhookDeliveries :many
+WITH due AS (
+  SELECT
+    id
+  FROM
+    webhook_deliveries
+  WHERE
+    status = 'pending' AND next_attempt_at <= now()
+  ORDER BY
+    next_attempt_at, id
+  LIMIT sqlc.arg('limit')::int
+  FOR UPDATE SKIP LOCKED
+), leased AS (
+  UPDATE
+    webhook_deliveries d
+  SET
+    next_attempt_at = now() + make_interval(secs => @lease_seconds::int)
+  FROM
+    due
+  WHERE
+    d.id = due.id
+  RETURNING
+    d.id, d.webhook_id, d.event_id, d.event_type, d.payload, d.attempts
+)
+SELECT
+  l.id,
+  l.event_id,
+  l.event_type,
+  l.payload,
+  l.attempts,
+  w.url,
+  w.secret
+FROM
+  leased l
+  JOIN webhooks w ON w.id = l.webhook_id
+ORDER BY
+  l.id;
+
+-- name: MarkWebhookDeliverySucceeded :exec
+UPDATE
+  webhook_deliveries
+SET
+  status = 'succeeded',
+  attempts = attempts + 1,
+  response_status = @response_status::int,
+  last_error = NULL,
+  delivered_at = now()
+WHERE
+  id = @id::uuid;
+
+-- Record a failed attempt. The delivery is retried with exponential backoff,
+-- up to an hour apart, until it ran out of attempts.
+-- name: MarkWebhookDeliveryFailed :exec
+UPDATE
+  webhook_deliveries
+SET
+  status = CASE WHEN attempts + 1 >= @max_attempts::int THEN 'failed'::delivery_status_t ELSE 'pending'::delivery_status_t END,
+  attempts = attempts + 1,
+  response_status = sqlc.narg('response_status')::int,
+  last_error = @last_error::text,
+  next_attempt_at = now() + make_interval(secs => least(30 * power(2, attempts), 3600))
+WHERE
+  id = @id::uuid;
+
+-- name: InsertWebhookDeliveryAttempt :exec
+INSERT INTO
+  webhook_delivery_attempts(delivery_id, attempt, response_status, error, duration_ms)
+VALUES
+  (@delivery_id::uuid, @attempt::int, sqlc.narg('response_status')::int, sqlc.narg('error')::text, @duration_ms::int);
+
+-- Attempts of the given deliveries, oldest first
+-- name: GetWebhookDeliveryAttempts :many
+SELECT
+  *
+FROM
+  webhook_delivery_attempts
+WHERE
+  delivery_id = ANY(@delivery_ids::uuid[])
+ORDER BY
+  delivery_id, attempt;
+
+-- name: GetWebhookDeliveries :many
+SELECT
+  *
+FROM
+  webhook_deliveries
+WHERE
+  webhook_id = @webhook_id::uuid
+  AND (sqlc.narg('status')::delivery_status_t IS NULL OR status = sqlc.narg('status')::delivery_status_t)
+ORDER BY
+  created_at DESC, id
+LIMIT sqlc.arg('limit')::int
+OFFSET sqlc.arg('offset')::int;
+
+-- Send a failed delivery again, with a fresh set of attempts
+-- name: ReplayWebhookDelivery :one
+UPDATE
+  webhook_deliveries
+SET
+  status = 'pending',
+  attempts = 0,
+  next_attempt_at = now(),
+  last_error = NULL
+WHERE
+  id = @id::uuid AND webhook_id = @webhook_id::uuid AND status = 'failed'
+RETURNING *;
+
+-- name: ReplayFailedWebhookDeliveries :execrows
+UPDATE
+  webhook_deliveries
+SET
+  status = 'pending',
+  attempts = 0,
+  next_attempt_at = now(),
+  last_error = NULL
+WHERE
+  webhook_id = @webhook_id::uuid AND status = 'failed';
+
+
+-- name: TouchProgram :exec
+UPDATE
+  program_access
+SET
+  updated_at = now()
+WHERE
+  program_id = @program_id::uuid;
+
+-- Latest revision of a program, locking the program until the transaction
+-- ends so it can't change in between
+-- name: LockProgramRevision :one
+SELECT
+  (SELECT coalesce(max(r.revision), 0) FROM program_revisions r WHERE r.program_id = pa.program_id)::int AS revision
+FROM
+  program_access pa
+WHERE
+  pa.program_id = @program_id::uuid
+FOR UPDATE OF pa;
+
+-- The time of the database, checkpoints are issued from it
+-- name: GetSyncClock :one
+SELECT now()::timestamptz;
+
+-- Programs of a user changed after the checkpoint, with their latest
+-- revision
+-- name: GetProgramsChangedSince :many
+SELECT
+  pa.program_id,
+  pa.visibility,
+  pa.updated_at,
+  (SELECT coalesce(max(r.revision), 0) FROM program_revisions r WHERE r.program_id = pa.program_id)::int AS revision
+FROM
+  program_access pa
+WHERE
+  pa.owner_id = @owner_id::bigint AND pa.updated_at > @since::timestamptz
+ORDER BY
+  pa.updated_at, pa.program_id;
+
+-- name: GetWorkoutsChangedSince :many
+SELECT
+  *
+FROM
+  workouts
+WHERE
+  user_id = @user_id::bigint AND updated_at > @since::timestamptz
+ORDER BY
+  updated_at, id;
+
+-- name: GetFavoritesChangedSince :many
+SELECT
+  *
+FROM
+  exercise_favorites
+WHERE
+  user_id = @user_id::bigint AND created_at > @since::timestamptz
+ORDER BY
+  created_at, exercise_id;
+
+-- Exercises the viewer can use changed after the checkpoint, grouped like
+-- GetExercises
+-- name: GetExercisesChangedSince :many
+SELECT
+    e.id,
+    string_agg(DISTINCT e_names.name, ', ') AS names_grouped,
+    e.equipment,
+    e.owner_id,
+    string_agg(DISTINCT m.name, ', ') AS muscles_grouped,
+    string_agg(DISTINCT v.path, ', ') AS visuals_grouped
+FROM exercises e
+INNER JOIN exercise_names e_names ON e_names.exercise_id = e.id
+INNER JOIN exercise_muscle e_m ON e_m.exercise_id = e.id
+INNER JOIN muscles m ON m.id = e_m.muscle_id
+LEFT JOIN visuals v ON v.id = e.visuals_id
+WHERE
+  e.updated_at > @since::timestamptz AND
+  (e.owner_id IS NULL OR e.owner_id = sqlc.narg('viewer_id')::bigint OR e.owner_id IN (SELECT coach_id FROM coach_clients WHERE client_id = sqlc.narg('viewer_id')::bigint AND ended_at IS NULL))
+GROUP BY
+    e.id
+ORDER BY e.id;
+
+-- name: InsertSyncTombstone :exec
+INSERT INTO
+  sync_tombstones(user_id, entity, entity_id)
+VALUES
+  (sqlc.narg('user_id')::bigint, @entity::text, @entity_id::text);
+
+-- Forget the deletion of a row that was created again
+-- name: DeleteSyncTombstones :exec
+DELETE FROM
+  sync_tombstones
+WHERE
+  user_id = @user_id::bigint AND entity = @entity::text AND entity_id = @entity_id::text;
+
+-- Deletions after the checkpoint concerning the user or every client
+-- name: GetSyncTombstonesSince :many
+SELECT
+  *
+FROM
+  sync_tombstones
+WHERE
+  (user_id IS NULL OR user_id = @user_id::bigint) AND deleted_at > @since::timestamptz
+ORDER BY
+  deleted_at, id;
@@ -18,6 +18,101 @@ ENUM(
   'Other'
 );
 
+CREATE TYPE category_t
+AS
+ENUM(
+  'strength',
+  'stretching',
+  'plyometrics',
+  'powerlifting',
+  'olympic weightlifting',
+  'strongman',
+  'cardio'
+);
+
+CREATE TYPE record_type_t
+AS
+ENUM(
+  'weight',
+  'e1rm',
+  'volume'
+);
+
+CREATE TYPE level_t
+AS
+ENUM(
+  'beginner',
+  'intermediate',
+  'expert'
+);
+
+CREATE TYPE mechanic_t
+AS
+ENUM(
+  'compound',
+  'isolation'
+);
+
+CREATE TYPE force_t
+AS
+ENUM(
+  'push',
+  'pull',
+  'static'
+);
+
+CREATE TYPE visibility_t
+AS
+ENUM(
+  'private',
+  'link',
+  'public'
+);
+
+CREATE TYPE import_status_t
+AS
+ENUM(
+  'pending',
+  'committed'
+);
+
+CREATE TYPE revision_source_t
+AS
+ENUM(
+  'created',
+  'updated',
+  'progression',
+  'restored',
+  'generated',
+  'imported',
+  'forked'
+);
+
+CREATE TYPE role_t
+AS
+ENUM(
+  'coach',
+  'client',
+  'admin'
+);
+
+CREATE TYPE submission_status_t
+AS
+ENUM(
+  'draft',
+  'pending',
+  'approved',
+  'rejected'
+);
+
+CREATE TYPE delivery_status_t
+AS
+ENUM(
+  'pending',
+  'succeeded',
+  'failed'
+);
+
 CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
 
 CREATE TABLE IF NOT EXISTS visuals (
@@ -28,10 +123,20 @@ CREATE TABLE IF NOT EXISTS visuals (
 CREATE TABLE IF NOT EXISTS exercises (
   id SERIAL PRIMARY KEY,
   equipment equipment_t,
+  category category_t,
+  level level_t,
+  mechanic mechanic_t,
+  force force_t,
+  instructions TEXT,
   visuals_id INT,
+  owner_id BIGINT,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
   FOREIGN KEY (visuals_id) REFERENCES visuals (id)
 );
 
+CREATE INDEX IF NOT EXISTS exercises_owner_id_idx ON exercises (owner_id) WHERE owner_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS exercises_updated_at_idx ON exercises (updated_at);
+
 CREATE TABLE IF NOT EXISTS muscles (
   id SERIAL PRIMARY KEY,
   name VARCHAR(100) UNIQUE
@@ -40,13 +145,15 @@ CREATE TABLE IF NOT EXISTS muscles (
 CREATE TABLE IF NOT EXISTS exercise_names (
   id SERIAL PRIMARY KEY,
   exercise_id INT NOT NULL,
-  name VARCHAR(255) NOT NULL UNIQUE,
+  name VARCHAR(255) NOT NULL,
+  UNIQUE (exercise_id, name),
   FOREIGN KEY (exercise_id) REFERENCES exercises (id)
 );
 
 CREATE TABLE IF NOT EXISTS exercise_muscle (
   exercise_id INT NOT NULL,
   muscle_id INT,
+  is_primary BOOLEAN NOT NULL DEFAULT FALSE,
   PRIMARY KEY (exercise_id, muscle_id),
   FOREIGN KEY (exercise_id) REFERENCES exercises (id),
   FOREIGN KEY (muscle_id) REFERENCES muscles (id)
@@ -58,6 +165,302 @@ CREATE TABLE IF NOT EXISTS programs (
   exercise_id INT NOT NULL,
   sets INT NOT NULL,
   reps INT NOT NULL,
-  PRIMARY KEY (id, exercise_id),
+  day INT NOT NULL DEFAULT 1,
+  PRIMARY KEY (id, idx),
+  FOREIGN KEY (exercise_id) REFERENCES exercises (id)
+);
+
+CREATE TABLE IF NOT EXISTS program_sets (
+  program_id UUID NOT NULL,
+  idx INT NOT NULL,
+  set_number INT NOT NULL,
+  reps INT,
+  weight_kg DOUBLE PRECISION,
+  one_rm_percent DOUBLE PRECISION,
+  rpe DOUBLE PRECISION,
+  rir INT,
+  tempo VARCHAR(16),
+  rest_seconds INT,
+  duration_seconds INT,
+  distance_meters DOUBLE PRECISION,
+  PRIMARY KEY (program_id, idx, set_number),
+  FOREIGN KEY (program_id, idx) REFERENCES programs (id, idx) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS workouts (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  user_id BIGINT NOT NULL,
+  program_id UUID NOT NULL,
+  day INT NOT NULL,
+  started_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  finished_at TIMESTAMPTZ,
+  notes TEXT,
+  analyzed_at TIMESTAMPTZ,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS workouts_user_updated_idx ON workouts (user_id, updated_at);
+
+CREATE TABLE IF NOT EXISTS workout_sets (
+  id BIGSERIAL PRIMARY KEY,
+  workout_id UUID NOT NULL,
+  program_id UUID,
+  program_idx INT,
+  exercise_id INT NOT NULL,
+  set_number INT NOT NULL,
+  reps INT,
+  weight_kg DOUBLE PRECISION,
+  rpe DOUBLE PRECISION,
+  duration_seconds INT,
+  distance_meters DOUBLE PRECISION,
+  notes TEXT,
+  performed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  client_id UUID,
+  FOREIGN KEY (workout_id) REFERENCES workouts (id) ON DELETE CASCADE,
+  FOREIGN KEY (program_id, program_idx) REFERENCES programs (id, idx) ON DELETE SET NULL,
   FOREIGN KEY (exercise_id) REFERENCES exercises (id)
-);
\ No newline at end of file
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS workout_sets_client_idx ON workout_sets (workout_id, client_id);
+
+CREATE TABLE IF NOT EXISTS weekly_muscle_volume (
+  user_id BIGINT NOT NULL,
+  week_start DATE NOT NULL,
+  muscle_id INT NOT NULL,
+  sets INT NOT NULL,
+  reps INT NOT NULL,
+  tonnage_kg DOUBLE PRECISION NOT NULL,
+  PRIMARY KEY (user_id, week_start, muscle_id),
+  FOREIGN KEY (muscle_id) REFERENCES muscles (id)
+);
+
+CREATE TABLE IF NOT EXISTS exercise_e1rm (
+  workout_id UUID NOT NULL,
+  exercise_id INT NOT NULL,
+  user_id BIGINT NOT NULL,
+  performed_at TIMESTAMPTZ NOT NULL,
+  epley_kg DOUBLE PRECISION NOT NULL,
+  brzycki_kg DOUBLE PRECISION NOT NULL,
+  PRIMARY KEY (workout_id, exercise_id),
+  FOREIGN KEY (workout_id) REFERENCES workouts (id) ON DELETE CASCADE,
+  FOREIGN KEY (exercise_id) REFERENCES exercises (id)
+);
+
+CREATE TABLE IF NOT EXISTS personal_records (
+  id BIGSERIAL PRIMARY KEY,
+  user_id BIGINT NOT NULL,
+  exercise_id INT NOT NULL,
+  record_type record_type_t NOT NULL,
+  value DOUBLE PRECISION NOT NULL,
+  previous_value DOUBLE PRECISION,
+  workout_id UUID NOT NULL,
+  workout_set_id BIGINT NOT NULL,
+  achieved_at TIMESTAMPTZ NOT NULL,
+  FOREIGN KEY (workout_id) REFERENCES workouts (id) ON DELETE CASCADE,
+  FOREIGN KEY (exercise_id) REFERENCES exercises (id)
+);
+CREATE TABLE IF NOT EXISTS program_access (
+  program_id UUID PRIMARY KEY,
+  owner_id BIGINT,
+  visibility visibility_t NOT NULL DEFAULT 'public',
+  forked_from UUID,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS program_access_owner_updated_idx ON program_access (owner_id, updated_at);
+
+CREATE TABLE IF NOT EXISTS program_share_tokens (
+  token VARCHAR(64) PRIMARY KEY,
+  program_id UUID NOT NULL,
+  created_by BIGINT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  revoked_at TIMESTAMPTZ,
+  FOREIGN KEY (program_id) REFERENCES program_access (program_id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS program_imports (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  user_id BIGINT NOT NULL,
+  filename VARCHAR(255) NOT NULL,
+  status import_status_t NOT NULL DEFAULT 'pending',
+  entries JSONB NOT NULL,
+  program_id UUID,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS program_revisions (
+  program_id UUID NOT NULL,
+  revision INT NOT NULL,
+  source revision_source_t NOT NULL,
+  restored_from INT,
+  created_by BIGINT,
+  items JSONB NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (program_id, revision),
+  FOREIGN KEY (program_id) REFERENCES program_access (program_id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS program_schedules (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  user_id BIGINT NOT NULL,
+  program_id UUID NOT NULL,
+  start_date DATE NOT NULL,
+  weekdays INT[] NOT NULL,
+  weeks INT NOT NULL,
+  start_time TIME,
+  duration_minutes INT NOT NULL DEFAULT 60,
+  timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  FOREIGN KEY (program_id) REFERENCES program_access (program_id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS calendar_feeds (
+  user_id BIGINT PRIMARY KEY,
+  token VARCHAR(64) NOT NULL UNIQUE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS user_roles (
+  user_id BIGINT NOT NULL,
+  role role_t NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (user_id, role)
+);
+
+CREATE TABLE IF NOT EXISTS coach_invitations (
+  code VARCHAR(64) PRIMARY KEY,
+  coach_id BIGINT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  expires_at TIMESTAMPTZ NOT NULL,
+  accepted_by BIGINT,
+  accepted_at TIMESTAMPTZ,
+  revoked_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS coach_clients (
+  coach_id BIGINT NOT NULL,
+  client_id BIGINT NOT NULL,
+  share_workouts BOOLEAN NOT NULL DEFAULT TRUE,
+  allow_assignments BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  ended_at TIMESTAMPTZ,
+  PRIMARY KEY (coach_id, client_id)
+);
+
+CREATE TABLE IF NOT EXISTS program_assignments (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  coach_id BIGINT NOT NULL,
+  client_id BIGINT NOT NULL,
+  program_id UUID NOT NULL,
+  assigned_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  unassigned_at TIMESTAMPTZ,
+  FOREIGN KEY (coach_id, client_id) REFERENCES coach_clients (coach_id, client_id),
+  FOREIGN KEY (program_id) REFERENCES program_access (program_id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS exercise_favorites (
+  user_id BIGINT NOT NULL,
+  exercise_id INT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (user_id, exercise_id),
+  FOREIGN KEY (exercise_id) REFERENCES exercises (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS exercise_collections (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  user_id BIGINT NOT NULL,
+  name VARCHAR(100) NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  UNIQUE (user_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS collection_exercises (
+  collection_id UUID NOT NULL,
+  exercise_id INT NOT NULL,
+  added_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (collection_id, exercise_id),
+  FOREIGN KEY (collection_id) REFERENCES exercise_collections (id) ON DELETE CASCADE,
+  FOREIGN KEY (exercise_id) REFERENCES exercises (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS exercise_submissions (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  exercise_id INT NOT NULL,
+  user_id BIGINT NOT NULL,
+  status submission_status_t NOT NULL DEFAULT 'pending',
+  note TEXT NOT NULL DEFAULT '',
+  submitted_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  snapshot JSONB,
+  reviewed_by BIGINT,
+  reviewed_at TIMESTAMPTZ,
+  published_exercise_id INT REFERENCES exercises (id),
+  FOREIGN KEY (exercise_id) REFERENCES exercises (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS exercise_submissions_open_idx ON exercise_submissions (exercise_id) WHERE status NOT IN ('approved', 'rejected');
+
+CREATE TABLE IF NOT EXISTS exercise_submission_comments (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  submission_id UUID NOT NULL,
+  author_id BIGINT NOT NULL,
+  body TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  FOREIGN KEY (submission_id) REFERENCES exercise_submissions (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS outbox_events (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  event_type VARCHAR(100) NOT NULL,
+  aggregate_id TEXT NOT NULL,
+  payload JSONB NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  published_at TIMESTAMPTZ,
+  attempts INT NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  last_error TEXT
+);
+
+CREATE INDEX IF NOT EXISTS outbox_events_unpublished_idx ON outbox_events (next_attempt_at) WHERE published_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS webhooks (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  user_id BIGINT NOT NULL,
+  url TEXT NOT NULL,
+  secret TEXT NOT NULL,
+  events TEXT[] NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS webhooks_user_idx ON webhooks (user_id);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  webhook_id UUID NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+  event_id UUID NOT NULL,
+  event_type VARCHAR(100) NOT NULL,
+  payload JSONB NOT NULL,
+  status delivery_status_t NOT NULL DEFAULT 'pending',
+  attempts INT NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  response_status INT,
+  last_error TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  delivered_at TIMESTAMPTZ,
+  UNIQUE (webhook_id, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_idx ON webhook_deliveries (webhook_id, created_at);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_pending_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+
+CREATE TABLE IF NOT EXISTS sync_tombstones (
+  id BIGSERIAL PRIMARY KEY,
+  user_id BIGINT,
+  entity VARCHAR(50) NOT NULL,
+  entity_id TEXT NOT NULL,
+  deleted_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS sync_tombstones_deleted_at_idx ON sync_tombstones (deleted_at);
+CREATE INDEX IF NOT EXISTS sync_tombstones_entity_idx ON sync_tombstones (user_id, entity, entity_id);
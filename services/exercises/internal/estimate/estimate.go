@@ -0,0 +1,108 @@
+package estimate
+
+import (
+	"math"
+	"sort"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/models"
+)
+
+const (
+	// SetupSeconds is the time spent moving to and setting up an exercise.
+	SetupSeconds = 45
+	// SecondsPerRep is a rough time under tension for one rep when no tempo
+	// is prescribed.
+	SecondsPerRep = 4
+	// defaultRestSeconds is the rest taken after a set of reps that doesn't
+	// prescribe one. Timed sets are assumed to run into each other.
+	defaultRestSeconds = 60
+	// metersPerSecond is the pace assumed for sets prescribed by distance
+	// alone, an easy run.
+	metersPerSecond = 3
+)
+
+// Item estimates the seconds an item takes: setting up, then every set with
+// the rest that follows it.
+func Item(sets []models.SetPrescription) int {
+	total := SetupSeconds
+	for _, set := range sets {
+		total += Set(set)
+	}
+	return total
+}
+
+// Set estimates the seconds a set and its rest take.
+func Set(set models.SetPrescription) int {
+	total := 0
+	switch {
+	case set.DurationSeconds != nil:
+		total += *set.DurationSeconds
+	case set.Reps != nil:
+		total += *set.Reps * repSeconds(set.Tempo)
+	case set.DistanceMeters != nil:
+		total += int(math.Round(*set.DistanceMeters / metersPerSecond))
+	}
+
+	switch {
+	case set.RestSeconds != nil:
+		total += *set.RestSeconds
+	case set.DurationSeconds == nil && set.Reps != nil:
+		total += defaultRestSeconds
+	}
+	return total
+}
+
+// repSeconds reads the length of a rep from a tempo such as "3-1-1-0" or
+// "31X0", an explosive phase counting for a second.
+func repSeconds(tempo *string) int {
+	if tempo == nil {
+		return SecondsPerRep
+	}
+	total := 0
+	for _, c := range *tempo {
+		switch {
+		case c >= '0' && c <= '9':
+			total += int(c - '0')
+		case c == 'X' || c == 'x':
+			total++
+		}
+	}
+	if total == 0 {
+		return SecondsPerRep
+	}
+	return total
+}
+
+// Program estimates the length of every session of a program, one per day,
+// and of the whole program.
+func Program(records []models.ProgramRecord) models.ProgramDuration {
+	byDay := make(map[int]int)
+	for _, rec := range records {
+		day := rec.Day
+		if day == 0 {
+			day = 1
+		}
+		byDay[day] += Item(rec.SetList())
+	}
+
+	d := models.ProgramDuration{Sessions: make([]models.SessionDuration, 0, len(byDay))}
+	total := 0
+	for day, seconds := range byDay {
+		d.Sessions = append(d.Sessions, models.SessionDuration{Day: day, Seconds: seconds, Minutes: minutes(seconds)})
+		total += seconds
+		d.LongestSessionMinutes = max(d.LongestSessionMinutes, minutes(seconds))
+	}
+	sort.Slice(d.Sessions, func(i, j int) bool { return d.Sessions[i].Day < d.Sessions[j].Day })
+
+	d.TotalSeconds = total
+	d.TotalMinutes = minutes(total)
+	if len(d.Sessions) > 0 {
+		d.AverageSessionMinutes = minutes(total / len(d.Sessions))
+	}
+	return d
+}
+
+// minutes rounds up, a session of 61 minutes doesn't fit in an hour.
+func minutes(seconds int) int {
+	return (seconds + 59) / 60
+}
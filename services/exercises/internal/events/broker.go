@@ -0,0 +1,113 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Broker publishes events to the other services. An event may be published
+// more than once, e.g. when the relay crashes before marking it published,
+// brokers drop the events they have seen already where they can.
+type Broker interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// Brokers publishes every event to each of its brokers in turn. An event
+// is handed to all of them again when one fails, each has to drop the
+// events it has seen.
+type Brokers []Broker
+
+func (bs Brokers) Publish(ctx context.Context, event Event) error {
+	for _, b := range bs {
+		if err := b.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MemoryBroker keeps the published events in memory, for tests. The
+// service itself always publishes to Redis, events handed to a
+// MemoryBroker would be marked published and lost on restart.
+type MemoryBroker struct {
+	mu     sync.Mutex
+	seen   map[uuid.UUID]bool
+	events []Event
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{seen: make(map[uuid.UUID]bool)}
+}
+
+func (b *MemoryBroker) Publish(ctx context.Context, event Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.seen[event.ID] {
+		return nil
+	}
+	b.seen[event.ID] = true
+	b.events = append(b.events, event)
+	return nil
+}
+
+// Events returns the events published so far, in order.
+func (b *MemoryBroker) Events() []Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]Event(nil), b.events...)
+}
+
+// DefaultStream is the Redis stream the events are appended to.
+const DefaultStream = "exercises.events"
+
+// publishOnce appends the event to the stream unless its dedupe key exists
+// already, both in one step so a retry never adds a second entry.
+var publishOnce = goredis.NewScript(`
+if redis.call("SET", KEYS[1], "1", "NX", "EX", ARGV[1]) then
+	return redis.call("XADD", KEYS[2], "MAXLEN", "~", ARGV[2], "*", "id", ARGV[3], "type", ARGV[4], "event", ARGV[5])
+end
+return false
+`)
+
+// RedisBroker appends the events to a Redis stream, the event ID is kept
+// for DedupeTTL to drop repeated deliveries.
+type RedisBroker struct {
+	Client    *goredis.Client
+	Stream    string
+	MaxLen    int64
+	DedupeTTL time.Duration
+}
+
+func NewRedisBroker(client *goredis.Client) *RedisBroker {
+	return &RedisBroker{
+		Client:    client,
+		Stream:    DefaultStream,
+		MaxLen:    100000,
+		DedupeTTL: 24 * time.Hour,
+	}
+}
+
+func (b *RedisBroker) Publish(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	keys := []string{b.Stream + ":seen:" + event.ID.String(), b.Stream}
+	args := []any{int64(b.DedupeTTL.Seconds()), b.MaxLen, event.ID.String(), event.Type, body}
+	err = publishOnce.Run(ctx, b.Client, keys, args...).Err()
+	if err == goredis.Nil {
+		// Published by an earlier attempt
+		return nil
+	}
+	return err
+}
+
+// NewBroker publishes to Redis at addr.
+func NewBroker(addr string) Broker {
+	return NewRedisBroker(goredis.NewClient(&goredis.Options{Addr: addr}))
+}
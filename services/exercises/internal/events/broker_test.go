@@ -0,0 +1,52 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// failingBroker fails every publish.
+type failingBroker struct {
+	calls int
+}
+
+func (b *failingBroker) Publish(ctx context.Context, event Event) error {
+	b.calls++
+	return errors.New("broker down")
+}
+
+func TestMemoryBrokerDropsRepeatedEvents(t *testing.T) {
+	b := NewMemoryBroker()
+	first := Event{ID: uuid.New(), Type: ProgramCreated}
+	second := Event{ID: uuid.New(), Type: ProgramUpdated}
+
+	for _, event := range []Event{first, second, first} {
+		if err := b.Publish(context.Background(), event); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+	}
+
+	got := b.Events()
+	if len(got) != 2 || got[0].ID != first.ID || got[1].ID != second.ID {
+		t.Errorf("events = %+v, want the two events once each, in order", got)
+	}
+}
+
+func TestBrokersStopAtTheFirstFailure(t *testing.T) {
+	before, after := NewMemoryBroker(), NewMemoryBroker()
+	failing := &failingBroker{}
+	bs := Brokers{before, failing, after}
+
+	if err := bs.Publish(context.Background(), Event{ID: uuid.New()}); err == nil {
+		t.Fatal("publish succeeded, want the error of the failing broker")
+	}
+	if len(before.Events()) != 1 {
+		t.Errorf("broker before the failure got %d events, want 1", len(before.Events()))
+	}
+	if len(after.Events()) != 0 {
+		t.Errorf("broker after the failure got %d events, want 0", len(after.Events()))
+	}
+}
@@ -0,0 +1,103 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/models"
+)
+
+// Types of the events published by the exercises service.
+const (
+	ProgramCreated   = "program.created"
+	ProgramUpdated   = "program.updated"
+	WorkoutStarted   = "workout.started"
+	WorkoutSetLogged = "workout.set_logged"
+	WorkoutCompleted = "workout.completed"
+)
+
+// Types lists every event type, in the order above.
+var Types = []string{ProgramCreated, ProgramUpdated, WorkoutStarted, WorkoutSetLogged, WorkoutCompleted}
+
+func IsType(eventType string) bool {
+	return slices.Contains(Types, eventType)
+}
+
+// Event is a domain event as it is handed to the broker. The ID stays the
+// same when an event is delivered again, consumers dedupe on it.
+type Event struct {
+	ID          uuid.UUID       `json:"id"`
+	Type        string          `json:"type"`
+	AggregateID string          `json:"aggregateId"`
+	Payload     json.RawMessage `json:"payload"`
+	OccurredAt  time.Time       `json:"occurredAt"`
+}
+
+// ProgramPayload is the payload of program.created and program.updated,
+// one per revision of the program.
+type ProgramPayload struct {
+	ProgramID uuid.UUID `json:"programId"`
+	Revision  int       `json:"revision"`
+	Source    string    `json:"source"`
+	UserID    *int64    `json:"userId,omitempty"`
+}
+
+// WorkoutPayload is the payload of the workout events. Set is only sent
+// with workout.set_logged.
+type WorkoutPayload struct {
+	WorkoutID  uuid.UUID          `json:"workoutId"`
+	UserID     int64              `json:"userId"`
+	ProgramID  uuid.UUID          `json:"programId"`
+	Day        int                `json:"day"`
+	StartedAt  time.Time          `json:"startedAt"`
+	FinishedAt *time.Time         `json:"finishedAt,omitempty"`
+	Set        *models.WorkoutSet `json:"set,omitempty"`
+}
+
+// Record writes an event to the outbox. q has to run inside the transaction
+// of the change the event describes, so the event is stored if and only if
+// the change commits.
+func Record(ctx context.Context, q *db.Queries, eventType, aggregateID string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	_, err = q.InsertOutboxEvent(ctx, db.InsertOutboxEventParams{
+		EventType:   eventType,
+		AggregateID: aggregateID,
+		Payload:     body,
+	})
+	return err
+}
+
+// WorkoutEvent builds the payload of a workout event from its row.
+func WorkoutEvent(row db.Workout) WorkoutPayload {
+	payload := WorkoutPayload{
+		WorkoutID: row.ID.Bytes,
+		UserID:    row.UserID,
+		ProgramID: row.ProgramID.Bytes,
+		Day:       int(row.Day),
+		StartedAt: row.StartedAt.Time,
+	}
+	if row.FinishedAt.Valid {
+		finished := row.FinishedAt.Time
+		payload.FinishedAt = &finished
+	}
+	return payload
+}
+
+// FromRow maps an outbox row to the event published for it.
+func FromRow(row db.OutboxEvent) Event {
+	return Event{
+		ID:          row.ID.Bytes,
+		Type:        row.EventType,
+		AggregateID: row.AggregateID,
+		Payload:     row.Payload,
+		OccurredAt:  row.CreatedAt.Time,
+	}
+}
@@ -0,0 +1,94 @@
+package events
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+)
+
+// Relay publishes the events of the outbox. Rows are claimed with SKIP
+// LOCKED so several replicas can relay at once, a row is marked published
+// only after the broker accepted it, failed rows are retried with backoff.
+type Relay struct {
+	Broker    Broker
+	Interval  time.Duration
+	BatchSize int32
+}
+
+func NewRelay(broker Broker) *Relay {
+	return &Relay{Broker: broker, Interval: time.Second, BatchSize: 100}
+}
+
+// Run flushes the outbox every Interval until ctx is done.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := r.Flush(ctx); err != nil {
+				log.Printf("Error at relaying outbox events: %v", err)
+			}
+		}
+	}
+}
+
+// Flush publishes one batch of due events and returns how many were
+// published.
+func (r *Relay) Flush(ctx context.Context) (int, error) {
+	tx, err := db.GetPool().Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := db.Queriez.WithTx(tx)
+
+	rows, err := qtx.ClaimOutboxEvents(ctx, r.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	published, err := r.publish(ctx, qtx, rows)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return published, nil
+}
+
+// outboxMarker records the outcome of publishing claimed rows, it is
+// *db.Queries in the transaction that claimed them.
+type outboxMarker interface {
+	MarkOutboxEventPublished(ctx context.Context, id pgtype.UUID) error
+	MarkOutboxEventFailed(ctx context.Context, arg db.MarkOutboxEventFailedParams) error
+}
+
+// publish hands the claimed rows to the broker in order and marks each
+// published or failed, returning how many were published.
+func (r *Relay) publish(ctx context.Context, q outboxMarker, rows []db.OutboxEvent) (int, error) {
+	published := 0
+	for _, row := range rows {
+		event := FromRow(row)
+		if err := r.Broker.Publish(ctx, event); err != nil {
+			log.Printf("Error at publishing event: %v, id: %s", err, event.ID)
+			err = q.MarkOutboxEventFailed(ctx, db.MarkOutboxEventFailedParams{LastError: err.Error(), ID: row.ID})
+			if err != nil {
+				return 0, err
+			}
+			continue
+		}
+		if err := q.MarkOutboxEventPublished(ctx, row.ID); err != nil {
+			return 0, err
+		}
+		published++
+	}
+	return published, nil
+}
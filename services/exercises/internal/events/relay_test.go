@@ -0,0 +1,115 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+)
+
+// fakeOutbox records the rows the relay marks.
+type fakeOutbox struct {
+	published []pgtype.UUID
+	failed    []db.MarkOutboxEventFailedParams
+}
+
+func (o *fakeOutbox) MarkOutboxEventPublished(ctx context.Context, id pgtype.UUID) error {
+	o.published = append(o.published, id)
+	return nil
+}
+
+func (o *fakeOutbox) MarkOutboxEventFailed(ctx context.Context, arg db.MarkOutboxEventFailedParams) error {
+	o.failed = append(o.failed, arg)
+	return nil
+}
+
+// flakyBroker fails the first Failures publishes.
+type flakyBroker struct {
+	Failures int
+}
+
+func (b *flakyBroker) Publish(ctx context.Context, event Event) error {
+	if b.Failures > 0 {
+		b.Failures--
+		return errors.New("broker down")
+	}
+	return nil
+}
+
+func outboxRow() db.OutboxEvent {
+	return db.OutboxEvent{
+		ID:          pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		EventType:   WorkoutSetLogged,
+		AggregateID: "1",
+		Payload:     []byte(`{}`),
+	}
+}
+
+func TestRelayMarksPublishedRows(t *testing.T) {
+	broker := NewMemoryBroker()
+	outbox := &fakeOutbox{}
+	rows := []db.OutboxEvent{outboxRow(), outboxRow()}
+
+	n, err := NewRelay(broker).publish(context.Background(), outbox, rows)
+	if err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if n != 2 || len(outbox.published) != 2 || len(outbox.failed) != 0 {
+		t.Fatalf("published %d, marked %d published and %d failed, want 2, 2 and 0", n, len(outbox.published), len(outbox.failed))
+	}
+	for i, event := range broker.Events() {
+		if event.ID != rows[i].ID.Bytes || event.Type != rows[i].EventType {
+			t.Errorf("event %d = %+v, want row %+v", i, event, rows[i])
+		}
+	}
+}
+
+func TestRelayMarksFailedRows(t *testing.T) {
+	outbox := &fakeOutbox{}
+	rows := []db.OutboxEvent{outboxRow(), outboxRow()}
+
+	n, err := NewRelay(&flakyBroker{Failures: 1}).publish(context.Background(), outbox, rows)
+	if err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("published %d, want 1", n)
+	}
+	if len(outbox.failed) != 1 || outbox.failed[0].ID != rows[0].ID || outbox.failed[0].LastError == "" {
+		t.Errorf("failed = %+v, want the first row with its error", outbox.failed)
+	}
+	if len(outbox.published) != 1 || outbox.published[0] != rows[1].ID {
+		t.Errorf("published = %+v, want the second row", outbox.published)
+	}
+}
+
+func TestRelayRetryPublishesOnce(t *testing.T) {
+	// The first broker accepts the event, the second fails, so the row is
+	// retried and handed to both again
+	memory := NewMemoryBroker()
+	relay := NewRelay(Brokers{memory, &flakyBroker{Failures: 1}})
+	rows := []db.OutboxEvent{outboxRow()}
+
+	outbox := &fakeOutbox{}
+	if _, err := relay.publish(context.Background(), outbox, rows); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if len(outbox.failed) != 1 {
+		t.Fatalf("failed = %+v, want the row marked failed", outbox.failed)
+	}
+
+	outbox = &fakeOutbox{}
+	if _, err := relay.publish(context.Background(), outbox, rows); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if len(outbox.published) != 1 {
+		t.Fatalf("published = %+v, want the row marked published on retry", outbox.published)
+	}
+	if got := memory.Events(); len(got) != 1 {
+		t.Errorf("memory broker got %d events, want 1", len(got))
+	}
+}
@@ -0,0 +1,82 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/models"
+)
+
+var csvHeader = []string{
+	"day", "idx", "exercise_id", "exercise", "equipment", "muscles", "set",
+	"reps", "weight_kg", "one_rm_percent", "rpe", "rir", "tempo", "rest_seconds", "duration_seconds", "distance_meters",
+}
+
+// renderCSV writes one row per set, so the sheet can be edited and summed in
+// a spreadsheet. Items without prescriptions get one row per set too.
+func renderCSV(w io.Writer, program *models.CompleteProgram) error {
+	out := csv.NewWriter(w)
+	if err := out.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, ex := range program.Exercises {
+		sets := ex.Prescriptions
+		if len(sets) == 0 {
+			for i := 0; i < ex.Sets; i++ {
+				reps := ex.Reps
+				sets = append(sets, models.SetPrescription{Reps: &reps})
+			}
+		}
+
+		for n, set := range sets {
+			err := out.Write([]string{
+				strconv.Itoa(ex.Day),
+				strconv.Itoa(ex.Idx),
+				strconv.Itoa(int(ex.Exercise.Id)),
+				exerciseName(ex.Exercise),
+				string(ex.Exercise.Equipment),
+				strings.Join(trimAll(ex.Exercise.Muscles), ", "),
+				strconv.Itoa(n + 1),
+				intCell(set.Reps),
+				floatCell(set.WeightKg),
+				floatCell(set.OneRmPercent),
+				floatCell(set.RPE),
+				intCell(set.RIR),
+				stringCell(set.Tempo),
+				intCell(set.RestSeconds),
+				intCell(set.DurationSeconds),
+				floatCell(set.DistanceMeters),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	out.Flush()
+	return out.Error()
+}
+
+func intCell(v *int) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.Itoa(*v)
+}
+
+func floatCell(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return number(*v)
+}
+
+func stringCell(v *string) string {
+	if v == nil {
+		return ""
+	}
+	return *v
+}
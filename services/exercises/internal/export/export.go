@@ -0,0 +1,213 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/models"
+)
+
+// Format is an output format of a program export.
+type Format string
+
+const (
+	PDF      Format = "pdf"
+	HTML     Format = "html"
+	CSV      Format = "csv"
+	Markdown Format = "md"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch Format(s) {
+	case PDF, HTML, CSV, Markdown:
+		return Format(s), nil
+	case "":
+		return PDF, nil
+	default:
+		return "", fmt.Errorf("unknown format %q, expected pdf, html, csv or md", s)
+	}
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case PDF:
+		return "application/pdf"
+	case HTML:
+		return "text/html; charset=utf-8"
+	case CSV:
+		return "text/csv; charset=utf-8"
+	default:
+		return "text/markdown; charset=utf-8"
+	}
+}
+
+// Render writes the program in the given format.
+func Render(w io.Writer, f Format, program *models.CompleteProgram) error {
+	switch f {
+	case PDF:
+		return renderPDF(w, program)
+	case HTML:
+		return renderHTML(w, program)
+	case CSV:
+		return renderCSV(w, program)
+	default:
+		return renderMarkdown(w, program)
+	}
+}
+
+// day and item are the program as the human readable formats lay it out.
+type day struct {
+	Number int
+	Items  []item
+}
+
+type item struct {
+	Position     int
+	Name         string
+	Equipment    string
+	Muscles      string
+	Sets         []string
+	Instructions []string
+}
+
+// Meta is the equipment and muscles of the item on one line.
+func (it item) Meta() string {
+	return strings.Join(trimAll([]string{it.Equipment, it.Muscles}), " · ")
+}
+
+func title(program *models.CompleteProgram) string {
+	return "Program " + program.UUID.String()
+}
+
+// layout groups the items of the program by day, in order.
+func layout(program *models.CompleteProgram) []day {
+	byDay := make(map[int][]item)
+	for _, ex := range program.Exercises {
+		byDay[ex.Day] = append(byDay[ex.Day], item{
+			Position:     len(byDay[ex.Day]) + 1,
+			Name:         exerciseName(ex.Exercise),
+			Equipment:    string(ex.Exercise.Equipment),
+			Muscles:      strings.Join(trimAll(ex.Exercise.Muscles), ", "),
+			Sets:         setLines(ex),
+			Instructions: trimAll(ex.Exercise.Instructions),
+		})
+	}
+
+	days := make([]day, 0, len(byDay))
+	for number, items := range byDay {
+		days = append(days, day{Number: number, Items: items})
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Number < days[j].Number })
+	return days
+}
+
+// Outline lists the exercises of every day of the program, one line each
+// with their sets, e.g. "Bench Press: 3 × 8 reps @ 80 kg".
+func Outline(program *models.CompleteProgram) map[int][]string {
+	outline := make(map[int][]string)
+	for _, d := range layout(program) {
+		for _, it := range d.Items {
+			outline[d.Number] = append(outline[d.Number], it.Name+": "+strings.Join(it.Sets, "; "))
+		}
+	}
+	return outline
+}
+
+func exerciseName(ex models.Exercise) string {
+	for _, name := range ex.Names {
+		if name = strings.TrimSpace(name); name != "" {
+			return name
+		}
+	}
+	return fmt.Sprintf("Exercise %d", ex.Id)
+}
+
+func trimAll(values []string) []string {
+	trimmed := make([]string, 0, len(values))
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			trimmed = append(trimmed, v)
+		}
+	}
+	return trimmed
+}
+
+// setLines describes the sets of an item, folding runs of identical sets
+// into one line such as "3 × 8 reps @ 80 kg, RPE 8, rest 90s".
+func setLines(ex models.ProgramExercise) []string {
+	if len(ex.Prescriptions) == 0 {
+		return []string{fmt.Sprintf("%d × %d reps", ex.Sets, ex.Reps)}
+	}
+
+	lines := make([]string, 0)
+	for i := 0; i < len(ex.Prescriptions); {
+		desc := describe(ex.Prescriptions[i])
+		j := i + 1
+		for j < len(ex.Prescriptions) && describe(ex.Prescriptions[j]) == desc {
+			j++
+		}
+		if j-i > 1 {
+			desc = fmt.Sprintf("%d × %s", j-i, desc)
+		}
+		lines = append(lines, desc)
+		i = j
+	}
+	return lines
+}
+
+// describe writes a single set prescription out in words.
+func describe(s models.SetPrescription) string {
+	var work []string
+	if s.Reps != nil {
+		work = append(work, fmt.Sprintf("%d reps", *s.Reps))
+	}
+	if s.DurationSeconds != nil {
+		work = append(work, seconds(*s.DurationSeconds))
+	}
+	if s.DistanceMeters != nil {
+		work = append(work, number(*s.DistanceMeters)+" m")
+	}
+	desc := strings.Join(work, ", ")
+
+	switch {
+	case s.WeightKg != nil:
+		desc += " @ " + number(*s.WeightKg) + " kg"
+	case s.OneRmPercent != nil:
+		desc += " @ " + number(*s.OneRmPercent) + "% 1RM"
+	}
+
+	var extra []string
+	if s.RPE != nil {
+		extra = append(extra, "RPE "+number(*s.RPE))
+	}
+	if s.RIR != nil {
+		extra = append(extra, fmt.Sprintf("%d RIR", *s.RIR))
+	}
+	if s.Tempo != nil {
+		extra = append(extra, "tempo "+*s.Tempo)
+	}
+	if s.RestSeconds != nil {
+		extra = append(extra, "rest "+seconds(*s.RestSeconds))
+	}
+	if len(extra) > 0 {
+		desc += ", " + strings.Join(extra, ", ")
+	}
+	return desc
+}
+
+func seconds(s int) string {
+	if s >= 60 && s%60 == 0 {
+		return fmt.Sprintf("%dmin", s/60)
+	}
+	if s > 60 {
+		return fmt.Sprintf("%dmin %ds", s/60, s%60)
+	}
+	return fmt.Sprintf("%ds", s)
+}
+
+func number(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,67 @@
+package export
+
+import (
+	"html/template"
+	"io"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/models"
+)
+
+var htmlTemplate = template.Must(template.New("program").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+  body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2rem; color: #111; }
+  h1 { font-size: 1.5rem; }
+  h2 { font-size: 1.2rem; border-bottom: 1px solid #999; padding-bottom: .2rem; margin-top: 2rem; }
+  table { width: 100%; border-collapse: collapse; }
+  th, td { text-align: left; vertical-align: top; padding: .4rem; border-bottom: 1px solid #ddd; }
+  th { font-size: .8rem; text-transform: uppercase; color: #555; }
+  td.done { width: 3rem; }
+  ul, ol { margin: 0; padding-left: 1.2rem; }
+  .meta { color: #555; font-size: .85rem; }
+  .instructions { font-size: .8rem; color: #333; }
+  @media print {
+    body { margin: 0; }
+    h2 { page-break-after: avoid; }
+    section { page-break-inside: avoid; }
+  }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{range .Days}}
+<section>
+<h2>Day {{.Number}}</h2>
+<table>
+  <thead><tr><th>#</th><th>Exercise</th><th>Sets</th><th>Done</th></tr></thead>
+  <tbody>
+  {{range .Items}}
+  <tr>
+    <td>{{.Position}}</td>
+    <td>
+      <strong>{{.Name}}</strong>
+      {{with .Meta}}<div class="meta">{{.}}</div>{{end}}
+      {{if .Instructions}}<ol class="instructions">{{range .Instructions}}<li>{{.}}</li>{{end}}</ol>{{end}}
+    </td>
+    <td><ul>{{range .Sets}}<li>{{.}}</li>{{end}}</ul></td>
+    <td class="done"></td>
+  </tr>
+  {{end}}
+  </tbody>
+</table>
+</section>
+{{end}}
+</body>
+</html>
+`))
+
+// renderHTML writes a standalone page laid out for printing.
+func renderHTML(w io.Writer, program *models.CompleteProgram) error {
+	return htmlTemplate.Execute(w, struct {
+		Title string
+		Days  []day
+	}{title(program), layout(program)})
+}
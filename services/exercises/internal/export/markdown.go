@@ -0,0 +1,36 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/models"
+)
+
+// renderMarkdown writes the program for pasting into a chat or a note.
+func renderMarkdown(w io.Writer, program *models.CompleteProgram) error {
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, "# %s\n", title(program))
+
+	for _, d := range layout(program) {
+		fmt.Fprintf(out, "\n## Day %d\n", d.Number)
+		for _, it := range d.Items {
+			fmt.Fprintf(out, "\n### %d. %s\n\n", it.Position, it.Name)
+			if meta := it.Meta(); meta != "" {
+				fmt.Fprintf(out, "_%s_\n\n", meta)
+			}
+			for _, line := range it.Sets {
+				fmt.Fprintf(out, "- %s\n", line)
+			}
+			if len(it.Instructions) > 0 {
+				fmt.Fprintln(out)
+				for n, step := range it.Instructions {
+					fmt.Fprintf(out, "%d. %s\n", n+1, step)
+				}
+			}
+		}
+	}
+
+	return out.Flush()
+}
@@ -0,0 +1,69 @@
+package export
+
+import (
+	"io"
+	"strconv"
+
+	"github.com/go-pdf/fpdf"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/models"
+)
+
+const (
+	pdfMargin     = 15.0
+	pdfLineHeight = 5.0
+)
+
+// renderPDF writes an A4 document with one section per day. The core fonts
+// only cover cp1252, text is translated to it.
+func renderPDF(w io.Writer, program *models.CompleteProgram) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(true, pdfMargin)
+	pdf.SetTitle(title(program), true)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-pdfMargin + 5)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.CellFormat(0, pdfLineHeight, "Page "+strconv.Itoa(pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 10, tr(title(program)), "", 1, "L", false, 0, "")
+
+	for _, d := range layout(program) {
+		pdf.Ln(3)
+		pdf.SetFont("Helvetica", "B", 13)
+		pdf.CellFormat(0, 8, "Day "+strconv.Itoa(d.Number), "B", 1, "L", false, 0, "")
+		pdf.Ln(2)
+
+		for _, it := range d.Items {
+			pdf.SetFont("Helvetica", "B", 11)
+			pdf.MultiCell(0, 6, tr(strconv.Itoa(it.Position)+". "+it.Name), "", "L", false)
+
+			if meta := it.Meta(); meta != "" {
+				pdf.SetFont("Helvetica", "I", 9)
+				pdf.SetTextColor(90, 90, 90)
+				pdf.MultiCell(0, pdfLineHeight, tr(meta), "", "L", false)
+				pdf.SetTextColor(0, 0, 0)
+			}
+
+			pdf.SetFont("Helvetica", "", 10)
+			for _, line := range it.Sets {
+				pdf.MultiCell(0, pdfLineHeight, tr("•  "+line), "", "L", false)
+			}
+
+			if len(it.Instructions) > 0 {
+				pdf.SetFont("Helvetica", "", 8)
+				for n, step := range it.Instructions {
+					pdf.MultiCell(0, 4, tr(strconv.Itoa(n+1)+". "+step), "", "L", false)
+				}
+			}
+			pdf.Ln(3)
+		}
+	}
+
+	return pdf.Output(w)
+}
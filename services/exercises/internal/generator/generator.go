@@ -0,0 +1,352 @@
+package generator
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"time"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/estimate"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/models"
+)
+
+// Goal decides which categories of exercises are picked and how they are
+// prescribed.
+type Goal string
+
+const (
+	Strength    Goal = "strength"
+	Hypertrophy Goal = "hypertrophy"
+	Endurance   Goal = "endurance"
+	Mobility    Goal = "mobility"
+)
+
+const (
+	defaultDaysPerWeek    = 3
+	defaultSessionMinutes = 60
+	minSessionMinutes     = 15
+	maxSessionMinutes     = 240
+	maxItemsPerDay        = 10
+)
+
+// Options are the validated inputs of a generation.
+type Options struct {
+	Goal           Goal
+	DaysPerWeek    int
+	SessionMinutes int
+	Equipment      map[db.EquipmentT]bool
+	Level          db.LevelT
+	Seed           int64
+}
+
+// NewOptions validates a request and fills in the defaults: 3 days of 60
+// minutes for a beginner, and a seed from the clock.
+func NewOptions(req models.GenerateProgramRequest) (Options, error) {
+	opts := Options{
+		Goal:           Goal(req.Goal),
+		DaysPerWeek:    req.DaysPerWeek,
+		SessionMinutes: req.SessionMinutes,
+		Equipment:      make(map[db.EquipmentT]bool, len(req.Equipment)),
+		Level:          db.LevelT(req.Level),
+	}
+
+	if _, ok := schemes[opts.Goal]; !ok {
+		return opts, fmt.Errorf("unknown goal %q, expected strength, hypertrophy, endurance or mobility", req.Goal)
+	}
+	if opts.DaysPerWeek == 0 {
+		opts.DaysPerWeek = defaultDaysPerWeek
+	}
+	if opts.DaysPerWeek < 1 || opts.DaysPerWeek > 7 {
+		return opts, fmt.Errorf("daysPerWeek must be between 1 and 7")
+	}
+	if opts.SessionMinutes == 0 {
+		opts.SessionMinutes = defaultSessionMinutes
+	}
+	if opts.SessionMinutes < minSessionMinutes || opts.SessionMinutes > maxSessionMinutes {
+		return opts, fmt.Errorf("sessionMinutes must be between %d and %d", minSessionMinutes, maxSessionMinutes)
+	}
+	if opts.Level == "" {
+		opts.Level = db.LevelTBeginner
+	}
+	if _, ok := levelRank[opts.Level]; !ok {
+		return opts, fmt.Errorf("unknown level %q, expected beginner, intermediate or expert", req.Level)
+	}
+	for _, eq := range req.Equipment {
+		opts.Equipment[eq] = true
+	}
+
+	opts.Seed = time.Now().UnixNano()
+	if req.Seed != nil {
+		opts.Seed = *req.Seed
+	}
+	return opts, nil
+}
+
+// Candidate is a catalog exercise the generator can pick.
+type Candidate struct {
+	ID             int32
+	Equipment      db.EquipmentT
+	Category       db.CategoryT
+	Level          db.LevelT
+	Compound       bool
+	PrimaryMuscles []string
+}
+
+// CandidatesFromRows keeps the exercises with at least one primary muscle.
+// Exercises without a known equipment or level count as "Other" and
+// "beginner", as the import script does.
+func CandidatesFromRows(rows []db.GetGeneratorCandidatesRow) []Candidate {
+	candidates := make([]Candidate, 0, len(rows))
+	for _, row := range rows {
+		if len(row.PrimaryMuscles) == 0 {
+			continue
+		}
+		c := Candidate{
+			ID:             row.ID,
+			Equipment:      db.EquipmentT("Other"),
+			Category:       row.Category.CategoryT,
+			Level:          db.LevelTBeginner,
+			Compound:       row.Mechanic.Valid && row.Mechanic.MechanicT == db.MechanicTCompound,
+			PrimaryMuscles: row.PrimaryMuscles,
+		}
+		if row.Equipment.Valid {
+			c.Equipment = row.Equipment.EquipmentT
+		}
+		if row.Level.Valid {
+			c.Level = row.Level.LevelT
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates
+}
+
+var levelRank = map[db.LevelT]int{
+	db.LevelTBeginner:     0,
+	db.LevelTIntermediate: 1,
+	db.LevelTExpert:       2,
+}
+
+// scheme is how a goal loads the exercises it picks.
+type scheme struct {
+	categories    []db.CategoryT
+	sets          int
+	reps          int
+	holdSeconds   int // used instead of reps for stretches and cardio
+	restSeconds   int
+	rpe           float64
+	compoundFirst bool
+}
+
+var schemes = map[Goal]scheme{
+	Strength: {
+		categories:    []db.CategoryT{db.CategoryTStrength, db.CategoryTPowerlifting, db.CategoryTOlympicweightlifting},
+		sets:          5,
+		reps:          5,
+		restSeconds:   180,
+		rpe:           8,
+		compoundFirst: true,
+	},
+	Hypertrophy: {
+		categories:    []db.CategoryT{db.CategoryTStrength},
+		sets:          3,
+		reps:          10,
+		restSeconds:   90,
+		rpe:           8,
+		compoundFirst: true,
+	},
+	Endurance: {
+		categories:  []db.CategoryT{db.CategoryTStrength, db.CategoryTPlyometrics, db.CategoryTCardio},
+		sets:        3,
+		reps:        15,
+		holdSeconds: 600,
+		restSeconds: 45,
+	},
+	Mobility: {
+		categories:  []db.CategoryT{db.CategoryTStretching},
+		sets:        2,
+		holdSeconds: 30,
+		restSeconds: 15,
+	},
+}
+
+// Muscle priorities of each kind of day, the first muscles get the compound
+// lifts and the most time.
+var (
+	fullBody = []string{"quadriceps", "chest", "lats", "hamstrings", "shoulders", "middle back", "glutes", "abdominals", "triceps", "biceps", "calves"}
+	upper    = []string{"chest", "lats", "shoulders", "middle back", "triceps", "biceps", "traps", "abdominals"}
+	lower    = []string{"quadriceps", "hamstrings", "glutes", "calves", "lower back", "adductors", "abductors", "abdominals"}
+	push     = []string{"chest", "shoulders", "triceps", "abdominals"}
+	pull     = []string{"lats", "middle back", "biceps", "traps", "lower back", "forearms"}
+	legs     = []string{"quadriceps", "hamstrings", "glutes", "calves", "adductors", "abductors"}
+	stretch  = []string{"hamstrings", "quadriceps", "glutes", "lower back", "chest", "shoulders", "lats", "calves", "adductors", "abductors", "neck"}
+)
+
+// split lays out the muscle priorities of every day: full body up to three
+// days, upper/lower on four and push/pull/legs past that.
+func split(goal Goal, days int) [][]string {
+	layout := make([][]string, 0, days)
+	for day := 0; day < days; day++ {
+		switch {
+		case goal == Mobility:
+			layout = append(layout, stretch)
+		case goal == Endurance || days <= 3:
+			layout = append(layout, fullBody)
+		case days == 4:
+			layout = append(layout, [][]string{upper, lower}[day%2])
+		default:
+			layout = append(layout, [][]string{push, pull, legs}[day%3])
+		}
+	}
+	return layout
+}
+
+// Generate builds a program from the catalog. Every day covers its muscles
+// in order of priority, one exercise per muscle, until the session is full;
+// exercises aren't repeated across days while others are left. The result
+// only depends on the options and the catalog.
+func Generate(opts Options, catalog []Candidate) ([]models.ProgramRecord, error) {
+	s := schemes[opts.Goal]
+	pool := make([]Candidate, 0, len(catalog))
+	for _, c := range catalog {
+		if available(opts, s, c) {
+			pool = append(pool, c)
+		}
+	}
+	if len(pool) == 0 {
+		return nil, fmt.Errorf("no exercise in the catalog matches the goal, level and equipment")
+	}
+
+	rng := rand.New(rand.NewSource(opts.Seed))
+	used := make(map[int32]bool)
+	budget := opts.SessionMinutes * 60
+
+	records := make([]models.ProgramRecord, 0)
+	for day, muscles := range split(opts.Goal, opts.DaysPerWeek) {
+		today := make(map[int32]bool)
+		remaining := budget
+		items := 0
+
+		// Each pass walks the muscles once, later passes add volume to
+		// the muscles that still have candidates.
+		for pass := 0; items < maxItemsPerDay; pass++ {
+			covered := make(map[string]bool)
+			added := false
+			for _, muscle := range muscles {
+				if items == maxItemsPerDay || covered[muscle] {
+					continue
+				}
+				c, ok := pick(rng, pool, muscle, today, used, s.compoundFirst && pass == 0)
+				if !ok {
+					continue
+				}
+				sets := s.prescribe(c)
+				cost := estimate.Item(sets)
+				if cost > remaining && items > 0 {
+					continue
+				}
+
+				today[c.ID], used[c.ID] = true, true
+				for _, m := range c.PrimaryMuscles {
+					covered[m] = true
+				}
+				remaining -= cost
+				items++
+				added = true
+				records = append(records, models.ProgramRecord{
+					ExerciseId:    int(c.ID),
+					Idx:           len(records) + 1,
+					Day:           day + 1,
+					Prescriptions: sets,
+				})
+			}
+			if !added {
+				break
+			}
+		}
+	}
+	return records, nil
+}
+
+// available reports whether the exercise fits the goal, the level and the
+// equipment at hand. Stretches filed under "Other" need no equipment.
+func available(opts Options, s scheme, c Candidate) bool {
+	if !slices.Contains(s.categories, c.Category) {
+		return false
+	}
+	if levelRank[c.Level] > levelRank[opts.Level] {
+		return false
+	}
+	switch {
+	case c.Equipment == db.EquipmentT("Bodyweight"):
+		return true
+	case c.Category == db.CategoryTStretching && c.Equipment == db.EquipmentT("Other"):
+		return true
+	default:
+		return opts.Equipment[c.Equipment]
+	}
+}
+
+// pick draws an exercise training muscle, preferring exercises not used on
+// any day yet and, when asked, compound ones.
+func pick(rng *rand.Rand, pool []Candidate, muscle string, today, used map[int32]bool, compound bool) (Candidate, bool) {
+	var fresh, repeat []Candidate
+	for _, c := range pool {
+		if today[c.ID] || !slices.Contains(c.PrimaryMuscles, muscle) {
+			continue
+		}
+		if used[c.ID] {
+			repeat = append(repeat, c)
+		} else {
+			fresh = append(fresh, c)
+		}
+	}
+
+	for _, group := range [][]Candidate{fresh, repeat} {
+		if len(group) == 0 {
+			continue
+		}
+		if compound {
+			var compounds []Candidate
+			for _, c := range group {
+				if c.Compound {
+					compounds = append(compounds, c)
+				}
+			}
+			if len(compounds) > 0 {
+				group = compounds
+			}
+		}
+		return group[rng.Intn(len(group))], true
+	}
+	return Candidate{}, false
+}
+
+// prescribe lays out the sets of an exercise for the scheme. Stretches are
+// held and cardio is done for time, everything else is done for reps.
+func (s scheme) prescribe(c Candidate) []models.SetPrescription {
+	set := models.SetPrescription{RestSeconds: intPtr(s.restSeconds)}
+	sets := s.sets
+	switch c.Category {
+	case db.CategoryTStretching:
+		set.DurationSeconds = intPtr(s.holdSeconds)
+	case db.CategoryTCardio:
+		set.DurationSeconds = intPtr(s.holdSeconds)
+		set.RestSeconds = nil
+		sets = 1
+	default:
+		set.Reps = intPtr(s.reps)
+		if s.rpe > 0 {
+			rpe := s.rpe
+			set.RPE = &rpe
+		}
+	}
+
+	prescriptions := make([]models.SetPrescription, sets)
+	for i := range prescriptions {
+		prescriptions[i] = set
+	}
+	return prescriptions
+}
+
+func intPtr(i int) *int { return &i }
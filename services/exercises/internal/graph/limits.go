@@ -0,0 +1,163 @@
+package graph
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+const (
+	// MaxDepth is the deepest nesting of fields a query may select, e.g.
+	// program { items { exercise { muscles { name } } } } is 5 deep.
+	MaxDepth = 8
+	// MaxComplexity bounds the number of fields a query may resolve. Every
+	// field costs 1, the fields below a list count once per item.
+	MaxComplexity = 5000
+	// assumedListSize is the number of items counted for lists without a
+	// limit argument, such as the items of a program.
+	assumedListSize = 10
+)
+
+// limiter measures the depth and complexity of an operation before it runs.
+type limiter struct {
+	schema    graphql.Schema
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+}
+
+// checkLimits rejects operations deeper than MaxDepth or more complex than
+// MaxComplexity. The document must have passed validation, which rules out
+// unknown fields and fragment cycles.
+func checkLimits(schema graphql.Schema, doc *ast.Document, operationName string, variables map[string]interface{}) error {
+	l := limiter{schema: schema, fragments: make(map[string]*ast.FragmentDefinition), variables: variables}
+
+	var operation *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.FragmentDefinition:
+			l.fragments[def.Name.Value] = def
+		case *ast.OperationDefinition:
+			if operationName == "" || (def.Name != nil && def.Name.Value == operationName) {
+				operation = def
+			}
+		}
+	}
+	if operation == nil {
+		return fmt.Errorf("unknown operation %q", operationName)
+	}
+
+	root := schema.QueryType()
+	if operation.Operation == ast.OperationTypeMutation {
+		root = schema.MutationType()
+	}
+	complexity, err := l.measure(operation.SelectionSet, root, 1)
+	if err != nil {
+		return err
+	}
+	if complexity > MaxComplexity {
+		return fmt.Errorf("query complexity %d exceeds the limit of %d", complexity, MaxComplexity)
+	}
+	return nil
+}
+
+// measure returns the complexity of the selections on parent, found at the
+// given depth.
+func (l *limiter) measure(set *ast.SelectionSet, parent graphql.Type, depth int) (int, error) {
+	if set == nil {
+		return 0, nil
+	}
+
+	complexity := 0
+	for _, selection := range set.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			name := selection.Name.Value
+			// Introspection is bounded by the schema itself.
+			if strings.HasPrefix(name, "__") {
+				continue
+			}
+			if depth > MaxDepth {
+				return 0, fmt.Errorf("query depth exceeds the limit of %d", MaxDepth)
+			}
+			object, ok := parent.(*graphql.Object)
+			if !ok {
+				continue
+			}
+			def, ok := object.Fields()[name]
+			if !ok {
+				continue
+			}
+			children, err := l.measure(selection.SelectionSet, graphql.GetNamed(def.Type).(graphql.Type), depth+1)
+			if err != nil {
+				return 0, err
+			}
+			if isList(def.Type) {
+				children *= l.listSize(selection, def)
+			}
+			complexity += 1 + children
+
+		case *ast.InlineFragment:
+			on := parent
+			if selection.TypeCondition != nil {
+				on = l.schema.Type(selection.TypeCondition.Name.Value)
+			}
+			children, err := l.measure(selection.SelectionSet, on, depth)
+			if err != nil {
+				return 0, err
+			}
+			complexity += children
+
+		case *ast.FragmentSpread:
+			fragment, ok := l.fragments[selection.Name.Value]
+			if !ok {
+				continue
+			}
+			children, err := l.measure(fragment.SelectionSet, l.schema.Type(fragment.TypeCondition.Name.Value), depth)
+			if err != nil {
+				return 0, err
+			}
+			complexity += children
+		}
+	}
+	return complexity, nil
+}
+
+// listSize is the number of items a list field is counted for: its limit
+// argument, given inline or as a variable, or the default of the argument.
+func (l *limiter) listSize(field *ast.Field, def *graphql.FieldDefinition) int {
+	for _, arg := range field.Arguments {
+		if arg.Name.Value != "limit" {
+			continue
+		}
+		switch value := arg.Value.(type) {
+		case *ast.IntValue:
+			if n, err := strconv.Atoi(value.Value); err == nil {
+				return clampLimit(n)
+			}
+		case *ast.Variable:
+			switch n := l.variables[value.Name.Value].(type) {
+			case int:
+				return clampLimit(n)
+			case float64:
+				return clampLimit(int(n))
+			}
+		}
+	}
+	for _, arg := range def.Args {
+		if n, ok := arg.DefaultValue.(int); ok && arg.Name() == "limit" {
+			return n
+		}
+	}
+	return assumedListSize
+}
+
+func isList(t graphql.Type) bool {
+	if nonNull, ok := t.(*graphql.NonNull); ok {
+		t = nonNull.OfType
+	}
+	_, ok := t.(*graphql.List)
+	return ok
+}
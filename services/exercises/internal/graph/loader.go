@@ -0,0 +1,156 @@
+package graph
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/auth"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/models"
+)
+
+// Loader collects the keys asked for while the resolvers of one level of a
+// query run and fetches all of them with a single query once the first
+// value is needed, in the manner of a dataloader. Values are cached for the
+// rest of the request.
+type Loader[K comparable, V any] struct {
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	queued  map[K]bool
+	fetched map[K]bool
+	pending []K
+	values  map[K]V
+	errs    map[K]error
+}
+
+func NewLoader[K comparable, V any](fetch func(ctx context.Context, keys []K) (map[K]V, error)) *Loader[K, V] {
+	return &Loader[K, V]{
+		fetch:   fetch,
+		queued:  make(map[K]bool),
+		fetched: make(map[K]bool),
+		values:  make(map[K]V),
+		errs:    make(map[K]error),
+	}
+}
+
+// Load queues the key and returns a thunk for its value. Calling the thunk
+// fetches every key queued so far, unless its own key was fetched already:
+// the keys queued by the resolvers of the next level wait for their own
+// thunks. Keys the fetch didn't return resolve to false, e.g. exercises the
+// viewer can't see.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) func() (V, bool, error) {
+	l.mu.Lock()
+	if !l.queued[key] {
+		l.queued[key] = true
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (V, bool, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if !l.fetched[key] {
+			l.flush(ctx)
+		}
+		if err := l.errs[key]; err != nil {
+			var zero V
+			return zero, false, err
+		}
+		value, ok := l.values[key]
+		return value, ok, nil
+	}
+}
+
+// flush fetches the pending keys, l.mu must be held.
+func (l *Loader[K, V]) flush(ctx context.Context) {
+	if len(l.pending) == 0 {
+		return
+	}
+	keys := l.pending
+	l.pending = nil
+
+	values, err := l.fetch(ctx, keys)
+	for _, key := range keys {
+		l.fetched[key] = true
+		if err != nil {
+			l.errs[key] = err
+		} else if value, ok := values[key]; ok {
+			l.values[key] = value
+		}
+	}
+}
+
+// loaders are the loaders of one request, they cache what the viewer of
+// the request may see.
+type loaders struct {
+	exercises *Loader[int32, db.GetExercisesByIdsRow]
+	muscles   *Loader[int32, []Muscle]
+	sets      *Loader[uuid.UUID, []models.WorkoutSet]
+}
+
+type loadersKey struct{}
+
+func withLoaders(ctx context.Context) context.Context {
+	return context.WithValue(ctx, loadersKey{}, &loaders{
+		exercises: NewLoader(fetchExercises),
+		muscles:   NewLoader(fetchMuscles),
+		sets:      NewLoader(fetchWorkoutSets),
+	})
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+// viewerID is the authenticated user of the request, custom exercises are
+// only visible to them and their clients.
+func viewerID(ctx context.Context) pgtype.Int8 {
+	userID, ok := auth.UserID(ctx)
+	return pgtype.Int8{Int64: userID, Valid: ok}
+}
+
+func fetchExercises(ctx context.Context, ids []int32) (map[int32]db.GetExercisesByIdsRow, error) {
+	rows, err := db.Queriez.GetExercisesByIds(ctx, db.GetExercisesByIdsParams{ExerciseIds: ids, ViewerID: viewerID(ctx)})
+	if err != nil {
+		return nil, err
+	}
+	exercises := make(map[int32]db.GetExercisesByIdsRow, len(rows))
+	for _, row := range rows {
+		exercises[row.ID] = row
+	}
+	return exercises, nil
+}
+
+func fetchMuscles(ctx context.Context, exerciseIDs []int32) (map[int32][]Muscle, error) {
+	rows, err := db.Queriez.GetMusclesByExerciseIds(ctx, exerciseIDs)
+	if err != nil {
+		return nil, err
+	}
+	muscles := make(map[int32][]Muscle, len(exerciseIDs))
+	for _, row := range rows {
+		primary := row.IsPrimary
+		muscles[row.ExerciseID] = append(muscles[row.ExerciseID], Muscle{ID: row.ID, Name: row.Name.String, Primary: &primary})
+	}
+	return muscles, nil
+}
+
+func fetchWorkoutSets(ctx context.Context, workoutIDs []uuid.UUID) (map[uuid.UUID][]models.WorkoutSet, error) {
+	ids := make([]pgtype.UUID, 0, len(workoutIDs))
+	for _, id := range workoutIDs {
+		ids = append(ids, pgtype.UUID{Bytes: id, Valid: true})
+	}
+	rows, err := db.Queriez.GetWorkoutSetsByWorkoutIds(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	sets := make(map[uuid.UUID][]models.WorkoutSet, len(workoutIDs))
+	for _, row := range rows {
+		workoutID := uuid.UUID(row.WorkoutID.Bytes)
+		sets[workoutID] = append(sets[workoutID], models.WorkoutSetFromRow(row))
+	}
+	return sets, nil
+}
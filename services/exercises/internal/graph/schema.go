@@ -0,0 +1,691 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/auth"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/estimate"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/models"
+)
+
+const (
+	defaultLimit = 50
+	maxLimit     = 100
+
+	// favoritesCollection names the favorites of the user in the collection
+	// argument of the exercises query.
+	favoritesCollection = "favorites"
+)
+
+var (
+	errInternal      = errors.New("internal server error")
+	errUnauthorized  = errors.New("authentication required")
+	errNoCollection  = errors.New("collection not found")
+	errBadCollection = errors.New("invalid collection, expected favorites or a collection UUID")
+)
+
+// Config holds what the schema needs from the HTTP handlers.
+type Config struct {
+	// ReadableProgram checks the caller of the context can read the
+	// program, optionally through a share token. It returns pgx.ErrNoRows
+	// for programs that are missing or can't be read.
+	ReadableProgram func(ctx context.Context, programID pgtype.UUID, share string) (db.ProgramAccess, error)
+}
+
+// Request is a GraphQL request as sent over HTTP.
+type Request struct {
+	Query         string                 `json:"query" example:"{ exercises(muscle: \"Chest\", limit: 10) { id names muscles { name primary } } }"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+	OperationName string                 `json:"operationName,omitempty"`
+}
+
+// Schema is the executable GraphQL schema of the exercises service.
+type Schema struct {
+	schema graphql.Schema
+}
+
+// Muscle is a muscle, Primary is only set for the muscles of an exercise.
+type Muscle struct {
+	ID      int32  `json:"id"`
+	Name    string `json:"name"`
+	Primary *bool  `json:"primary"`
+}
+
+// program is the source of the Program type.
+type program struct {
+	ID    uuid.UUID
+	Items []models.ProgramRecord
+}
+
+func NewSchema(cfg Config) (*Schema, error) {
+	t := newTypes()
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"exercises": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(t.exercise))),
+				Description: "Exercises matching every given filter, the same filters as GET /api/exercises.",
+				Args: graphql.FieldConfigArgument{
+					"id":         &graphql.ArgumentConfig{Type: graphql.Int},
+					"name":       &graphql.ArgumentConfig{Type: graphql.String, Description: "Part of any name of the exercise"},
+					"equipment":  &graphql.ArgumentConfig{Type: t.equipment},
+					"muscle":     &graphql.ArgumentConfig{Type: graphql.String},
+					"collection": &graphql.ArgumentConfig{Type: graphql.String, Description: "favorites or the UUID of a collection of the user"},
+					"limit":      &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultLimit},
+					"offset":     &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0},
+				},
+				Resolve: resolveExercises,
+			},
+			"exercise": &graphql.Field{
+				Type: t.exercise,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return loadExercise(p.Context, int32(p.Args["id"].(int))), nil
+				},
+			},
+			"muscles": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(t.muscle))),
+				Resolve: resolveMuscles,
+			},
+			"equipment": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(t.equipment))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return equipment, nil
+				},
+			},
+			"program": &graphql.Field{
+				Type: t.program,
+				Args: graphql.FieldConfigArgument{
+					"id":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"share": &graphql.ArgumentConfig{Type: graphql.String, Description: "Share token of a link-only program"},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return resolveProgram(p, cfg)
+				},
+			},
+			"workouts": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(t.workout))),
+				Description: "Workouts of the authenticated user, most recent first.",
+				Args: graphql.FieldConfigArgument{
+					"limit":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultLimit},
+					"offset": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0},
+				},
+				Resolve: resolveWorkouts,
+			},
+		},
+	})
+
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{Query: query})
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{schema: schema}, nil
+}
+
+// Do parses, validates and runs a request. Operations over the depth or
+// complexity limits are rejected before anything is resolved.
+func (s *Schema) Do(ctx context.Context, req Request) *graphql.Result {
+	doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{
+		Body: []byte(req.Query),
+		Name: "GraphQL request",
+	})})
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+
+	validation := graphql.ValidateDocument(&s.schema, doc, nil)
+	if !validation.IsValid {
+		return &graphql.Result{Errors: validation.Errors}
+	}
+	if err := checkLimits(s.schema, doc, req.OperationName, req.Variables); err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        s.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       withLoaders(ctx),
+	})
+}
+
+// types are the object types of the schema, which refer to each other.
+type types struct {
+	equipment    *graphql.Enum
+	muscle       *graphql.Object
+	exercise     *graphql.Object
+	prescription *graphql.Object
+	duration     *graphql.Object
+	programItem  *graphql.Object
+	program      *graphql.Object
+	workoutSet   *graphql.Object
+	workout      *graphql.Object
+}
+
+// equipment lists the values of equipment_t in their database order.
+var equipment = []db.EquipmentT{
+	db.EquipmentTDumbbells,
+	db.EquipmentTBarbell,
+	db.EquipmentTMachine,
+	db.EquipmentTBodyweight,
+	db.EquipmentTMedicineBall,
+	db.EquipmentTKettlebells,
+	db.EquipmentTStreches,
+	db.EquipmentTCables,
+	db.EquipmentTBand,
+	db.EquipmentTPlate,
+	db.EquipmentTTRX,
+	db.EquipmentTBosuBall,
+	db.EquipmentTFoamroll,
+	db.EquipmentTExerciseBall,
+	db.EquipmentTOther,
+}
+
+// dateTime serializes times as RFC 3339 strings.
+var dateTime = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "DateTime",
+	Description: "An RFC 3339 timestamp",
+	Serialize: func(value interface{}) interface{} {
+		switch value := value.(type) {
+		case time.Time:
+			return value.Format(time.RFC3339)
+		case *time.Time:
+			if value == nil {
+				return nil
+			}
+			return value.Format(time.RFC3339)
+		}
+		return nil
+	},
+})
+
+func newTypes() *types {
+	t := &types{}
+
+	values := graphql.EnumValueConfigMap{}
+	for _, e := range equipment {
+		name := strings.ToUpper(strings.NewReplacer(" ", "_").Replace(string(e)))
+		values[name] = &graphql.EnumValueConfig{Value: e, Description: string(e)}
+	}
+	t.equipment = graphql.NewEnum(graphql.EnumConfig{Name: "Equipment", Values: values})
+
+	t.muscle = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Muscle",
+		Fields: graphql.Fields{
+			"id":      &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"name":    &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"primary": &graphql.Field{Type: graphql.Boolean, Description: "Whether the exercise mainly targets the muscle, only set on the muscles of an exercise"},
+		},
+	})
+
+	t.exercise = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Exercise",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Int),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source, nil
+				},
+			},
+			"names": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String))),
+				Resolve: exerciseField(func(row db.GetExercisesByIdsRow) interface{} {
+					return row.Names
+				}),
+			},
+			"equipment": &graphql.Field{
+				Type: t.equipment,
+				Resolve: exerciseField(func(row db.GetExercisesByIdsRow) interface{} {
+					if !row.Equipment.Valid {
+						return nil
+					}
+					return row.Equipment.EquipmentT
+				}),
+			},
+			"category": &graphql.Field{
+				Type: graphql.String,
+				Resolve: exerciseField(func(row db.GetExercisesByIdsRow) interface{} {
+					return nullable(string(row.Category.CategoryT), row.Category.Valid)
+				}),
+			},
+			"level": &graphql.Field{
+				Type: graphql.String,
+				Resolve: exerciseField(func(row db.GetExercisesByIdsRow) interface{} {
+					return nullable(string(row.Level.LevelT), row.Level.Valid)
+				}),
+			},
+			"mechanic": &graphql.Field{
+				Type: graphql.String,
+				Resolve: exerciseField(func(row db.GetExercisesByIdsRow) interface{} {
+					return nullable(string(row.Mechanic.MechanicT), row.Mechanic.Valid)
+				}),
+			},
+			"force": &graphql.Field{
+				Type: graphql.String,
+				Resolve: exerciseField(func(row db.GetExercisesByIdsRow) interface{} {
+					return nullable(string(row.Force.ForceT), row.Force.Valid)
+				}),
+			},
+			"instructions": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String))),
+				Resolve: exerciseField(func(row db.GetExercisesByIdsRow) interface{} {
+					return row.Instructions
+				}),
+			},
+			"visuals": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String))),
+				Resolve: exerciseField(func(row db.GetExercisesByIdsRow) interface{} {
+					visuals := make([]string, 0)
+					for _, path := range strings.Split(row.Visuals.String, ",") {
+						if path = strings.TrimSpace(path); path != "" {
+							visuals = append(visuals, path)
+						}
+					}
+					return visuals
+				}),
+			},
+			"custom": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.Boolean),
+				Description: "Whether the exercise is a custom exercise of a user rather than part of the catalog",
+				Resolve: exerciseField(func(row db.GetExercisesByIdsRow) interface{} {
+					return row.OwnerID.Valid
+				}),
+			},
+			"muscles": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(t.muscle))),
+				Args: graphql.FieldConfigArgument{
+					"primary": &graphql.ArgumentConfig{Type: graphql.Boolean, Description: "Only the primary, or only the secondary, muscles"},
+				},
+				Resolve: resolveExerciseMuscles,
+			},
+		},
+	})
+
+	t.prescription = graphql.NewObject(graphql.ObjectConfig{
+		Name: "SetPrescription",
+		Fields: graphql.Fields{
+			"reps":            &graphql.Field{Type: graphql.Int},
+			"weightKg":        &graphql.Field{Type: graphql.Float},
+			"oneRmPercent":    &graphql.Field{Type: graphql.Float},
+			"rpe":             &graphql.Field{Type: graphql.Float},
+			"rir":             &graphql.Field{Type: graphql.Int},
+			"tempo":           &graphql.Field{Type: graphql.String},
+			"restSeconds":     &graphql.Field{Type: graphql.Int},
+			"durationSeconds": &graphql.Field{Type: graphql.Int},
+			"distanceMeters":  &graphql.Field{Type: graphql.Float},
+		},
+	})
+
+	session := graphql.NewObject(graphql.ObjectConfig{
+		Name: "SessionDuration",
+		Fields: graphql.Fields{
+			"day":     &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"seconds": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"minutes": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+	t.duration = graphql.NewObject(graphql.ObjectConfig{
+		Name: "ProgramDuration",
+		Fields: graphql.Fields{
+			"sessions":              &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(session)))},
+			"totalSeconds":          &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"totalMinutes":          &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"longestSessionMinutes": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"averageSessionMinutes": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+
+	t.programItem = graphql.NewObject(graphql.ObjectConfig{
+		Name: "ProgramItem",
+		Fields: graphql.Fields{
+			"idx":        &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"day":        &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"sets":       &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"reps":       &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"exerciseId": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"exercise": &graphql.Field{
+				Type:        t.exercise,
+				Description: "The exercise of the item, null if it is a custom exercise the viewer can't see",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return loadExercise(p.Context, int32(p.Source.(models.ProgramRecord).ExerciseId)), nil
+				},
+			},
+			"prescriptions": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(t.prescription))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if prescriptions := p.Source.(models.ProgramRecord).Prescriptions; prescriptions != nil {
+						return prescriptions, nil
+					}
+					return []models.SetPrescription{}, nil
+				},
+			},
+		},
+	})
+
+	t.program = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Program",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.ID),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*program).ID.String(), nil
+				},
+			},
+			"days": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.Int))),
+				Description: "The days of the program, in order",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					days := make([]int, 0)
+					seen := make(map[int]bool)
+					for _, item := range p.Source.(*program).Items {
+						if !seen[item.Day] {
+							seen[item.Day] = true
+							days = append(days, item.Day)
+						}
+					}
+					return days, nil
+				},
+			},
+			"items": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(t.programItem))),
+				Args: graphql.FieldConfigArgument{
+					"day": &graphql.ArgumentConfig{Type: graphql.Int, Description: "Only the items of this day"},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					prog := p.Source.(*program)
+					if day, ok := p.Args["day"].(int); ok {
+						return (&models.Program{UUID: prog.ID, Exercises: prog.Items}).ProgramDay(day), nil
+					}
+					return prog.Items, nil
+				},
+			},
+			"duration": &graphql.Field{
+				Type:        graphql.NewNonNull(t.duration),
+				Description: "The estimated length of the sessions of the program",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return estimate.Program(p.Source.(*program).Items), nil
+				},
+			},
+		},
+	})
+
+	t.workoutSet = graphql.NewObject(graphql.ObjectConfig{
+		Name: "WorkoutSet",
+		Fields: graphql.Fields{
+			"id":         &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"programIdx": &graphql.Field{Type: graphql.Int},
+			"exerciseId": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"exercise": &graphql.Field{
+				Type: t.exercise,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return loadExercise(p.Context, int32(p.Source.(models.WorkoutSet).ExerciseId)), nil
+				},
+			},
+			"setNumber":       &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"reps":            &graphql.Field{Type: graphql.Int},
+			"weightKg":        &graphql.Field{Type: graphql.Float},
+			"rpe":             &graphql.Field{Type: graphql.Float},
+			"durationSeconds": &graphql.Field{Type: graphql.Int},
+			"distanceMeters":  &graphql.Field{Type: graphql.Float},
+			"notes":           &graphql.Field{Type: graphql.String},
+			"performedAt":     &graphql.Field{Type: graphql.NewNonNull(dateTime)},
+		},
+	})
+
+	t.workout = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Workout",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.ID),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(models.WorkoutSummary).ID.String(), nil
+				},
+			},
+			"programId": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.ID),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(models.WorkoutSummary).ProgramID.String(), nil
+				},
+			},
+			"day":        &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"startedAt":  &graphql.Field{Type: graphql.NewNonNull(dateTime)},
+			"finishedAt": &graphql.Field{Type: dateTime},
+			"notes":      &graphql.Field{Type: graphql.String},
+			"setCount":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"sets": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(t.workoutSet))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					load := loadersFrom(p.Context).sets.Load(p.Context, p.Source.(models.WorkoutSummary).ID)
+					return func() (interface{}, error) {
+						sets, _, err := load()
+						if err != nil {
+							log.Printf("Error at GETting workout sets from DB: %v", err)
+							return nil, errInternal
+						}
+						if sets == nil {
+							sets = []models.WorkoutSet{}
+						}
+						return sets, nil
+					}, nil
+				},
+			},
+		},
+	})
+
+	return t
+}
+
+// exerciseField resolves a field of an exercise from its batch loaded row.
+// The source of the Exercise type is the exercise id.
+func exerciseField(get func(row db.GetExercisesByIdsRow) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		load := loadersFrom(p.Context).exercises.Load(p.Context, p.Source.(int32))
+		return func() (interface{}, error) {
+			row, ok, err := load()
+			if err != nil {
+				log.Printf("Error at GETting exercises from DB: %v", err)
+				return nil, errInternal
+			}
+			if !ok {
+				return nil, nil
+			}
+			return get(row), nil
+		}, nil
+	}
+}
+
+// loadExercise resolves an exercise reference to its id, or to null when
+// the viewer can't see the exercise.
+func loadExercise(ctx context.Context, id int32) func() (interface{}, error) {
+	load := loadersFrom(ctx).exercises.Load(ctx, id)
+	return func() (interface{}, error) {
+		_, ok, err := load()
+		if err != nil {
+			log.Printf("Error at GETting exercises from DB: %v", err)
+			return nil, errInternal
+		}
+		if !ok {
+			return nil, nil
+		}
+		return id, nil
+	}
+}
+
+func resolveExerciseMuscles(p graphql.ResolveParams) (interface{}, error) {
+	load := loadersFrom(p.Context).muscles.Load(p.Context, p.Source.(int32))
+	primary, filter := p.Args["primary"].(bool)
+	return func() (interface{}, error) {
+		muscles, _, err := load()
+		if err != nil {
+			log.Printf("Error at GETting exercise muscles from DB: %v", err)
+			return nil, errInternal
+		}
+		selected := make([]Muscle, 0, len(muscles))
+		for _, m := range muscles {
+			if !filter || *m.Primary == primary {
+				selected = append(selected, m)
+			}
+		}
+		return selected, nil
+	}, nil
+}
+
+func resolveExercises(p graphql.ResolveParams) (interface{}, error) {
+	params := db.GetExercisesParams{
+		Limit:    clampLimit(p.Args["limit"].(int)),
+		Offset:   max(p.Args["offset"].(int), 0),
+		ViewerID: viewerID(p.Context),
+	}
+	if id, ok := p.Args["id"].(int); ok {
+		params.ExerciseID = int32(id)
+	}
+	if name, ok := p.Args["name"].(string); ok && name != "" {
+		params.Name = name
+	}
+	if equipment, ok := p.Args["equipment"].(db.EquipmentT); ok {
+		params.Equipment = equipment
+	}
+	if muscle, ok := p.Args["muscle"].(string); ok && muscle != "" {
+		params.Muscle = muscle
+	}
+	if collection, ok := p.Args["collection"].(string); ok && collection != "" {
+		if err := applyCollectionFilter(p.Context, collection, &params); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := db.Queriez.GetExercises(p.Context, params)
+	if err != nil {
+		log.Printf("Couldn't Fetch exercises from db: %v", err)
+		return nil, errInternal
+	}
+	ids := make([]int32, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+	return ids, nil
+}
+
+// applyCollectionFilter narrows the exercise query down to the favorites or
+// a collection of the user, like the collection parameter of GET
+// /api/exercises.
+func applyCollectionFilter(ctx context.Context, collection string, params *db.GetExercisesParams) error {
+	userID, ok := auth.UserID(ctx)
+	if !ok {
+		return errUnauthorized
+	}
+	if collection == favoritesCollection {
+		params.FavoritesOf = userID
+		return nil
+	}
+
+	var collection_uuid pgtype.UUID
+	if err := collection_uuid.Scan(collection); err != nil {
+		return errBadCollection
+	}
+	_, err := db.Queriez.GetExerciseCollection(ctx, db.GetExerciseCollectionParams{ID: collection_uuid, UserID: userID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return errNoCollection
+	}
+	if err != nil {
+		log.Printf("Error at GETting collection from DB: %v, uuid: %s", err, collection)
+		return errInternal
+	}
+	params.CollectionID = collection_uuid
+	return nil
+}
+
+func resolveMuscles(p graphql.ResolveParams) (interface{}, error) {
+	rows, err := db.Queriez.GetMuscles(p.Context)
+	if err != nil {
+		log.Printf("Couldn't Fetch muscles from db: %v", err)
+		return nil, errInternal
+	}
+	muscles := make([]Muscle, 0, len(rows))
+	for _, row := range rows {
+		muscles = append(muscles, Muscle{ID: row.ID, Name: row.Name.String})
+	}
+	return muscles, nil
+}
+
+// resolveProgram loads a program the caller can read. Programs that are
+// missing or private resolve to null.
+func resolveProgram(p graphql.ResolveParams, cfg Config) (interface{}, error) {
+	var program_uuid pgtype.UUID
+	if err := program_uuid.Scan(p.Args["id"].(string)); err != nil {
+		return nil, errors.New("invalid UUID")
+	}
+	share, _ := p.Args["share"].(string)
+
+	if _, err := cfg.ReadableProgram(p.Context, program_uuid, share); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		log.Printf("Error at GETting the program access from DB: %v, uuid: %s", err, p.Args["id"])
+		return nil, errInternal
+	}
+
+	programRows, err := db.Queriez.GetProgramById(p.Context, program_uuid)
+	if err != nil {
+		log.Printf("Error at GETting the program from DB: %v, uuid: %s", err, p.Args["id"])
+		return nil, errInternal
+	}
+	setRows, err := db.Queriez.GetProgramSetsById(p.Context, program_uuid)
+	if err != nil {
+		log.Printf("Error at GETting the program sets from DB: %v, uuid: %s", err, p.Args["id"])
+		return nil, errInternal
+	}
+
+	prog := models.ProgramFromRows(program_uuid.Bytes, programRows, setRows)
+	return &program{ID: prog.UUID, Items: prog.Exercises}, nil
+}
+
+func resolveWorkouts(p graphql.ResolveParams) (interface{}, error) {
+	userID, ok := auth.UserID(p.Context)
+	if !ok {
+		return nil, errUnauthorized
+	}
+
+	rows, err := db.Queriez.GetWorkoutsByUser(p.Context, db.GetWorkoutsByUserParams{
+		UserID: userID,
+		Limit:  int32(clampLimit(p.Args["limit"].(int))),
+		Offset: int32(max(p.Args["offset"].(int), 0)),
+	})
+	if err != nil {
+		log.Printf("Couldn't Fetch workouts from db: %v", err)
+		return nil, errInternal
+	}
+	return *models.WorkoutSummariesFromRows(rows), nil
+}
+
+// clampLimit falls back to the default page size for limits out of range,
+// the same way the REST endpoints do.
+func clampLimit(limit int) int {
+	if limit < 1 || limit > maxLimit {
+		return defaultLimit
+	}
+	return limit
+}
+
+func nullable(value string, valid bool) interface{} {
+	if !valid {
+		return nil
+	}
+	return value
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/analysis"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+)
+
+// GetProgramAnalysis godoc
+// @Summary      Analyze the balance of a Program
+// @Description  Count the sets per muscle, weighting secondary muscles by half, the push/pull and compound/isolation ratios and the equipment needed, and warn about neglected major muscle groups and imbalances
+// @Tags         programs
+// @Produce      json
+// @Param        uuid		path      string  	true	"Programs UUID"
+// @Param        share		query      string  	false	"Share token of a link-only program"
+// @Success      200	{object}  models.ProgramAnalysis
+// @Failure      400
+// @Failure      404
+// @Failure      500
+// @Router       /api/program/{uuid}/analysis [get]
+func (h *Handler) GetProgramAnalysis(w http.ResponseWriter, r *http.Request) {
+	log.Println("GET /api/program/{uuid}/analysis endpoint called")
+	var program_uuid pgtype.UUID
+	if err := program_uuid.Scan(chi.URLParam(r, "uuid")); err != nil {
+		log.Printf("Error scanning UUID Value from URL: %v, uuid: %s", err, chi.URLParam(r, "uuid"))
+		http.Error(w, "Invalid UUID", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.readableProgram(r, program_uuid); err != nil {
+		writeAccessError(w, err, chi.URLParam(r, "uuid"))
+		return
+	}
+
+	rows, err := db.Queriez.GetProgramAnalysisItems(r.Context(), program_uuid)
+	if err != nil {
+		log.Printf("Error at GETting the program items from DB: %v, uuid: %s", err, chi.URLParam(r, "uuid"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if len(rows) == 0 {
+		http.Error(w, "Program not found", http.StatusNotFound)
+		return
+	}
+
+	result := analysis.Analyze(rows)
+	result.ProgramID = program_uuid.Bytes
+	writeJSON(w, http.StatusOK, result)
+}
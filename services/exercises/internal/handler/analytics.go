@@ -0,0 +1,142 @@
+package service
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/analytics"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/auth"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/models"
+)
+
+// GetWeeklyVolume godoc
+// @Summary      Weekly volume per muscle
+// @Description  Get the sets, reps and tonnage trained per muscle and week by the authenticated user
+// @Tags         analytics
+// @Produce      json
+// @Param        weeks		query      int  	false	"Number of weeks to look back (default 8, max 52)"
+// @Success      200	{array}  models.MuscleVolume
+// @Failure      401
+// @Failure      500
+// @Router       /api/analytics/volume [get]
+func GetWeeklyVolume(w http.ResponseWriter, r *http.Request) {
+	log.Println("GET /api/analytics/volume endpoint called")
+	userID, _ := auth.UserID(r.Context())
+
+	weeks := 8
+	if wk, err := strconv.Atoi(r.URL.Query().Get("weeks")); err == nil && wk > 0 && wk <= 52 {
+		weeks = wk
+	}
+
+	if err := analytics.CatchUp(r.Context(), userID); err != nil {
+		log.Printf("Error at catching up analytics: %v, user: %d", err, userID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	since := time.Now().UTC().AddDate(0, 0, -7*weeks)
+	rows, err := db.Queriez.GetWeeklyMuscleVolume(r.Context(), db.GetWeeklyMuscleVolumeParams{
+		UserID: userID,
+		Since:  pgtype.Date{Time: since, Valid: true},
+	})
+	if err != nil {
+		log.Printf("Couldn't Fetch muscle volume from db: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, models.MuscleVolumeFromRows(rows))
+}
+
+// GetE1RMTrend godoc
+// @Summary      Estimated 1RM trend
+// @Description  Get the best estimated 1RM per workout of an exercise for the authenticated user
+// @Tags         analytics
+// @Produce      json
+// @Param        exerciseId		path      int  	true	"Exercise ID"
+// @Param        formula		query      string  	false	"epley (default) or brzycki"
+// @Success      200	{object}  models.E1RMTrend
+// @Failure      400
+// @Failure      401
+// @Failure      500
+// @Router       /api/analytics/e1rm/{exerciseId} [get]
+func GetE1RMTrend(w http.ResponseWriter, r *http.Request) {
+	log.Println("GET /api/analytics/e1rm/{exerciseId} endpoint called")
+	userID, _ := auth.UserID(r.Context())
+
+	exerciseID, err := strconv.Atoi(chi.URLParam(r, "exerciseId"))
+	if err != nil {
+		http.Error(w, "Invalid exercise ID", http.StatusBadRequest)
+		return
+	}
+	formula, err := analytics.ParseFormula(r.URL.Query().Get("formula"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := analytics.CatchUp(r.Context(), userID); err != nil {
+		log.Printf("Error at catching up analytics: %v, user: %d", err, userID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	rows, err := db.Queriez.GetE1rmTrend(r.Context(), db.GetE1rmTrendParams{
+		UserID:     userID,
+		ExerciseID: int32(exerciseID),
+	})
+	if err != nil {
+		log.Printf("Couldn't Fetch e1rm trend from db: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, models.E1RMTrendFromRows(exerciseID, string(formula), rows))
+}
+
+// GetPersonalRecords godoc
+// @Summary      Personal records
+// @Description  Get the current personal records (heaviest weight, best estimated 1RM and best set volume) of the authenticated user
+// @Tags         analytics
+// @Produce      json
+// @Param        exerciseId		query      int  	false	"Only the records of this exercise"
+// @Success      200	{array}  models.PersonalRecord
+// @Failure      400
+// @Failure      401
+// @Failure      500
+// @Router       /api/analytics/records [get]
+func GetPersonalRecords(w http.ResponseWriter, r *http.Request) {
+	log.Println("GET /api/analytics/records endpoint called")
+	userID, _ := auth.UserID(r.Context())
+
+	params := db.GetPersonalRecordsParams{UserID: userID}
+	if exerciseParam := r.URL.Query().Get("exerciseId"); exerciseParam != "" {
+		exerciseID, err := strconv.Atoi(exerciseParam)
+		if err != nil {
+			http.Error(w, "Invalid exercise ID", http.StatusBadRequest)
+			return
+		}
+		params.ExerciseID = pgtype.Int4{Int32: int32(exerciseID), Valid: true}
+	}
+
+	if err := analytics.CatchUp(r.Context(), userID); err != nil {
+		log.Printf("Error at catching up analytics: %v, user: %d", err, userID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	rows, err := db.Queriez.GetPersonalRecords(r.Context(), params)
+	if err != nil {
+		log.Printf("Couldn't Fetch personal records from db: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, models.PersonalRecordsFromRows(rows))
+}
@@ -0,0 +1,409 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/auth"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/calendar"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/export"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/models"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/repository"
+)
+
+const (
+	// defaultAgendaDays is how far ahead the agenda looks by default.
+	defaultAgendaDays = 28
+	maxAgendaDays     = 366
+)
+
+// PostSchedule godoc
+// @Summary      Schedule a Program
+// @Description  Lay the days of a readable program out on the weekdays of the user's calendar, for a number of weeks
+// @Tags         calendar
+// @Accept       json
+// @Produce      json
+// @Param        schedule	body      models.ScheduleRequest  	true	"Dates of the sessions"
+// @Param        share		query      string  	false	"Share token of a link-only program"
+// @Success      201	{object}  models.ProgramSchedule
+// @Failure      400
+// @Failure      401
+// @Failure      404
+// @Failure      500
+// @Router       /api/schedules [post]
+func (h *Handler) PostSchedule(w http.ResponseWriter, r *http.Request) {
+	log.Println("POST /api/schedules endpoint called")
+	userID, _ := auth.UserID(r.Context())
+
+	var req models.ScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Invalid JSON in PostSchedule: %v", err)
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	schedule, err := calendar.NewSchedule(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	programID := pgtype.UUID{Bytes: req.ProgramID, Valid: true}
+	if _, err := h.readableProgram(r, programID); err != nil {
+		writeAccessError(w, err, req.ProgramID.String())
+		return
+	}
+
+	params := db.InsertProgramScheduleParams{
+		UserID:          userID,
+		ProgramID:       programID,
+		StartDate:       pgtype.Date{Time: schedule.StartDate, Valid: true},
+		Weeks:           int32(schedule.Weeks),
+		DurationMinutes: int32(schedule.Duration / time.Minute),
+		Timezone:        schedule.Location.String(),
+	}
+	for _, day := range schedule.Weekdays {
+		params.Weekdays = append(params.Weekdays, int32(day))
+	}
+	if schedule.StartTime != nil {
+		params.StartTime = pgtype.Time{Microseconds: schedule.StartTime.Microseconds(), Valid: true}
+	}
+
+	row, err := db.Queriez.InsertProgramSchedule(r.Context(), params)
+	if err != nil {
+		log.Printf("Error at inserting program schedule: %v, program_id: %s", err, req.ProgramID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, models.ProgramScheduleFromRow(row))
+}
+
+// GetSchedules godoc
+// @Summary      List schedules
+// @Description  List the program schedules of the authenticated user
+// @Tags         calendar
+// @Produce      json
+// @Success      200	{array}  models.ProgramSchedule
+// @Failure      401
+// @Failure      500
+// @Router       /api/schedules [get]
+func GetSchedules(w http.ResponseWriter, r *http.Request) {
+	log.Println("GET /api/schedules endpoint called")
+	userID, _ := auth.UserID(r.Context())
+
+	rows, err := db.Queriez.GetProgramSchedules(r.Context(), userID)
+	if err != nil {
+		log.Printf("Error at GETting the schedules from DB: %v, user: %d", err, userID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	schedules := make([]models.ProgramSchedule, 0, len(rows))
+	for _, row := range rows {
+		schedules = append(schedules, models.ProgramScheduleFromRow(row))
+	}
+	writeJSON(w, http.StatusOK, schedules)
+}
+
+// DeleteSchedule godoc
+// @Summary      Delete a schedule
+// @Description  Remove a program schedule, and its sessions, from the calendar of the authenticated user
+// @Tags         calendar
+// @Param        id		path      string  	true	"Schedule UUID"
+// @Success      204
+// @Failure      400
+// @Failure      401
+// @Failure      404
+// @Failure      500
+// @Router       /api/schedules/{id} [delete]
+func DeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	log.Println("DELETE /api/schedules/{id} endpoint called")
+	userID, _ := auth.UserID(r.Context())
+
+	var schedule_uuid pgtype.UUID
+	if err := schedule_uuid.Scan(chi.URLParam(r, "id")); err != nil {
+		log.Printf("Error scanning UUID Value from URL: %v, uuid: %s", err, chi.URLParam(r, "id"))
+		http.Error(w, "Invalid UUID", http.StatusBadRequest)
+		return
+	}
+
+	deleted, err := db.Queriez.DeleteProgramSchedule(r.Context(), db.DeleteProgramScheduleParams{ID: schedule_uuid, UserID: userID})
+	if err != nil {
+		log.Printf("Error at deleting schedule: %v, uuid: %s", err, chi.URLParam(r, "id"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if deleted == 0 {
+		http.Error(w, "Schedule not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetAgenda godoc
+// @Summary      Get the agenda
+// @Description  List the scheduled sessions of the authenticated user between two dates, with the exercises of each session. Defaults to the next 4 weeks.
+// @Tags         calendar
+// @Produce      json
+// @Param        from		query      string  	false	"First date, e.g. 2026-11-02"
+// @Param        to		query      string  	false	"Last date, included"
+// @Success      200	{array}  models.AgendaEntry
+// @Failure      400
+// @Failure      401
+// @Failure      500
+// @Router       /api/agenda [get]
+func (h *Handler) GetAgenda(w http.ResponseWriter, r *http.Request) {
+	log.Println("GET /api/agenda endpoint called")
+	userID, _ := auth.UserID(r.Context())
+
+	from := time.Now().UTC().Truncate(24 * time.Hour)
+	if v := r.URL.Query().Get("from"); v != "" {
+		t, err := time.Parse(calendar.DateLayout, v)
+		if err != nil {
+			http.Error(w, "from must be a date such as 2026-11-02", http.StatusBadRequest)
+			return
+		}
+		from = t
+	}
+	to := from.AddDate(0, 0, defaultAgendaDays-1)
+	if v := r.URL.Query().Get("to"); v != "" {
+		t, err := time.Parse(calendar.DateLayout, v)
+		if err != nil {
+			http.Error(w, "to must be a date such as 2026-11-30", http.StatusBadRequest)
+			return
+		}
+		to = t
+	}
+	if to.Before(from) || to.Sub(from) >= maxAgendaDays*24*time.Hour {
+		http.Error(w, fmt.Sprintf("to must be on or after from, and at most %d days later", maxAgendaDays-1), http.StatusBadRequest)
+		return
+	}
+
+	sessions, err := h.userSessions(r.Context(), userID)
+	if err != nil {
+		log.Printf("Error at laying out the schedules: %v, user: %d", err, userID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	agenda := make([]models.AgendaEntry, 0)
+	for _, s := range sessions {
+		if s.Date.Before(from) || s.Date.After(to) {
+			continue
+		}
+		agenda = append(agenda, models.AgendaEntry{
+			ScheduleID: s.schedule.ID.Bytes,
+			ProgramID:  s.schedule.ProgramID.Bytes,
+			Session:    s.Number,
+			Day:        s.Day,
+			Date:       s.Date.Format(calendar.DateLayout),
+			Start:      s.Start,
+			End:        s.End,
+			Exercises:  s.exercises,
+		})
+	}
+	writeJSON(w, http.StatusOK, agenda)
+}
+
+// PostCalendarFeed godoc
+// @Summary      Create the calendar feed
+// @Description  Create the secret iCalendar feed address of the authenticated user. Calling it again replaces the token, which stops the previous address from working.
+// @Tags         calendar
+// @Produce      json
+// @Success      201	{object}  models.CalendarFeed
+// @Failure      401
+// @Failure      500
+// @Router       /api/calendar/feed [post]
+func PostCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	log.Println("POST /api/calendar/feed endpoint called")
+	userID, _ := auth.UserID(r.Context())
+
+	token, err := newShareToken()
+	if err != nil {
+		log.Printf("Error at generating calendar token: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	row, err := db.Queriez.UpsertCalendarFeed(r.Context(), db.UpsertCalendarFeedParams{UserID: userID, Token: token})
+	if err != nil {
+		log.Printf("Error at inserting calendar feed: %v, user: %d", err, userID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, models.CalendarFeedFromRow(row))
+}
+
+// GetCalendarFeed godoc
+// @Summary      Get the calendar feed
+// @Description  Get the iCalendar feed address of the authenticated user
+// @Tags         calendar
+// @Produce      json
+// @Success      200	{object}  models.CalendarFeed
+// @Failure      401
+// @Failure      404
+// @Failure      500
+// @Router       /api/calendar/feed [get]
+func GetCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	log.Println("GET /api/calendar/feed endpoint called")
+	userID, _ := auth.UserID(r.Context())
+
+	row, err := db.Queriez.GetCalendarFeed(r.Context(), userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		http.Error(w, "No calendar feed yet", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error at GETting the calendar feed from DB: %v, user: %d", err, userID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, models.CalendarFeedFromRow(row))
+}
+
+// GetCalendarICS godoc
+// @Summary      iCalendar feed
+// @Description  Every scheduled session of the owner of the token as an iCalendar document, for subscribing from a calendar app. The token is the credential, no bearer token is needed.
+// @Tags         calendar
+// @Produce      text/calendar
+// @Param        token		path      string  	true	"Calendar feed token"
+// @Success      200
+// @Failure      404
+// @Failure      500
+// @Router       /api/calendar/{token}.ics [get]
+func (h *Handler) GetCalendarICS(w http.ResponseWriter, r *http.Request) {
+	log.Println("GET /api/calendar/{token}.ics endpoint called")
+
+	feed, err := db.Queriez.GetCalendarFeedByToken(r.Context(), chi.URLParam(r, "token"))
+	if errors.Is(err, pgx.ErrNoRows) {
+		http.Error(w, "Calendar not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error at GETting the calendar feed from DB: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	sessions, err := h.userSessions(r.Context(), feed.UserID)
+	if err != nil {
+		log.Printf("Error at laying out the schedules: %v, user: %d", err, feed.UserID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	events := make([]calendar.Event, 0, len(sessions))
+	for _, s := range sessions {
+		scheduleID := uuid.UUID(s.schedule.ID.Bytes)
+		event := calendar.Event{
+			UID:         fmt.Sprintf("%s-%d@guddy", scheduleID, s.Number),
+			Stamp:       s.schedule.CreatedAt.Time,
+			Start:       s.Date,
+			End:         s.Date.AddDate(0, 0, 1),
+			AllDay:      true,
+			Summary:     fmt.Sprintf("Workout: day %d", s.Day),
+			Description: strings.Join(s.exercises, "\n"),
+		}
+		if s.Start != nil {
+			event.Start, event.End, event.AllDay = *s.Start, *s.End, false
+		}
+		events = append(events, event)
+	}
+
+	var buf bytes.Buffer
+	if err := calendar.WriteICS(&buf, "Workouts", events); err != nil {
+		log.Printf("Error at writing the calendar: %v, user: %d", err, feed.UserID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Write(buf.Bytes())
+}
+
+// scheduledSession is a session of one of the user's schedules with the
+// exercises of its program day.
+type scheduledSession struct {
+	calendar.Session
+	schedule  db.ProgramSchedule
+	exercises []string
+}
+
+// userSessions lays out every session of the user's schedules, in date
+// order. Programs are read as they are now, so edits show up in sessions
+// already scheduled, and the schedules of programs the user can no longer
+// read are left out.
+func (h *Handler) userSessions(ctx context.Context, userID int64) ([]scheduledSession, error) {
+	rows, err := db.Queriez.GetProgramSchedules(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Feeds are fetched without a user, the access is checked as the owner
+	// of the schedules
+	userCtx := auth.WithUserID(ctx, userID)
+	outlines := make(map[pgtype.UUID]map[int][]string)
+	unreadable := make(map[pgtype.UUID]bool)
+	sessions := make([]scheduledSession, 0)
+	for _, row := range rows {
+		if unreadable[row.ProgramID] {
+			continue
+		}
+		outline, ok := outlines[row.ProgramID]
+		if !ok {
+			_, err := h.readableProgramWithToken(userCtx, row.ProgramID, "")
+			if errors.Is(err, pgx.ErrNoRows) {
+				unreadable[row.ProgramID] = true
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			program, err := repository.FetchCompleteProgram(ctx, db.Queriez, row.ProgramID)
+			if err != nil {
+				return nil, err
+			}
+			outline = export.Outline(program)
+			outlines[row.ProgramID] = outline
+		}
+
+		schedule, err := calendar.ScheduleFromRow(row)
+		if err != nil {
+			return nil, err
+		}
+		days := make([]int, 0, len(outline))
+		for day := range outline {
+			days = append(days, day)
+		}
+		sort.Ints(days)
+
+		for _, s := range schedule.Sessions(days) {
+			sessions = append(sessions, scheduledSession{Session: s, schedule: row, exercises: outline[s.Day]})
+		}
+	}
+
+	sort.SliceStable(sessions, func(i, j int) bool {
+		a, b := sessions[i], sessions[j]
+		if !a.Date.Equal(b.Date) {
+			return a.Date.Before(b.Date)
+		}
+		// All-day sessions first
+		if a.Start == nil || b.Start == nil {
+			return a.Start == nil && b.Start != nil
+		}
+		return a.Start.Before(*b.Start)
+	})
+	return sessions, nil
+}
@@ -0,0 +1,687 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/auth"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/models"
+)
+
+// invitationTTL is how long an invitation code can be accepted.
+const invitationTTL = 7 * 24 * time.Hour
+
+// RequireCoach rejects requests of users without the coach role. It runs
+// after auth.RequireUser.
+func RequireCoach(next http.Handler) http.Handler {
+	return requireRole(next, db.RoleTCoach, "Only coaches can do this")
+}
+
+func requireRole(next http.Handler, role db.RoleT, message string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, _ := auth.UserID(r.Context())
+		hasRole, err := db.Queriez.HasUserRole(r.Context(), db.HasUserRoleParams{UserID: userID, Role: role})
+		if err != nil {
+			log.Printf("Error at GETting the roles from DB: %v, user: %d", err, userID)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		if !hasRole {
+			http.Error(w, message, http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// GetMyRoles godoc
+// @Summary      Get the roles of the user
+// @Description  Get the coach, client and admin roles of the authenticated user
+// @Tags         coaching
+// @Produce      json
+// @Success      200	{object}  models.UserRoles
+// @Failure      401
+// @Failure      500
+// @Router       /api/me/roles [get]
+func GetMyRoles(w http.ResponseWriter, r *http.Request) {
+	log.Println("GET /api/me/roles endpoint called")
+	userID, _ := auth.UserID(r.Context())
+
+	roles, err := db.Queriez.GetUserRoles(r.Context(), userID)
+	if err != nil {
+		log.Printf("Error at GETting the roles from DB: %v, user: %d", err, userID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if roles == nil {
+		roles = []db.RoleT{}
+	}
+	writeJSON(w, http.StatusOK, models.UserRoles{UserID: userID, Roles: roles})
+}
+
+// BecomeCoach godoc
+// @Summary      Become a coach
+// @Description  Give the authenticated user the coach role, which lets them invite clients
+// @Tags         coaching
+// @Produce      json
+// @Success      200	{object}  models.UserRoles
+// @Failure      401
+// @Failure      500
+// @Router       /api/coach [post]
+func BecomeCoach(w http.ResponseWriter, r *http.Request) {
+	log.Println("POST /api/coach endpoint called")
+	userID, _ := auth.UserID(r.Context())
+
+	if err := db.Queriez.AddUserRole(r.Context(), db.AddUserRoleParams{UserID: userID, Role: db.RoleTCoach}); err != nil {
+		log.Printf("Error at inserting user role: %v, user: %d", err, userID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	GetMyRoles(w, r)
+}
+
+// PostCoachInvitation godoc
+// @Summary      Invite a client
+// @Description  Create a single use invitation code, valid for 7 days, that links the client accepting it to the coach
+// @Tags         coaching
+// @Produce      json
+// @Success      201	{object}  models.CoachInvitation
+// @Failure      401
+// @Failure      403
+// @Failure      500
+// @Router       /api/coach/invitations [post]
+func PostCoachInvitation(w http.ResponseWriter, r *http.Request) {
+	log.Println("POST /api/coach/invitations endpoint called")
+	coachID, _ := auth.UserID(r.Context())
+
+	code, err := newShareToken()
+	if err != nil {
+		log.Printf("Error at generating invitation code: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	row, err := db.Queriez.InsertCoachInvitation(r.Context(), db.InsertCoachInvitationParams{
+		Code:      code,
+		CoachID:   coachID,
+		ExpiresAt: pgtype.Timestamptz{Time: now.Add(invitationTTL), Valid: true},
+	})
+	if err != nil {
+		log.Printf("Error at inserting invitation: %v, coach: %d", err, coachID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, models.CoachInvitationFromRow(row, now))
+}
+
+// GetCoachInvitations godoc
+// @Summary      List invitations
+// @Description  List the invitations of the coach, newest first
+// @Tags         coaching
+// @Produce      json
+// @Success      200	{array}  models.CoachInvitation
+// @Failure      401
+// @Failure      403
+// @Failure      500
+// @Router       /api/coach/invitations [get]
+func GetCoachInvitations(w http.ResponseWriter, r *http.Request) {
+	log.Println("GET /api/coach/invitations endpoint called")
+	coachID, _ := auth.UserID(r.Context())
+
+	rows, err := db.Queriez.GetCoachInvitations(r.Context(), coachID)
+	if err != nil {
+		log.Printf("Error at GETting the invitations from DB: %v, coach: %d", err, coachID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	invitations := make([]models.CoachInvitation, 0, len(rows))
+	for _, row := range rows {
+		invitations = append(invitations, models.CoachInvitationFromRow(row, now))
+	}
+	writeJSON(w, http.StatusOK, invitations)
+}
+
+// DeleteCoachInvitation godoc
+// @Summary      Revoke an invitation
+// @Description  Revoke an invitation that wasn't accepted yet
+// @Tags         coaching
+// @Param        code		path      string  	true	"Invitation code"
+// @Success      204
+// @Failure      401
+// @Failure      403
+// @Failure      404
+// @Failure      500
+// @Router       /api/coach/invitations/{code} [delete]
+func DeleteCoachInvitation(w http.ResponseWriter, r *http.Request) {
+	log.Println("DELETE /api/coach/invitations/{code} endpoint called")
+	coachID, _ := auth.UserID(r.Context())
+
+	revoked, err := db.Queriez.RevokeCoachInvitation(r.Context(), db.RevokeCoachInvitationParams{Code: chi.URLParam(r, "code"), CoachID: coachID})
+	if err != nil {
+		log.Printf("Error at revoking invitation: %v, coach: %d", err, coachID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if revoked == 0 {
+		http.Error(w, "Invitation not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AcceptCoachInvitation godoc
+// @Summary      Accept an invitation
+// @Description  Link the authenticated user, as a client, to the coach who created the invitation. The client shares their workouts and accepts assignments until they change their consent.
+// @Tags         coaching
+// @Produce      json
+// @Param        code		path      string  	true	"Invitation code"
+// @Success      201	{object}  models.CoachLink
+// @Failure      401
+// @Failure      404
+// @Failure      500
+// @Router       /api/invitations/{code}/accept [post]
+func AcceptCoachInvitation(w http.ResponseWriter, r *http.Request) {
+	log.Println("POST /api/invitations/{code}/accept endpoint called")
+	clientID, _ := auth.UserID(r.Context())
+
+	tx, err := db.GetPool().Begin(r.Context())
+	if err != nil {
+		log.Printf("Error at starting transaction: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback(r.Context())
+	qtx := db.Queriez.WithTx(tx)
+
+	invitation, err := qtx.AcceptCoachInvitation(r.Context(), db.AcceptCoachInvitationParams{Code: chi.URLParam(r, "code"), ClientID: clientID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		http.Error(w, "Invitation not found or no longer valid", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error at accepting invitation: %v, client: %d", err, clientID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	link, err := qtx.UpsertCoachClient(r.Context(), db.UpsertCoachClientParams{CoachID: invitation.CoachID, ClientID: clientID})
+	if err != nil {
+		log.Printf("Error at linking coach and client: %v, coach: %d, client: %d", err, invitation.CoachID, clientID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if err := qtx.AddUserRole(r.Context(), db.AddUserRoleParams{UserID: clientID, Role: db.RoleTClient}); err != nil {
+		log.Printf("Error at inserting user role: %v, user: %d", err, clientID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("Error at committing invitation: %v, client: %d", err, clientID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, models.CoachLinkFromRow(link))
+}
+
+// GetCoachClients godoc
+// @Summary      List clients
+// @Description  List the clients of the coach with what they consent to
+// @Tags         coaching
+// @Produce      json
+// @Success      200	{array}  models.CoachLink
+// @Failure      401
+// @Failure      403
+// @Failure      500
+// @Router       /api/coach/clients [get]
+func GetCoachClients(w http.ResponseWriter, r *http.Request) {
+	log.Println("GET /api/coach/clients endpoint called")
+	coachID, _ := auth.UserID(r.Context())
+
+	rows, err := db.Queriez.GetCoachClients(r.Context(), coachID)
+	if err != nil {
+		log.Printf("Error at GETting the clients from DB: %v, coach: %d", err, coachID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, models.CoachLinksFromRows(rows))
+}
+
+// DeleteCoachClient godoc
+// @Summary      Drop a client
+// @Description  End the link with a client, unassigning the programs assigned to them
+// @Tags         coaching
+// @Param        clientId		path      int  	true	"Client user ID"
+// @Success      204
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      404
+// @Failure      500
+// @Router       /api/coach/clients/{clientId} [delete]
+func DeleteCoachClient(w http.ResponseWriter, r *http.Request) {
+	log.Println("DELETE /api/coach/clients/{clientId} endpoint called")
+	link, ok := coachClient(w, r)
+	if !ok {
+		return
+	}
+	endCoachLink(w, r, link)
+}
+
+// PostAssignment godoc
+// @Summary      Assign a Program
+// @Description  Assign a program the coach can read without a share token to a client who accepts assignments. The client can read it for as long as it stays assigned.
+// @Tags         coaching
+// @Accept       json
+// @Produce      json
+// @Param        clientId		path      int  	true	"Client user ID"
+// @Param        assignment	body      models.AssignProgramRequest  	true	"Program to assign"
+// @Success      201	{object}  models.ProgramAssignment
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      404
+// @Failure      500
+// @Router       /api/coach/clients/{clientId}/assignments [post]
+func (h *Handler) PostAssignment(w http.ResponseWriter, r *http.Request) {
+	log.Println("POST /api/coach/clients/{clientId}/assignments endpoint called")
+	link, ok := coachClient(w, r)
+	if !ok {
+		return
+	}
+	if !link.AllowAssignments {
+		http.Error(w, "The client doesn't accept assignments", http.StatusForbidden)
+		return
+	}
+
+	var req models.AssignProgramRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Invalid JSON in PostAssignment: %v", err)
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	// A share token only lets the coach read a link-only program, not
+	// hand it on, so the assignment ignores it.
+	programID := pgtype.UUID{Bytes: req.ProgramID, Valid: true}
+	if _, err := h.readableProgramWithToken(r.Context(), programID, ""); err != nil {
+		writeAccessError(w, err, req.ProgramID.String())
+		return
+	}
+
+	row, err := db.Queriez.InsertProgramAssignment(r.Context(), db.InsertProgramAssignmentParams{
+		CoachID:   link.CoachID,
+		ClientID:  link.ClientID,
+		ProgramID: programID,
+	})
+	if err != nil {
+		log.Printf("Error at inserting assignment: %v, program_id: %s", err, req.ProgramID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, models.ProgramAssignmentFromRow(row))
+}
+
+// GetClientAssignments godoc
+// @Summary      List the assignments of a client
+// @Description  List the programs the coach assigned to a client
+// @Tags         coaching
+// @Produce      json
+// @Param        clientId		path      int  	true	"Client user ID"
+// @Success      200	{array}  models.ProgramAssignment
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      404
+// @Failure      500
+// @Router       /api/coach/clients/{clientId}/assignments [get]
+func GetClientAssignments(w http.ResponseWriter, r *http.Request) {
+	log.Println("GET /api/coach/clients/{clientId}/assignments endpoint called")
+	link, ok := coachClient(w, r)
+	if !ok {
+		return
+	}
+
+	rows, err := db.Queriez.GetClientAssignments(r.Context(), db.GetClientAssignmentsParams{CoachID: link.CoachID, ClientID: link.ClientID})
+	if err != nil {
+		log.Printf("Error at GETting the assignments from DB: %v, client: %d", err, link.ClientID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, models.ProgramAssignmentsFromRows(rows))
+}
+
+// DeleteAssignment godoc
+// @Summary      Unassign a Program
+// @Description  Take back a program assigned to a client
+// @Tags         coaching
+// @Param        clientId		path      int  	true	"Client user ID"
+// @Param        id		path      string  	true	"Assignment UUID"
+// @Success      204
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      404
+// @Failure      500
+// @Router       /api/coach/clients/{clientId}/assignments/{id} [delete]
+func DeleteAssignment(w http.ResponseWriter, r *http.Request) {
+	log.Println("DELETE /api/coach/clients/{clientId}/assignments/{id} endpoint called")
+	link, ok := coachClient(w, r)
+	if !ok {
+		return
+	}
+
+	var assignment_uuid pgtype.UUID
+	if err := assignment_uuid.Scan(chi.URLParam(r, "id")); err != nil {
+		log.Printf("Error scanning UUID Value from URL: %v, uuid: %s", err, chi.URLParam(r, "id"))
+		http.Error(w, "Invalid UUID", http.StatusBadRequest)
+		return
+	}
+
+	unassigned, err := db.Queriez.UnassignProgram(r.Context(), db.UnassignProgramParams{
+		ID:       assignment_uuid,
+		CoachID:  link.CoachID,
+		ClientID: link.ClientID,
+	})
+	if err != nil {
+		log.Printf("Error at unassigning program: %v, uuid: %s", err, chi.URLParam(r, "id"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if unassigned == 0 {
+		http.Error(w, "Assignment not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetClientWorkouts godoc
+// @Summary      List the workouts of a client
+// @Description  Workout history of a client who shares their workouts, most recent first
+// @Tags         coaching
+// @Produce      json
+// @Param        clientId		path      int  	true	"Client user ID"
+// @Param		 limit		query		int		false	"Limit"
+// @Param		 offset		query		int		false	"Offset"
+// @Success      200	{array}  models.WorkoutSummary
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      404
+// @Failure      500
+// @Router       /api/coach/clients/{clientId}/workouts [get]
+func GetClientWorkouts(w http.ResponseWriter, r *http.Request) {
+	log.Println("GET /api/coach/clients/{clientId}/workouts endpoint called")
+	link, ok := sharingClient(w, r)
+	if !ok {
+		return
+	}
+	limit, offset := pagination(r)
+
+	rows, err := db.Queriez.GetWorkoutsByUser(r.Context(), db.GetWorkoutsByUserParams{
+		UserID: link.ClientID,
+		Limit:  int32(limit),
+		Offset: int32(offset),
+	})
+	if err != nil {
+		log.Printf("Couldn't Fetch workouts from db: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, models.WorkoutSummariesFromRows(rows))
+}
+
+// GetClientWorkout godoc
+// @Summary      Get a workout of a client
+// @Description  Get a workout of a client who shares their workouts, with its plan and logged sets
+// @Tags         coaching
+// @Produce      json
+// @Param        clientId		path      int  	true	"Client user ID"
+// @Param        id		path      string  	true	"Workout UUID"
+// @Success      200	{object}  models.Workout
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      404
+// @Failure      500
+// @Router       /api/coach/clients/{clientId}/workouts/{id} [get]
+func GetClientWorkout(w http.ResponseWriter, r *http.Request) {
+	log.Println("GET /api/coach/clients/{clientId}/workouts/{id} endpoint called")
+	link, ok := sharingClient(w, r)
+	if !ok {
+		return
+	}
+
+	var workoutID pgtype.UUID
+	if err := workoutID.Scan(chi.URLParam(r, "id")); err != nil {
+		log.Printf("Error scanning UUID Value from URL: %v, id: %s", err, chi.URLParam(r, "id"))
+		http.Error(w, "Invalid UUID", http.StatusBadRequest)
+		return
+	}
+
+	row, err := db.Queriez.GetWorkoutById(r.Context(), workoutID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && row.UserID != link.ClientID) {
+		http.Error(w, "Workout not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error at GETting the workout from DB: %v, id: %s", err, chi.URLParam(r, "id"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	workout, err := completeWorkout(r.Context(), row)
+	if err != nil {
+		log.Printf("Error at GETting the workout from DB: %v, id: %s", err, chi.URLParam(r, "id"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, workout)
+}
+
+// GetMyCoaches godoc
+// @Summary      List coaches
+// @Description  List the coaches of the authenticated user with what they consent to
+// @Tags         coaching
+// @Produce      json
+// @Success      200	{array}  models.CoachLink
+// @Failure      401
+// @Failure      500
+// @Router       /api/me/coaches [get]
+func GetMyCoaches(w http.ResponseWriter, r *http.Request) {
+	log.Println("GET /api/me/coaches endpoint called")
+	clientID, _ := auth.UserID(r.Context())
+
+	rows, err := db.Queriez.GetClientCoaches(r.Context(), clientID)
+	if err != nil {
+		log.Printf("Error at GETting the coaches from DB: %v, client: %d", err, clientID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, models.CoachLinksFromRows(rows))
+}
+
+// PutCoachConsent godoc
+// @Summary      Change consent
+// @Description  Choose whether a coach can see the workouts of the authenticated user and assign them programs
+// @Tags         coaching
+// @Accept       json
+// @Produce      json
+// @Param        coachId		path      int  	true	"Coach user ID"
+// @Param        consent	body      models.ConsentRequest  	true	"What the client consents to"
+// @Success      200	{object}  models.CoachLink
+// @Failure      400
+// @Failure      401
+// @Failure      404
+// @Failure      500
+// @Router       /api/me/coaches/{coachId}/consent [put]
+func PutCoachConsent(w http.ResponseWriter, r *http.Request) {
+	log.Println("PUT /api/me/coaches/{coachId}/consent endpoint called")
+	link, ok := clientCoach(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.ConsentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Invalid JSON in PutCoachConsent: %v", err)
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	params := db.UpdateClientConsentParams{
+		CoachID:          link.CoachID,
+		ClientID:         link.ClientID,
+		ShareWorkouts:    link.ShareWorkouts,
+		AllowAssignments: link.AllowAssignments,
+	}
+	if req.ShareWorkouts != nil {
+		params.ShareWorkouts = *req.ShareWorkouts
+	}
+	if req.AllowAssignments != nil {
+		params.AllowAssignments = *req.AllowAssignments
+	}
+
+	row, err := db.Queriez.UpdateClientConsent(r.Context(), params)
+	if errors.Is(err, pgx.ErrNoRows) {
+		http.Error(w, "Coach not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error at updating consent: %v, coach: %d, client: %d", err, link.CoachID, link.ClientID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, models.CoachLinkFromRow(row))
+}
+
+// DeleteMyCoach godoc
+// @Summary      Leave a coach
+// @Description  End the link with a coach, who loses access to the workouts of the authenticated user and to the programs assigned to them
+// @Tags         coaching
+// @Param        coachId		path      int  	true	"Coach user ID"
+// @Success      204
+// @Failure      400
+// @Failure      401
+// @Failure      404
+// @Failure      500
+// @Router       /api/me/coaches/{coachId} [delete]
+func DeleteMyCoach(w http.ResponseWriter, r *http.Request) {
+	log.Println("DELETE /api/me/coaches/{coachId} endpoint called")
+	link, ok := clientCoach(w, r)
+	if !ok {
+		return
+	}
+	endCoachLink(w, r, link)
+}
+
+// GetMyAssignments godoc
+// @Summary      List assigned Programs
+// @Description  List the programs coaches assigned to the authenticated user
+// @Tags         coaching
+// @Produce      json
+// @Success      200	{array}  models.ProgramAssignment
+// @Failure      401
+// @Failure      500
+// @Router       /api/me/assignments [get]
+func GetMyAssignments(w http.ResponseWriter, r *http.Request) {
+	log.Println("GET /api/me/assignments endpoint called")
+	clientID, _ := auth.UserID(r.Context())
+
+	rows, err := db.Queriez.GetAssignmentsOfClient(r.Context(), clientID)
+	if err != nil {
+		log.Printf("Error at GETting the assignments from DB: %v, client: %d", err, clientID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, models.ProgramAssignmentsFromRows(rows))
+}
+
+// coachClient loads the active link between the coach and the client of the
+// URL. On failure the response has already been written.
+func coachClient(w http.ResponseWriter, r *http.Request) (db.CoachClient, bool) {
+	coachID, _ := auth.UserID(r.Context())
+	clientID, err := strconv.ParseInt(chi.URLParam(r, "clientId"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid client ID", http.StatusBadRequest)
+		return db.CoachClient{}, false
+	}
+	return coachLink(r.Context(), w, coachID, clientID, "Client not found")
+}
+
+// sharingClient is coachClient for a client who shares their workouts.
+func sharingClient(w http.ResponseWriter, r *http.Request) (db.CoachClient, bool) {
+	link, ok := coachClient(w, r)
+	if ok && !link.ShareWorkouts {
+		http.Error(w, "The client doesn't share their workouts", http.StatusForbidden)
+		return link, false
+	}
+	return link, ok
+}
+
+// clientCoach loads the active link between the client and the coach of the
+// URL. On failure the response has already been written.
+func clientCoach(w http.ResponseWriter, r *http.Request) (db.CoachClient, bool) {
+	clientID, _ := auth.UserID(r.Context())
+	coachID, err := strconv.ParseInt(chi.URLParam(r, "coachId"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid coach ID", http.StatusBadRequest)
+		return db.CoachClient{}, false
+	}
+	return coachLink(r.Context(), w, coachID, clientID, "Coach not found")
+}
+
+func coachLink(ctx context.Context, w http.ResponseWriter, coachID, clientID int64, notFound string) (db.CoachClient, bool) {
+	link, err := db.Queriez.GetCoachClient(ctx, db.GetCoachClientParams{CoachID: coachID, ClientID: clientID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		http.Error(w, notFound, http.StatusNotFound)
+		return link, false
+	}
+	if err != nil {
+		log.Printf("Error at GETting the coach link from DB: %v, coach: %d, client: %d", err, coachID, clientID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return link, false
+	}
+	return link, true
+}
+
+// endCoachLink ends a link along with the assignments made through it.
+func endCoachLink(w http.ResponseWriter, r *http.Request, link db.CoachClient) {
+	tx, err := db.GetPool().Begin(r.Context())
+	if err != nil {
+		log.Printf("Error at starting transaction: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback(r.Context())
+	qtx := db.Queriez.WithTx(tx)
+
+	if err := qtx.UnassignClientPrograms(r.Context(), db.UnassignClientProgramsParams{CoachID: link.CoachID, ClientID: link.ClientID}); err != nil {
+		log.Printf("Error at unassigning programs: %v, coach: %d, client: %d", err, link.CoachID, link.ClientID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if _, err := qtx.EndCoachClient(r.Context(), db.EndCoachClientParams{CoachID: link.CoachID, ClientID: link.ClientID}); err != nil {
+		log.Printf("Error at ending coach link: %v, coach: %d, client: %d", err, link.CoachID, link.ClientID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("Error at committing coach link: %v, coach: %d, client: %d", err, link.CoachID, link.ClientID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
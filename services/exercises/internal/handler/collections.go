@@ -0,0 +1,595 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/auth"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/models"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/repository"
+)
+
+// FavoritesCollection is the value of the collection filter of
+// /api/exercises that selects the favorites of the user.
+const FavoritesCollection = "favorites"
+
+const maxCollectionName = 100
+
+// GetFavorites godoc
+// @Summary      List favorite exercises
+// @Description  Get the exercises the authenticated user marked as favorite
+// @Tags         collections
+// @Produce      json
+// @Param		 limit		query		int		false	"Limit"
+// @Param		 offset		query		int		false	"Offset"
+// @Success      200	{array}  models.Exercise
+// @Failure      401
+// @Failure      500
+// @Router       /api/me/favorites [get]
+func GetFavorites(w http.ResponseWriter, r *http.Request) {
+	log.Println("GET /api/me/favorites endpoint called")
+	userID, _ := auth.UserID(r.Context())
+
+	limit, offset := pagination(r)
+	rows, err := db.Queriez.GetExercises(r.Context(), db.GetExercisesParams{FavoritesOf: userID, Offset: offset, Limit: limit})
+	if err != nil {
+		log.Printf("Couldn't Fetch favorite exercises from db: %v, user: %d", err, userID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, models.ExerciseFromRows(rows))
+}
+
+// PutFavorite godoc
+// @Summary      Add a favorite exercise
+// @Description  Mark an exercise as favorite, marking it again does nothing
+// @Tags         collections
+// @Param        exerciseId		path      int  	true	"Exercise ID"
+// @Success      204
+// @Failure      400
+// @Failure      401
+// @Failure      404
+// @Failure      500
+// @Router       /api/me/favorites/{exerciseId} [put]
+func PutFavorite(w http.ResponseWriter, r *http.Request) {
+	log.Println("PUT /api/me/favorites/{exerciseId} endpoint called")
+	userID, _ := auth.UserID(r.Context())
+
+	exerciseID, ok := exerciseParam(w, r)
+	if !ok {
+		return
+	}
+	if err := checkExercisesExist(r.Context(), []int32{exerciseID}); err != nil {
+		writeExerciseError(w, err)
+		return
+	}
+
+	if err := addFavorite(r.Context(), userID, exerciseID); err != nil {
+		log.Printf("Error at inserting favorite: %v, user: %d", err, userID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteFavorite godoc
+// @Summary      Remove a favorite exercise
+// @Tags         collections
+// @Param        exerciseId		path      int  	true	"Exercise ID"
+// @Success      204
+// @Failure      400
+// @Failure      401
+// @Failure      404
+// @Failure      500
+// @Router       /api/me/favorites/{exerciseId} [delete]
+func DeleteFavorite(w http.ResponseWriter, r *http.Request) {
+	log.Println("DELETE /api/me/favorites/{exerciseId} endpoint called")
+	userID, _ := auth.UserID(r.Context())
+
+	exerciseID, ok := exerciseParam(w, r)
+	if !ok {
+		return
+	}
+
+	deleted, err := removeFavorite(r.Context(), userID, exerciseID)
+	if err != nil {
+		log.Printf("Error at deleting favorite: %v, user: %d", err, userID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if deleted == 0 {
+		http.Error(w, "Favorite not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// addFavorite marks an exercise as favorite, dropping the tombstone of an
+// earlier removal so syncing clients keep it.
+func addFavorite(ctx context.Context, userID int64, exerciseID int32) error {
+	tx, err := db.GetPool().Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	qtx := db.Queriez.WithTx(tx)
+
+	if err := qtx.AddFavorite(ctx, db.AddFavoriteParams{UserID: userID, ExerciseID: exerciseID}); err != nil {
+		return err
+	}
+	if err := qtx.DeleteSyncTombstones(ctx, db.DeleteSyncTombstonesParams{
+		UserID:   userID,
+		Entity:   models.SyncEntityFavorite,
+		EntityID: strconv.Itoa(int(exerciseID)),
+	}); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// removeFavorite unmarks an exercise, leaving a tombstone for syncing
+// clients. It returns the number of favorites removed.
+func removeFavorite(ctx context.Context, userID int64, exerciseID int32) (int64, error) {
+	tx, err := db.GetPool().Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := db.Queriez.WithTx(tx)
+
+	deleted, err := qtx.RemoveFavorite(ctx, db.RemoveFavoriteParams{UserID: userID, ExerciseID: exerciseID})
+	if err != nil || deleted == 0 {
+		return deleted, err
+	}
+	if err := qtx.InsertSyncTombstone(ctx, db.InsertSyncTombstoneParams{
+		UserID:   pgtype.Int8{Int64: userID, Valid: true},
+		Entity:   models.SyncEntityFavorite,
+		EntityID: strconv.Itoa(int(exerciseID)),
+	}); err != nil {
+		return 0, err
+	}
+	return deleted, tx.Commit(ctx)
+}
+
+// GetCollections godoc
+// @Summary      List collections
+// @Description  Get the exercise collections of the authenticated user, by name
+// @Tags         collections
+// @Produce      json
+// @Success      200	{array}  models.ExerciseCollection
+// @Failure      401
+// @Failure      500
+// @Router       /api/collections [get]
+func GetCollections(w http.ResponseWriter, r *http.Request) {
+	log.Println("GET /api/collections endpoint called")
+	userID, _ := auth.UserID(r.Context())
+
+	rows, err := db.Queriez.GetExerciseCollections(r.Context(), userID)
+	if err != nil {
+		log.Printf("Error at GETting collections from DB: %v, user: %d", err, userID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, models.ExerciseCollectionsFromRows(rows))
+}
+
+// PostCollection godoc
+// @Summary      Create a collection
+// @Description  Create a named collection of exercises, e.g. "home gym". Names are unique per user
+// @Tags         collections
+// @Accept       json
+// @Produce      json
+// @Param        collection	body      models.CollectionRequest  true  "Collection"
+// @Success      201	{object}  models.ExerciseCollection
+// @Failure      400
+// @Failure      401
+// @Failure      404
+// @Failure      409
+// @Failure      500
+// @Router       /api/collections [post]
+func PostCollection(w http.ResponseWriter, r *http.Request) {
+	log.Println("POST /api/collections endpoint called")
+	userID, _ := auth.UserID(r.Context())
+
+	var req models.CollectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Invalid JSON in PostCollection: %v", err)
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	name, err := collectionName(req.Name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(req.ExerciseIds) > 0 {
+		if err := checkExercisesExist(r.Context(), req.ExerciseIds); err != nil {
+			writeExerciseError(w, err)
+			return
+		}
+	}
+
+	tx, err := db.GetPool().Begin(r.Context())
+	if err != nil {
+		log.Printf("Error at beginning transaction: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback(r.Context())
+	qtx := db.Queriez.WithTx(tx)
+
+	row, err := qtx.InsertExerciseCollection(r.Context(), db.InsertExerciseCollectionParams{UserID: userID, Name: name})
+	if errors.Is(err, pgx.ErrNoRows) {
+		http.Error(w, "A collection with this name already exists", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("Error at inserting collection: %v, user: %d", err, userID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	added := make([]int32, 0, len(req.ExerciseIds))
+	seen := make(map[int32]bool, len(req.ExerciseIds))
+	for _, exerciseID := range req.ExerciseIds {
+		if seen[exerciseID] {
+			continue
+		}
+		seen[exerciseID] = true
+		if err := qtx.AddCollectionExercise(r.Context(), db.AddCollectionExerciseParams{CollectionID: row.ID, ExerciseID: exerciseID}); err != nil {
+			log.Printf("Error at inserting collection exercise: %v, collection: %s", err, uuid.UUID(row.ID.Bytes))
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		added = append(added, exerciseID)
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("Error at committing collection: %v, user: %d", err, userID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, models.ExerciseCollectionFromRow(row, added))
+}
+
+// GetCollection godoc
+// @Summary      Get a collection
+// @Description  Get a collection of the authenticated user with the IDs of its exercises, use /api/exercises?collection={id} for the exercises themselves
+// @Tags         collections
+// @Produce      json
+// @Param        id		path      string  	true	"Collection UUID"
+// @Success      200	{object}  models.ExerciseCollection
+// @Failure      400
+// @Failure      401
+// @Failure      404
+// @Failure      500
+// @Router       /api/collections/{id} [get]
+func GetCollection(w http.ResponseWriter, r *http.Request) {
+	log.Println("GET /api/collections/{id} endpoint called")
+
+	row, ok := userCollection(w, r)
+	if !ok {
+		return
+	}
+	writeCollection(w, r, http.StatusOK, row)
+}
+
+// PutCollection godoc
+// @Summary      Rename a collection
+// @Tags         collections
+// @Accept       json
+// @Produce      json
+// @Param        id			path      string  	true	"Collection UUID"
+// @Param        collection	body      models.CollectionRequest  true  "New name of the collection"
+// @Success      200	{object}  models.ExerciseCollection
+// @Failure      400
+// @Failure      401
+// @Failure      404
+// @Failure      409
+// @Failure      500
+// @Router       /api/collections/{id} [put]
+func PutCollection(w http.ResponseWriter, r *http.Request) {
+	log.Println("PUT /api/collections/{id} endpoint called")
+	userID, _ := auth.UserID(r.Context())
+
+	current, ok := userCollection(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.CollectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Invalid JSON in PutCollection: %v", err)
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	name, err := collectionName(req.Name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	row, err := db.Queriez.RenameExerciseCollection(r.Context(), db.RenameExerciseCollectionParams{Name: name, ID: current.ID, UserID: userID})
+	if isUniqueViolation(err) {
+		http.Error(w, "A collection with this name already exists", http.StatusConflict)
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		http.Error(w, "Collection not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error at renaming collection: %v, uuid: %s", err, chi.URLParam(r, "id"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeCollection(w, r, http.StatusOK, row)
+}
+
+// DeleteCollection godoc
+// @Summary      Delete a collection
+// @Description  Delete a collection, the exercises themselves stay
+// @Tags         collections
+// @Param        id		path      string  	true	"Collection UUID"
+// @Success      204
+// @Failure      400
+// @Failure      401
+// @Failure      404
+// @Failure      500
+// @Router       /api/collections/{id} [delete]
+func DeleteCollection(w http.ResponseWriter, r *http.Request) {
+	log.Println("DELETE /api/collections/{id} endpoint called")
+	userID, _ := auth.UserID(r.Context())
+
+	var collection_uuid pgtype.UUID
+	if err := collection_uuid.Scan(chi.URLParam(r, "id")); err != nil {
+		log.Printf("Error scanning UUID Value from URL: %v, uuid: %s", err, chi.URLParam(r, "id"))
+		http.Error(w, "Invalid UUID", http.StatusBadRequest)
+		return
+	}
+
+	deleted, err := db.Queriez.DeleteExerciseCollection(r.Context(), db.DeleteExerciseCollectionParams{ID: collection_uuid, UserID: userID})
+	if err != nil {
+		log.Printf("Error at deleting collection: %v, uuid: %s", err, chi.URLParam(r, "id"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if deleted == 0 {
+		http.Error(w, "Collection not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PutCollectionExercise godoc
+// @Summary      Add an exercise to a collection
+// @Description  Add an exercise to a collection, adding it again does nothing
+// @Tags         collections
+// @Param        id				path      string  	true	"Collection UUID"
+// @Param        exerciseId		path      int  		true	"Exercise ID"
+// @Success      204
+// @Failure      400
+// @Failure      401
+// @Failure      404
+// @Failure      500
+// @Router       /api/collections/{id}/exercises/{exerciseId} [put]
+func PutCollectionExercise(w http.ResponseWriter, r *http.Request) {
+	log.Println("PUT /api/collections/{id}/exercises/{exerciseId} endpoint called")
+
+	collection, ok := userCollection(w, r)
+	if !ok {
+		return
+	}
+	exerciseID, ok := exerciseParam(w, r)
+	if !ok {
+		return
+	}
+	if err := checkExercisesExist(r.Context(), []int32{exerciseID}); err != nil {
+		writeExerciseError(w, err)
+		return
+	}
+
+	if err := db.Queriez.AddCollectionExercise(r.Context(), db.AddCollectionExerciseParams{CollectionID: collection.ID, ExerciseID: exerciseID}); err != nil {
+		log.Printf("Error at inserting collection exercise: %v, uuid: %s", err, chi.URLParam(r, "id"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteCollectionExercise godoc
+// @Summary      Remove an exercise from a collection
+// @Tags         collections
+// @Param        id				path      string  	true	"Collection UUID"
+// @Param        exerciseId		path      int  		true	"Exercise ID"
+// @Success      204
+// @Failure      400
+// @Failure      401
+// @Failure      404
+// @Failure      500
+// @Router       /api/collections/{id}/exercises/{exerciseId} [delete]
+func DeleteCollectionExercise(w http.ResponseWriter, r *http.Request) {
+	log.Println("DELETE /api/collections/{id}/exercises/{exerciseId} endpoint called")
+
+	collection, ok := userCollection(w, r)
+	if !ok {
+		return
+	}
+	exerciseID, ok := exerciseParam(w, r)
+	if !ok {
+		return
+	}
+
+	deleted, err := db.Queriez.RemoveCollectionExercise(r.Context(), db.RemoveCollectionExerciseParams{CollectionID: collection.ID, ExerciseID: exerciseID})
+	if err != nil {
+		log.Printf("Error at deleting collection exercise: %v, uuid: %s", err, chi.URLParam(r, "id"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if deleted == 0 {
+		http.Error(w, "Exercise not in collection", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// applyCollectionFilter narrows the exercise query down to the collection
+// named by the collection query parameter: "favorites" or the UUID of a
+// collection of the user. Collections are private, so both need a user. It
+// writes the error response itself.
+func applyCollectionFilter(w http.ResponseWriter, r *http.Request, exercises repository.Exercises, params *db.GetExercisesParams) bool {
+	collection := r.URL.Query().Get("collection")
+	if collection == "" {
+		return true
+	}
+
+	err := collectionFilter(r.Context(), exercises, collection, params)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, errCollectionNeedsUser):
+		http.Error(w, "Collections need an authenticated user", http.StatusUnauthorized)
+	case errors.Is(err, errInvalidCollection):
+		http.Error(w, "Invalid collection, expected favorites or a collection UUID", http.StatusBadRequest)
+	case errors.Is(err, pgx.ErrNoRows):
+		http.Error(w, "Collection not found", http.StatusNotFound)
+	default:
+		log.Printf("Error at GETting collection from DB: %v, uuid: %s", err, collection)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+	return false
+}
+
+var (
+	errCollectionNeedsUser = errors.New("collections need an authenticated user")
+	errInvalidCollection   = errors.New("invalid collection, expected favorites or a collection UUID")
+)
+
+// collectionFilter sets the collection filter of params for the favorites
+// or a collection of the caller. Collections of other users are reported
+// as pgx.ErrNoRows.
+func collectionFilter(ctx context.Context, exercises repository.Exercises, collection string, params *db.GetExercisesParams) error {
+	userID, ok := auth.UserID(ctx)
+	if !ok {
+		return errCollectionNeedsUser
+	}
+	if collection == FavoritesCollection {
+		params.FavoritesOf = userID
+		return nil
+	}
+
+	var collection_uuid pgtype.UUID
+	if err := collection_uuid.Scan(collection); err != nil {
+		return errInvalidCollection
+	}
+	if _, err := exercises.GetExerciseCollection(ctx, collection_uuid, userID); err != nil {
+		return err
+	}
+	params.CollectionID = collection_uuid
+	return nil
+}
+
+// userCollection loads the collection in the id URL parameter, which has to
+// belong to the caller. Collections of other users are reported as not
+// found.
+func userCollection(w http.ResponseWriter, r *http.Request) (db.ExerciseCollection, bool) {
+	userID, _ := auth.UserID(r.Context())
+
+	var collection_uuid pgtype.UUID
+	if err := collection_uuid.Scan(chi.URLParam(r, "id")); err != nil {
+		log.Printf("Error scanning UUID Value from URL: %v, uuid: %s", err, chi.URLParam(r, "id"))
+		http.Error(w, "Invalid UUID", http.StatusBadRequest)
+		return db.ExerciseCollection{}, false
+	}
+
+	row, err := db.Queriez.GetExerciseCollection(r.Context(), db.GetExerciseCollectionParams{ID: collection_uuid, UserID: userID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		http.Error(w, "Collection not found", http.StatusNotFound)
+		return db.ExerciseCollection{}, false
+	}
+	if err != nil {
+		log.Printf("Error at GETting collection from DB: %v, uuid: %s", err, chi.URLParam(r, "id"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return db.ExerciseCollection{}, false
+	}
+	return row, true
+}
+
+func writeCollection(w http.ResponseWriter, r *http.Request, status int, row db.ExerciseCollection) {
+	exerciseIds, err := db.Queriez.GetCollectionExerciseIds(r.Context(), row.ID)
+	if err != nil {
+		log.Printf("Error at GETting collection exercises from DB: %v, collection: %s", err, uuid.UUID(row.ID.Bytes))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, status, models.ExerciseCollectionFromRow(row, exerciseIds))
+}
+
+func collectionName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", errors.New("name is required")
+	}
+	if len([]rune(name)) > maxCollectionName {
+		return "", fmt.Errorf("name is longer than %d characters", maxCollectionName)
+	}
+	return name, nil
+}
+
+func exerciseParam(w http.ResponseWriter, r *http.Request) (int32, bool) {
+	exerciseID, err := strconv.ParseInt(chi.URLParam(r, "exerciseId"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid exercise ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return int32(exerciseID), true
+}
+
+// errUnknownExercise is returned by checkExercisesExist for IDs that don't
+// match an exercise.
+var errUnknownExercise = errors.New("exercise not found")
+
+func checkExercisesExist(ctx context.Context, ids []int32) error {
+	rows, err := db.Queriez.GetExerciseCategories(ctx, db.GetExerciseCategoriesParams{ExerciseIds: ids, ViewerID: viewerID(ctx)})
+	if err != nil {
+		return fmt.Errorf("couldn't fetch exercises: %w", err)
+	}
+	found := make(map[int32]bool, len(rows))
+	for _, row := range rows {
+		found[row.ID] = true
+	}
+	for _, id := range ids {
+		if !found[id] {
+			return fmt.Errorf("%w: %d", errUnknownExercise, id)
+		}
+	}
+	return nil
+}
+
+func writeExerciseError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errUnknownExercise) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	log.Printf("Error at checking exercises: %v", err)
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
+
+// isUniqueViolation reports whether err is a unique constraint violation.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
@@ -0,0 +1,555 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/auth"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/models"
+)
+
+// GetCustomExercises godoc
+// @Summary      List custom exercises
+// @Description  Get the exercises the authenticated user created, with the status of their latest submission
+// @Tags         custom exercises
+// @Produce      json
+// @Success      200	{array}  models.CustomExercise
+// @Failure      401
+// @Failure      500
+// @Router       /api/exercises/custom [get]
+func GetCustomExercises(w http.ResponseWriter, r *http.Request) {
+	log.Println("GET /api/exercises/custom endpoint called")
+	userID, _ := auth.UserID(r.Context())
+
+	rows, err := db.Queriez.GetCustomExercises(r.Context(), db.GetCustomExercisesParams{OwnerID: userID})
+	if err != nil {
+		log.Printf("Error at GETting custom exercises from DB: %v, user: %d", err, userID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, models.CustomExercisesFromRows(rows))
+}
+
+// PostCustomExercise godoc
+// @Summary      Create a custom exercise
+// @Description  Create an exercise that only the user and their clients see. It can be used in programs like a catalog exercise
+// @Tags         custom exercises
+// @Accept       json
+// @Produce      json
+// @Param        exercise	body      models.CustomExerciseRequest  true  "Exercise"
+// @Success      201	{object}  models.CustomExercise
+// @Failure      400
+// @Failure      401
+// @Failure      500
+// @Router       /api/exercises/custom [post]
+func PostCustomExercise(w http.ResponseWriter, r *http.Request) {
+	log.Println("POST /api/exercises/custom endpoint called")
+	userID, _ := auth.UserID(r.Context())
+
+	req, muscles, ok := customExerciseRequest(w, r)
+	if !ok {
+		return
+	}
+
+	tx, err := db.GetPool().Begin(r.Context())
+	if err != nil {
+		log.Printf("Error at beginning transaction: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback(r.Context())
+	qtx := db.Queriez.WithTx(tx)
+
+	visualsID, err := insertVisuals(r.Context(), qtx, req.Visuals)
+	if err != nil {
+		log.Printf("Error at inserting visuals: %v, user: %d", err, userID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	id, err := qtx.InsertCustomExercise(r.Context(), db.InsertCustomExerciseParams{
+		OwnerID:      userID,
+		Equipment:    req.Equipment,
+		Category:     nullCategory(req.Category),
+		Level:        nullLevel(req.Level),
+		Mechanic:     nullMechanic(req.Mechanic),
+		Force:        nullForce(req.Force),
+		Instructions: req.Instructions,
+		VisualsID:    visualsID,
+	})
+	if err != nil {
+		log.Printf("Error at inserting custom exercise: %v, user: %d", err, userID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if err := insertExerciseDetails(r.Context(), qtx, id, req, muscles); err != nil {
+		log.Printf("Error at inserting custom exercise details: %v, exercise: %d", err, id)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("Error at committing custom exercise: %v, user: %d", err, userID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeCustomExercise(w, r, http.StatusCreated, id)
+}
+
+// GetCustomExercise godoc
+// @Summary      Get a custom exercise
+// @Tags         custom exercises
+// @Produce      json
+// @Param        id		path      int  	true	"Exercise ID"
+// @Success      200	{object}  models.CustomExercise
+// @Failure      400
+// @Failure      401
+// @Failure      404
+// @Failure      500
+// @Router       /api/exercises/custom/{id} [get]
+func GetCustomExercise(w http.ResponseWriter, r *http.Request) {
+	log.Println("GET /api/exercises/custom/{id} endpoint called")
+
+	row, ok := customExercise(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, models.CustomExerciseFromRow(row))
+}
+
+// PutCustomExercise godoc
+// @Summary      Replace a custom exercise
+// @Description  Replace the names, muscles, attributes, instructions and visuals of a custom exercise. Programs using it show the new version
+// @Tags         custom exercises
+// @Accept       json
+// @Produce      json
+// @Param        id			path      int  	true	"Exercise ID"
+// @Param        exercise	body      models.CustomExerciseRequest  true  "Exercise"
+// @Success      200	{object}  models.CustomExercise
+// @Failure      400
+// @Failure      401
+// @Failure      404
+// @Failure      500
+// @Router       /api/exercises/custom/{id} [put]
+func PutCustomExercise(w http.ResponseWriter, r *http.Request) {
+	log.Println("PUT /api/exercises/custom/{id} endpoint called")
+	userID, _ := auth.UserID(r.Context())
+
+	current, ok := customExercise(w, r)
+	if !ok {
+		return
+	}
+	req, muscles, ok := customExerciseRequest(w, r)
+	if !ok {
+		return
+	}
+
+	tx, err := db.GetPool().Begin(r.Context())
+	if err != nil {
+		log.Printf("Error at beginning transaction: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback(r.Context())
+	qtx := db.Queriez.WithTx(tx)
+
+	visualsID, err := insertVisuals(r.Context(), qtx, req.Visuals)
+	if err != nil {
+		log.Printf("Error at inserting visuals: %v, exercise: %d", err, current.ID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	updated, err := qtx.UpdateCustomExercise(r.Context(), db.UpdateCustomExerciseParams{
+		Equipment:    req.Equipment,
+		Category:     nullCategory(req.Category),
+		Level:        nullLevel(req.Level),
+		Mechanic:     nullMechanic(req.Mechanic),
+		Force:        nullForce(req.Force),
+		Instructions: req.Instructions,
+		VisualsID:    visualsID,
+		ID:           current.ID,
+		OwnerID:      userID,
+	})
+	if err != nil {
+		log.Printf("Error at updating custom exercise: %v, exercise: %d", err, current.ID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if updated == 0 {
+		http.Error(w, "Exercise not found", http.StatusNotFound)
+		return
+	}
+	if current.VisualsID.Valid {
+		if err := qtx.DeleteVisual(r.Context(), current.VisualsID.Int32); err != nil {
+			log.Printf("Error at deleting visuals: %v, exercise: %d", err, current.ID)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+	if err := qtx.DeleteExerciseNames(r.Context(), current.ID); err != nil {
+		log.Printf("Error at deleting exercise names: %v, exercise: %d", err, current.ID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if err := qtx.DeleteExerciseMuscles(r.Context(), current.ID); err != nil {
+		log.Printf("Error at deleting exercise muscles: %v, exercise: %d", err, current.ID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if err := insertExerciseDetails(r.Context(), qtx, current.ID, req, muscles); err != nil {
+		log.Printf("Error at inserting custom exercise details: %v, exercise: %d", err, current.ID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("Error at committing custom exercise: %v, exercise: %d", err, current.ID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeCustomExercise(w, r, http.StatusOK, current.ID)
+}
+
+// DeleteCustomExercise godoc
+// @Summary      Delete a custom exercise
+// @Description  Delete a custom exercise that no program or logged workout uses
+// @Tags         custom exercises
+// @Param        id		path      int  	true	"Exercise ID"
+// @Success      204
+// @Failure      400
+// @Failure      401
+// @Failure      404
+// @Failure      409
+// @Failure      500
+// @Router       /api/exercises/custom/{id} [delete]
+func DeleteCustomExercise(w http.ResponseWriter, r *http.Request) {
+	log.Println("DELETE /api/exercises/custom/{id} endpoint called")
+	userID, _ := auth.UserID(r.Context())
+
+	current, ok := customExercise(w, r)
+	if !ok {
+		return
+	}
+
+	tx, err := db.GetPool().Begin(r.Context())
+	if err != nil {
+		log.Printf("Error at beginning transaction: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback(r.Context())
+	qtx := db.Queriez.WithTx(tx)
+
+	if err := qtx.DeleteExerciseNames(r.Context(), current.ID); err != nil {
+		log.Printf("Error at deleting exercise names: %v, exercise: %d", err, current.ID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if err := qtx.DeleteExerciseMuscles(r.Context(), current.ID); err != nil {
+		log.Printf("Error at deleting exercise muscles: %v, exercise: %d", err, current.ID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	deleted, err := qtx.DeleteCustomExercise(r.Context(), db.DeleteCustomExerciseParams{ID: current.ID, OwnerID: userID})
+	if isForeignKeyViolation(err) {
+		http.Error(w, "Exercise is used in programs or workouts", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("Error at deleting custom exercise: %v, exercise: %d", err, current.ID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if deleted == 0 {
+		http.Error(w, "Exercise not found", http.StatusNotFound)
+		return
+	}
+	if current.VisualsID.Valid {
+		if err := qtx.DeleteVisual(r.Context(), current.VisualsID.Int32); err != nil {
+			log.Printf("Error at deleting visuals: %v, exercise: %d", err, current.ID)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+	// The owner's clients may have synced it as well
+	if err := qtx.InsertSyncTombstone(r.Context(), db.InsertSyncTombstoneParams{
+		Entity:   models.SyncEntityExercise,
+		EntityID: strconv.Itoa(int(current.ID)),
+	}); err != nil {
+		log.Printf("Error at inserting sync tombstone: %v, exercise: %d", err, current.ID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("Error at committing custom exercise deletion: %v, exercise: %d", err, current.ID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PostExerciseSubmission godoc
+// @Summary      Submit a custom exercise
+// @Description  Propose a custom exercise for the public catalog, or start a draft of the submission with draft. An exercise has at most one open submission
+// @Tags         custom exercises
+// @Accept       json
+// @Produce      json
+// @Param        id				path      int  	true	"Exercise ID"
+// @Param        submission		body      models.SubmitExerciseRequest  false  "Note for the reviewers"
+// @Success      201	{object}  models.ExerciseSubmission
+// @Failure      400
+// @Failure      401
+// @Failure      404
+// @Failure      409
+// @Failure      500
+// @Router       /api/exercises/custom/{id}/submissions [post]
+func PostExerciseSubmission(w http.ResponseWriter, r *http.Request) {
+	log.Println("POST /api/exercises/custom/{id}/submissions endpoint called")
+	userID, _ := auth.UserID(r.Context())
+
+	current, ok := customExercise(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.SubmitExerciseRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Printf("Invalid JSON in PostExerciseSubmission: %v", err)
+			http.Error(w, "Invalid JSON", http.StatusBadRequest)
+			return
+		}
+	}
+
+	snapshot, err := json.Marshal(models.CustomExerciseFromRow(current).Request())
+	if err != nil {
+		log.Printf("Error at Marshaling submission snapshot: %v, exercise: %d", err, current.ID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	status := db.SubmissionStatusTPending
+	if req.Draft {
+		status = db.SubmissionStatusTDraft
+	}
+
+	row, err := db.Queriez.InsertExerciseSubmission(r.Context(), db.InsertExerciseSubmissionParams{
+		ExerciseID: current.ID,
+		UserID:     userID,
+		Note:       strings.TrimSpace(req.Note),
+		Status:     status,
+		Snapshot:   snapshot,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		http.Error(w, "Exercise already has an open submission", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("Error at inserting exercise submission: %v, exercise: %d", err, current.ID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, models.ExerciseSubmissionFromRow(row))
+}
+
+// GetExerciseSubmissions godoc
+// @Summary      List the submissions of a custom exercise
+// @Tags         custom exercises
+// @Produce      json
+// @Param        id		path      int  	true	"Exercise ID"
+// @Success      200	{array}  models.ExerciseSubmission
+// @Failure      400
+// @Failure      401
+// @Failure      404
+// @Failure      500
+// @Router       /api/exercises/custom/{id}/submissions [get]
+func GetExerciseSubmissions(w http.ResponseWriter, r *http.Request) {
+	log.Println("GET /api/exercises/custom/{id}/submissions endpoint called")
+
+	current, ok := customExercise(w, r)
+	if !ok {
+		return
+	}
+
+	rows, err := db.Queriez.GetExerciseSubmissions(r.Context(), current.ID)
+	if err != nil {
+		log.Printf("Error at GETting exercise submissions from DB: %v, exercise: %d", err, current.ID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, models.ExerciseSubmissionsFromRows(rows))
+}
+
+// viewerID is the user custom exercises are filtered for: catalog
+// exercises are visible to everybody, custom ones to their owner and the
+// clients of their owner.
+func viewerID(ctx context.Context) pgtype.Int8 {
+	userID, ok := auth.UserID(ctx)
+	return pgtype.Int8{Int64: userID, Valid: ok}
+}
+
+// customExercise loads the custom exercise in the id URL parameter, which
+// has to belong to the caller.
+func customExercise(w http.ResponseWriter, r *http.Request) (db.GetCustomExercisesRow, bool) {
+	userID, _ := auth.UserID(r.Context())
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid exercise ID", http.StatusBadRequest)
+		return db.GetCustomExercisesRow{}, false
+	}
+
+	rows, err := db.Queriez.GetCustomExercises(r.Context(), db.GetCustomExercisesParams{OwnerID: userID, ExerciseID: id})
+	if err != nil {
+		log.Printf("Error at GETting custom exercise from DB: %v, exercise: %d", err, id)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return db.GetCustomExercisesRow{}, false
+	}
+	if len(rows) == 0 {
+		http.Error(w, "Exercise not found", http.StatusNotFound)
+		return db.GetCustomExercisesRow{}, false
+	}
+	return rows[0], true
+}
+
+func writeCustomExercise(w http.ResponseWriter, r *http.Request, status int, id int32) {
+	userID, _ := auth.UserID(r.Context())
+	rows, err := db.Queriez.GetCustomExercises(r.Context(), db.GetCustomExercisesParams{OwnerID: userID, ExerciseID: id})
+	if err != nil || len(rows) == 0 {
+		log.Printf("Error at GETting custom exercise from DB: %v, exercise: %d", err, id)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, status, models.CustomExerciseFromRow(rows[0]))
+}
+
+// customExerciseRequest decodes and checks a custom exercise, resolving
+// its muscle names to catalog muscles.
+func customExerciseRequest(w http.ResponseWriter, r *http.Request) (models.CustomExerciseRequest, map[string]int32, bool) {
+	var req models.CustomExerciseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Invalid JSON in custom exercise: %v", err)
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return req, nil, false
+	}
+	if err := req.Normalize(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return req, nil, false
+	}
+
+	muscles, err := resolveMuscles(r.Context(), req)
+	if errors.Is(err, errUnknownMuscle) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return req, nil, false
+	}
+	if err != nil {
+		log.Printf("Error at GETting the muscles from DB: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return req, nil, false
+	}
+	return req, muscles, true
+}
+
+var errUnknownMuscle = errors.New("unknown muscle")
+
+// resolveMuscles maps the muscles of an exercise to the IDs of catalog
+// muscles, ignoring case.
+func resolveMuscles(ctx context.Context, req models.CustomExerciseRequest) (map[string]int32, error) {
+	rows, err := db.Queriez.GetMuscles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]int32, len(rows))
+	for _, row := range rows {
+		known[strings.ToLower(strings.TrimSpace(row.Name.String))] = row.ID
+	}
+
+	muscles := make(map[string]int32, len(req.Muscles)+len(req.SecondaryMuscles))
+	for _, name := range append(append([]string{}, req.Muscles...), req.SecondaryMuscles...) {
+		id, ok := known[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("%w %q", errUnknownMuscle, name)
+		}
+		muscles[name] = id
+	}
+	return muscles, nil
+}
+
+// insertVisuals stores the visuals of an exercise in one visuals row, the
+// way the catalog does.
+func insertVisuals(ctx context.Context, q *db.Queries, visuals []string) (pgtype.Int4, error) {
+	if len(visuals) == 0 {
+		return pgtype.Int4{}, nil
+	}
+	id, err := q.InsertToVisuals(ctx, strings.Join(visuals, ","))
+	if err != nil {
+		return pgtype.Int4{}, err
+	}
+	return pgtype.Int4{Int32: id, Valid: true}, nil
+}
+
+// insertExerciseDetails writes the names and muscles of an exercise.
+func insertExerciseDetails(ctx context.Context, q *db.Queries, exerciseID int32, req models.CustomExerciseRequest, muscles map[string]int32) error {
+	for _, name := range req.Names {
+		if _, err := q.InsertToExerciseNames(ctx, db.InsertToExerciseNamesParams{ExerciseID: exerciseID, Name: name}); err != nil {
+			return fmt.Errorf("couldn't insert name %q: %w", name, err)
+		}
+	}
+	for _, names := range []struct {
+		list    []string
+		primary bool
+	}{{req.Muscles, true}, {req.SecondaryMuscles, false}} {
+		for _, name := range names.list {
+			params := db.InsertExerciseMuscleParams{ExerciseID: exerciseID, MuscleID: muscles[name], IsPrimary: names.primary}
+			if err := q.InsertExerciseMuscle(ctx, params); err != nil {
+				return fmt.Errorf("couldn't insert muscle %q: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func nullCategory(c *db.CategoryT) db.NullCategoryT {
+	if c == nil {
+		return db.NullCategoryT{}
+	}
+	return db.NullCategoryT{CategoryT: *c, Valid: true}
+}
+
+func nullLevel(l *db.LevelT) db.NullLevelT {
+	if l == nil {
+		return db.NullLevelT{}
+	}
+	return db.NullLevelT{LevelT: *l, Valid: true}
+}
+
+func nullMechanic(m *db.MechanicT) db.NullMechanicT {
+	if m == nil {
+		return db.NullMechanicT{}
+	}
+	return db.NullMechanicT{MechanicT: *m, Valid: true}
+}
+
+func nullForce(f *db.ForceT) db.NullForceT {
+	if f == nil {
+		return db.NullForceT{}
+	}
+	return db.NullForceT{ForceT: *f, Valid: true}
+}
+
+// isForeignKeyViolation reports whether err is a foreign key violation.
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
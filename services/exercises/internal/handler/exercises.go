@@ -7,7 +7,6 @@ import (
 	"strconv"
 
 	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
-	"github.com/Farzan-kh/guddy-cn/exercises/internal/models"
 )
 
 // GetExercises godoc
@@ -19,12 +18,16 @@ import (
 // @Param        muscle   	query      string  	false  	"Target Muscle(s)"
 // @Param        equipment  query      string  	false	"Equipment required for the Exercise"
 // @Param        name   	query      string  	false  	"Name of the Exercise"
+// @Param        collection	query      string  	false  	"favorites or the UUID of a collection of the user"
 // @Param		 limit		query		int		false	"Limit"
 // @Param		 offset		query		int		false	"Offset"
 // @Success      200	{array}  models.Exercise
+// @Failure      400
+// @Failure      401
+// @Failure      404
 // @Failure      500
 // @Router       /api/exercises [get]
-func GetExercises(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) GetExercises(w http.ResponseWriter, r *http.Request) {
 	log.Println("GET /api/exercises endpoint called")
 
 	query_params := r.URL.Query()
@@ -69,17 +72,19 @@ func GetExercises(w http.ResponseWriter, r *http.Request) {
 	if id != "" {
 		params.ExerciseID = &id
 	}
+	params.ViewerID = viewerID(r.Context())
+	if !applyCollectionFilter(w, r, h.Exercises, &params) {
+		return
+	}
 
 	log.Printf("Fetching exercises with params: %+v", params)
-	exercisesRows, err := db.Queriez.GetExercises(r.Context(), params)
+	exercises, err := h.Exercises.GetExercises(r.Context(), params)
 	if err != nil {
 		log.Printf("Couldn't Fetch exercises from db: %v", err)
 		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
 
-	exercises := models.ExerciseFromRows(exercisesRows)
-
 	exercises_json, err := json.MarshalIndent(exercises, "", "  ")
 	if err != nil {
 		log.Printf("Error at Marshaling exercises objects: %v", err)
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/models"
+)
+
+func TestGetExercises(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		userID int64
+		status int
+		ids    []int32
+	}{
+		{name: "all", target: "/api/exercises", status: http.StatusOK, ids: []int32{1, 2, 3}},
+		{name: "by name", target: "/api/exercises?name=squat", status: http.StatusOK, ids: []int32{2}},
+		{name: "by muscle", target: "/api/exercises?muscle=Chest", status: http.StatusOK, ids: []int32{1}},
+		{name: "by id", target: "/api/exercises?id=3", status: http.StatusOK, ids: []int32{3}},
+		{name: "no match", target: "/api/exercises?name=deadlift", status: http.StatusOK, ids: []int32{}},
+		{name: "limit", target: "/api/exercises?limit=2", status: http.StatusOK, ids: []int32{1, 2}},
+		{name: "offset", target: "/api/exercises?offset=1&limit=1", status: http.StatusOK, ids: []int32{2}},
+		{name: "invalid limit uses the default", target: "/api/exercises?limit=1000", status: http.StatusOK, ids: []int32{1, 2, 3}},
+		{name: "custom exercises of the viewer", target: "/api/exercises", userID: ownerID, status: http.StatusOK, ids: []int32{1, 2, 3, 100}},
+		{name: "custom exercises of others", target: "/api/exercises", userID: otherID, status: http.StatusOK, ids: []int32{1, 2, 3}},
+		{name: "favorites", target: "/api/exercises?collection=favorites", userID: ownerID, status: http.StatusOK, ids: []int32{2}},
+		{name: "favorites need a user", target: "/api/exercises?collection=favorites", status: http.StatusUnauthorized},
+		{name: "collection", target: "/api/exercises?collection=55555555-5555-5555-5555-555555555555", userID: ownerID, status: http.StatusOK, ids: []int32{3}},
+		{name: "collection of another user", target: "/api/exercises?collection=55555555-5555-5555-5555-555555555555", userID: otherID, status: http.StatusNotFound},
+		{name: "missing collection", target: "/api/exercises?collection=44444444-4444-4444-4444-444444444444", userID: ownerID, status: http.StatusNotFound},
+		{name: "invalid collection", target: "/api/exercises?collection=core", userID: ownerID, status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTestStore()
+			h := NewHandler(store, store)
+
+			rec := serve(t, http.MethodGet, "/api/exercises", h.GetExercises, tt.target, "", tt.userID)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var exercises []models.Exercise
+			if err := json.Unmarshal(rec.Body.Bytes(), &exercises); err != nil {
+				t.Fatalf("invalid body: %v", err)
+			}
+			ids := make([]int32, 0, len(exercises))
+			for _, ex := range exercises {
+				ids = append(ids, ex.Id)
+			}
+			if !slices.Equal(ids, tt.ids) {
+				t.Errorf("ids = %v, want %v", ids, tt.ids)
+			}
+		})
+	}
+}
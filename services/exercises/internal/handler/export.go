@@ -0,0 +1,73 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/export"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/repository"
+)
+
+// ExportProgram godoc
+// @Summary      Export a Program
+// @Description  Render a program with its exercise names, prescriptions and instructions as a PDF, a printable HTML page, a CSV sheet or Markdown
+// @Tags         programs
+// @Produce      application/pdf
+// @Produce      text/html
+// @Produce      text/csv
+// @Produce      text/markdown
+// @Param        uuid		path      string  	true	"Programs UUID"
+// @Param        format		query      string  	false	"pdf (default), html, csv or md"
+// @Param        share		query      string  	false	"Share token of a link-only program"
+// @Success      200	{file}  file
+// @Failure      400
+// @Failure      404
+// @Failure      500
+// @Router       /api/program/{uuid}/export [get]
+func (h *Handler) ExportProgram(w http.ResponseWriter, r *http.Request) {
+	log.Println("GET /api/program/{uuid}/export endpoint called")
+	var program_uuid pgtype.UUID
+	if err := program_uuid.Scan(chi.URLParam(r, "uuid")); err != nil {
+		log.Printf("Error scanning UUID Value from URL: %v, uuid: %s", err, chi.URLParam(r, "uuid"))
+		http.Error(w, "Invalid UUID", http.StatusBadRequest)
+		return
+	}
+
+	format, err := export.ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.readableProgram(r, program_uuid); err != nil {
+		writeAccessError(w, err, chi.URLParam(r, "uuid"))
+		return
+	}
+
+	program, err := repository.FetchCompleteProgram(r.Context(), db.Queriez, program_uuid)
+	if err != nil {
+		log.Printf("Error at GETting the program from DB: %v, uuid: %s", err, chi.URLParam(r, "uuid"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// Render to a buffer first so a failure can still be reported
+	var body bytes.Buffer
+	if err := export.Render(&body, format, program); err != nil {
+		log.Printf("Error at rendering program export: %v, uuid: %s, format: %s", err, chi.URLParam(r, "uuid"), format)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Add("Content-Type", format.ContentType())
+	if format != export.HTML {
+		w.Header().Add("Content-Disposition", fmt.Sprintf("attachment; filename=\"program-%s.%s\"", chi.URLParam(r, "uuid"), format))
+	}
+	w.Write(body.Bytes())
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/generator"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/models"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/repository"
+)
+
+// GenerateProgram godoc
+// @Summary      Generate a Program
+// @Description  Build and store a balanced program from the catalog for a goal, schedule, equipment and level. The same seed gives the same program. Programs of an authenticated user start private.
+// @Tags         programs
+// @Accept       json
+// @Produce      json
+// @Param        request	body      models.GenerateProgramRequest  	true	"Goal and constraints"
+// @Success      200	{object}  models.GeneratedProgram
+// @Failure      400
+// @Failure      422
+// @Failure      500
+// @Router       /api/programs/generate [post]
+func GenerateProgram(w http.ResponseWriter, r *http.Request) {
+	log.Println("POST /api/programs/generate endpoint called")
+	var req models.GenerateProgramRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Invalid JSON in GenerateProgram: %v", err)
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	opts, err := generator.NewOptions(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rows, err := db.Queriez.GetGeneratorCandidates(r.Context())
+	if err != nil {
+		log.Printf("Error at GETting the generator candidates from DB: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	records, err := generator.Generate(opts, generator.CandidatesFromRows(rows))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	programID := uuid.New()
+	pg_uuid := pgtype.UUID{Bytes: programID, Valid: true}
+
+	tx, err := db.GetPool().Begin(r.Context())
+	if err != nil {
+		log.Printf("Error at starting transaction: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback(r.Context())
+	qtx := db.Queriez.WithTx(tx)
+
+	log.Printf("Inserting generated program with ID: %s, goal: %s, seed: %d, num_exercises: %d", programID, opts.Goal, opts.Seed, len(records))
+	if err := repository.InsertProgramRecords(r.Context(), qtx, pg_uuid, records); err != nil {
+		log.Printf("Error at inserting program items: %v, program_id: %s", err, programID)
+		http.Error(w, "Error at inserting program items", http.StatusInternalServerError)
+		return
+	}
+	if err := repository.InsertProgramAccess(r.Context(), qtx, pg_uuid, pgtype.UUID{}); err != nil {
+		log.Printf("Error at inserting program access: %v, program_id: %s", err, programID)
+		http.Error(w, "Error at inserting program items", http.StatusInternalServerError)
+		return
+	}
+	if _, err := repository.RecordRevision(r.Context(), qtx, pg_uuid, db.RevisionSourceTGenerated, pgtype.Int4{}); err != nil {
+		log.Printf("Error at recording program revision: %v, program_id: %s", err, programID)
+		http.Error(w, "Error at inserting program items", http.StatusInternalServerError)
+		return
+	}
+
+	program, err := repository.FetchProgram(r.Context(), qtx, pg_uuid)
+	if err != nil {
+		log.Printf("Error at GETting the program from DB: %v, program_id: %s", err, programID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("Error at committing program: %v, program_id: %s", err, programID)
+		http.Error(w, "Error at inserting program items", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, models.GeneratedProgram{
+		Program: *program,
+		Goal:    string(opts.Goal),
+		Seed:    opts.Seed,
+	})
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/graph"
+)
+
+// GraphQL godoc
+// @Summary      GraphQL endpoint
+// @Description  Query exercises, muscles, equipment, programs and the workouts of the authenticated user in a single round trip. Queries nested deeper than 8 levels or more complex than 5000 fields are rejected
+// @Tags         graphql
+// @Accept       json
+// @Produce      json
+// @Param        query		query      string  	false	"Query, for GET requests"
+// @Param        operationName	query      string  	false	"Operation to run, for GET requests"
+// @Param        request	body      graph.Request	false	"Query, variables and operation name, for POST requests"
+// @Success      200
+// @Failure      400
+// @Failure      500
+// @Router       /api/graphql [post]
+func (h *Handler) GraphQL(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s /api/graphql endpoint called", r.Method)
+
+	var req graph.Request
+	if r.Method == http.MethodGet {
+		req.Query = r.URL.Query().Get("query")
+		req.OperationName = r.URL.Query().Get("operationName")
+		if variables := r.URL.Query().Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				http.Error(w, "Invalid variables", http.StatusBadRequest)
+				return
+			}
+		}
+	} else if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Invalid JSON in GraphQL: %v", err)
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if req.Query == "" {
+		http.Error(w, "query is required", http.StatusBadRequest)
+		return
+	}
+
+	schema, err := h.graphSchema()
+	if err != nil {
+		log.Printf("Error at building the GraphQL schema: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, schema.Do(r.Context(), req))
+}
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/estimate"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/models"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/pb"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/repository"
+)
+
+// ExercisesServer serves the gRPC API of the exercises service. It runs the
+// same queries and checks as the HTTP endpoints it mirrors, through the
+// same repositories as Handler.
+type ExercisesServer struct {
+	pb.UnimplementedExercisesServer
+	Exercises repository.Exercises
+	Programs  repository.Programs
+}
+
+func NewExercisesServer(exercises repository.Exercises, programs repository.Programs) *ExercisesServer {
+	return &ExercisesServer{Exercises: exercises, Programs: programs}
+}
+
+func (s *ExercisesServer) GetExercises(ctx context.Context, req *pb.GetExercisesRequest) (*pb.GetExercisesResponse, error) {
+	log.Println("gRPC GetExercises called")
+
+	params := db.GetExercisesParams{
+		Limit:    50,
+		Offset:   max(int(req.Offset), 0),
+		ViewerID: viewerID(ctx),
+	}
+	if req.Limit > 0 && req.Limit <= 100 {
+		params.Limit = int(req.Limit)
+	}
+	if req.Id != nil {
+		params.ExerciseID = *req.Id
+	}
+	if req.Name != nil && *req.Name != "" {
+		params.Name = *req.Name
+	}
+	if req.Equipment != nil && *req.Equipment != "" {
+		if !models.IsEquipment(db.EquipmentT(*req.Equipment)) {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown equipment %q", *req.Equipment)
+		}
+		params.Equipment = db.EquipmentT(*req.Equipment)
+	}
+	if req.Muscle != nil && *req.Muscle != "" {
+		params.Muscle = *req.Muscle
+	}
+	if req.Collection != nil && *req.Collection != "" {
+		err := collectionFilter(ctx, s.Exercises, *req.Collection, &params)
+		switch {
+		case errors.Is(err, errCollectionNeedsUser):
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		case errors.Is(err, errInvalidCollection):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.Is(err, pgx.ErrNoRows):
+			return nil, status.Error(codes.NotFound, "collection not found")
+		case err != nil:
+			log.Printf("Error at GETting collection from DB: %v, uuid: %s", err, *req.Collection)
+			return nil, status.Error(codes.Internal, codes.Internal.String())
+		}
+	}
+
+	exercises, err := s.Exercises.GetExercises(ctx, params)
+	if err != nil {
+		log.Printf("Couldn't Fetch exercises from db: %v", err)
+		return nil, status.Error(codes.Internal, codes.Internal.String())
+	}
+
+	res := &pb.GetExercisesResponse{Exercises: make([]*pb.Exercise, 0, len(exercises))}
+	for _, ex := range exercises {
+		res.Exercises = append(res.Exercises, exerciseToPB(ex))
+	}
+	return res, nil
+}
+
+func (s *ExercisesServer) GetProgram(ctx context.Context, req *pb.GetProgramRequest) (*pb.Program, error) {
+	log.Println("gRPC GetProgram called")
+	program_uuid, err := readableProgramRequest(ctx, s.Programs, req)
+	if err != nil {
+		return nil, err
+	}
+
+	program, err := s.Programs.GetProgram(ctx, program_uuid)
+	if err != nil {
+		log.Printf("Error at GETting the program from DB: %v, uuid: %s", err, req.Uuid)
+		return nil, status.Error(codes.Internal, codes.Internal.String())
+	}
+
+	res := &pb.Program{Uuid: program.UUID.String(), Exercises: make([]*pb.ProgramRecord, 0, len(program.Exercises))}
+	for _, rec := range program.Exercises {
+		res.Exercises = append(res.Exercises, recordToPB(rec))
+	}
+	return res, nil
+}
+
+func (s *ExercisesServer) GetCompleteProgram(ctx context.Context, req *pb.GetProgramRequest) (*pb.CompleteProgram, error) {
+	log.Println("gRPC GetCompleteProgram called")
+	program_uuid, err := readableProgramRequest(ctx, s.Programs, req)
+	if err != nil {
+		return nil, err
+	}
+
+	program, err := s.Programs.GetCompleteProgram(ctx, program_uuid)
+	if err != nil {
+		log.Printf("Error at GETting the program from DB: %v, uuid: %s", err, req.Uuid)
+		return nil, status.Error(codes.Internal, codes.Internal.String())
+	}
+
+	res := &pb.CompleteProgram{
+		Uuid:      program.UUID.String(),
+		Exercises: make([]*pb.ProgramExercise, 0, len(program.Exercises)),
+		Duration:  durationToPB(estimate.Program(program.Records())),
+	}
+	for _, ex := range program.Exercises {
+		res.Exercises = append(res.Exercises, &pb.ProgramExercise{
+			Exercise:      exerciseToPB(ex.Exercise),
+			Idx:           int32(ex.Idx),
+			Day:           int32(ex.Day),
+			Sets:          int32(ex.Sets),
+			Reps:          int32(ex.Reps),
+			Prescriptions: prescriptionsToPB(ex.Prescriptions),
+		})
+	}
+	return res, nil
+}
+
+func (s *ExercisesServer) PostProgram(ctx context.Context, req *pb.PostProgramRequest) (*pb.PostProgramResponse, error) {
+	log.Println("gRPC PostProgram called")
+	records := make([]models.ProgramRecord, 0, len(req.Exercises))
+	for _, rec := range req.Exercises {
+		records = append(records, recordFromPB(rec))
+	}
+
+	if err := validateProgramRecords(ctx, s.Exercises, records); err != nil {
+		log.Printf("Invalid program in PostProgram: %v", err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	uuid, err := createProgram(ctx, s.Programs, records)
+	if err != nil {
+		log.Printf("Error at creating program: %v, program_id: %s", err, uuid.String())
+		return nil, status.Error(codes.Internal, "error at inserting program items")
+	}
+	return &pb.PostProgramResponse{ProgramId: uuid.String()}, nil
+}
+
+// readableProgramRequest parses the program of the request and checks the
+// caller can read it, mapping failures to gRPC status errors.
+func readableProgramRequest(ctx context.Context, programs repository.Programs, req *pb.GetProgramRequest) (pgtype.UUID, error) {
+	var program_uuid pgtype.UUID
+	if err := program_uuid.Scan(req.Uuid); err != nil {
+		return program_uuid, status.Error(codes.InvalidArgument, "invalid UUID")
+	}
+
+	if _, err := readableProgramFrom(ctx, programs, program_uuid, req.Share); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return program_uuid, status.Error(codes.NotFound, "program not found")
+		}
+		log.Printf("Error at GETting the program access from DB: %v, uuid: %s", err, req.Uuid)
+		return program_uuid, status.Error(codes.Internal, codes.Internal.String())
+	}
+	return program_uuid, nil
+}
+
+func exerciseToPB(ex models.Exercise) *pb.Exercise {
+	return &pb.Exercise{
+		Id:           ex.Id,
+		Names:        ex.Names,
+		Muscles:      ex.Muscles,
+		Equipment:    string(ex.Equipment),
+		Visuals:      ex.Visuals,
+		OwnerId:      ex.OwnerID,
+		Instructions: ex.Instructions,
+	}
+}
+
+func recordToPB(rec models.ProgramRecord) *pb.ProgramRecord {
+	return &pb.ProgramRecord{
+		ExerciseId:    int32(rec.ExerciseId),
+		Idx:           int32(rec.Idx),
+		Day:           int32(rec.Day),
+		Sets:          int32(rec.Sets),
+		Reps:          int32(rec.Reps),
+		Prescriptions: prescriptionsToPB(rec.Prescriptions),
+	}
+}
+
+func recordFromPB(rec *pb.ProgramRecord) models.ProgramRecord {
+	prescriptions := make([]models.SetPrescription, 0, len(rec.Prescriptions))
+	for _, s := range rec.Prescriptions {
+		prescriptions = append(prescriptions, models.SetPrescription{
+			Reps:            intPtr(s.Reps),
+			WeightKg:        s.WeightKg,
+			OneRmPercent:    s.OneRmPercent,
+			RPE:             s.Rpe,
+			RIR:             intPtr(s.Rir),
+			Tempo:           s.Tempo,
+			RestSeconds:     intPtr(s.RestSeconds),
+			DurationSeconds: intPtr(s.DurationSeconds),
+			DistanceMeters:  s.DistanceMeters,
+		})
+	}
+	return models.ProgramRecord{
+		ExerciseId:    int(rec.ExerciseId),
+		Idx:           int(rec.Idx),
+		Day:           int(rec.Day),
+		Sets:          int(rec.Sets),
+		Reps:          int(rec.Reps),
+		Prescriptions: prescriptions,
+	}
+}
+
+func prescriptionsToPB(prescriptions []models.SetPrescription) []*pb.SetPrescription {
+	res := make([]*pb.SetPrescription, 0, len(prescriptions))
+	for _, s := range prescriptions {
+		res = append(res, &pb.SetPrescription{
+			Reps:            int32Ptr(s.Reps),
+			WeightKg:        s.WeightKg,
+			OneRmPercent:    s.OneRmPercent,
+			Rpe:             s.RPE,
+			Rir:             int32Ptr(s.RIR),
+			Tempo:           s.Tempo,
+			RestSeconds:     int32Ptr(s.RestSeconds),
+			DurationSeconds: int32Ptr(s.DurationSeconds),
+			DistanceMeters:  s.DistanceMeters,
+		})
+	}
+	return res
+}
+
+func durationToPB(d models.ProgramDuration) *pb.ProgramDuration {
+	res := &pb.ProgramDuration{
+		Sessions:              make([]*pb.SessionDuration, 0, len(d.Sessions)),
+		TotalSeconds:          int32(d.TotalSeconds),
+		TotalMinutes:          int32(d.TotalMinutes),
+		LongestSessionMinutes: int32(d.LongestSessionMinutes),
+		AverageSessionMinutes: int32(d.AverageSessionMinutes),
+	}
+	for _, s := range d.Sessions {
+		res.Sessions = append(res.Sessions, &pb.SessionDuration{Day: int32(s.Day), Seconds: int32(s.Seconds), Minutes: int32(s.Minutes)})
+	}
+	return res
+}
+
+func intPtr(v *int32) *int {
+	if v == nil {
+		return nil
+	}
+	i := int(*v)
+	return &i
+}
+
+func int32Ptr(v *int) *int32 {
+	if v == nil {
+		return nil
+	}
+	i := int32(*v)
+	return &i
+}
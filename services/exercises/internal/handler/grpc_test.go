@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/auth"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/pb"
+)
+
+func TestExercisesServerGetExercises(t *testing.T) {
+	core := "55555555-5555-5555-5555-555555555555"
+	tests := []struct {
+		name   string
+		req    *pb.GetExercisesRequest
+		userID int64
+		code   codes.Code
+		ids    []int32
+	}{
+		{name: "all", req: &pb.GetExercisesRequest{}, ids: []int32{1, 2, 3}},
+		{name: "collection", req: &pb.GetExercisesRequest{Collection: &core}, userID: ownerID, ids: []int32{3}},
+		{name: "collection of another user", req: &pb.GetExercisesRequest{Collection: &core}, userID: otherID, code: codes.NotFound},
+		{name: "collection needs a user", req: &pb.GetExercisesRequest{Collection: &core}, code: codes.Unauthenticated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTestStore()
+			s := NewExercisesServer(store, store)
+
+			ctx := context.Background()
+			if tt.userID != 0 {
+				ctx = auth.WithUserID(ctx, tt.userID)
+			}
+			res, err := s.GetExercises(ctx, tt.req)
+			if code := status.Code(err); code != tt.code {
+				t.Fatalf("code = %s, want %s: %v", code, tt.code, err)
+			}
+			if err != nil {
+				return
+			}
+
+			ids := make([]int32, 0, len(res.Exercises))
+			for _, ex := range res.Exercises {
+				ids = append(ids, ex.Id)
+			}
+			if !slices.Equal(ids, tt.ids) {
+				t.Errorf("ids = %v, want %v", ids, tt.ids)
+			}
+		})
+	}
+}
+
+func TestExercisesServerGetProgram(t *testing.T) {
+	tests := []struct {
+		name   string
+		req    *pb.GetProgramRequest
+		userID int64
+		code   codes.Code
+	}{
+		{name: "invalid uuid", req: &pb.GetProgramRequest{Uuid: "not-a-uuid"}, code: codes.InvalidArgument},
+		{name: "public", req: &pb.GetProgramRequest{Uuid: "11111111-1111-1111-1111-111111111111"}},
+		{name: "private anonymous", req: &pb.GetProgramRequest{Uuid: "22222222-2222-2222-2222-222222222222"}, code: codes.NotFound},
+		{name: "private owner", req: &pb.GetProgramRequest{Uuid: "22222222-2222-2222-2222-222222222222"}, userID: ownerID},
+		{name: "link with token", req: &pb.GetProgramRequest{Uuid: "33333333-3333-3333-3333-333333333333", Share: "secret"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTestStore()
+			s := NewExercisesServer(store, store)
+
+			ctx := context.Background()
+			if tt.userID != 0 {
+				ctx = auth.WithUserID(ctx, tt.userID)
+			}
+			res, err := s.GetProgram(ctx, tt.req)
+			if code := status.Code(err); code != tt.code {
+				t.Fatalf("code = %s, want %s: %v", code, tt.code, err)
+			}
+			if err == nil && len(res.Exercises) != 2 {
+				t.Errorf("items = %+v, want 2", res.Exercises)
+			}
+		})
+	}
+}
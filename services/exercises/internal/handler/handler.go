@@ -0,0 +1,27 @@
+package service
+
+import (
+	"sync"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/graph"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/repository"
+)
+
+// Handler serves the endpoints that reach exercises and programs through
+// the repositories, so they can run without Postgres.
+type Handler struct {
+	Exercises repository.Exercises
+	Programs  repository.Programs
+
+	graphSchema func() (*graph.Schema, error)
+}
+
+func NewHandler(exercises repository.Exercises, programs repository.Programs) *Handler {
+	h := &Handler{Exercises: exercises, Programs: programs}
+	// Built on first use, the GraphQL API checks program access the same
+	// way the REST endpoints do.
+	h.graphSchema = sync.OnceValues(func() (*graph.Schema, error) {
+		return graph.NewSchema(graph.Config{ReadableProgram: h.readableProgramWithToken})
+	})
+	return h
+}
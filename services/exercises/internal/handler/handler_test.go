@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/auth"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/models"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/repository"
+)
+
+const (
+	ownerID  = int64(7)
+	clientID = int64(8)
+	otherID  = int64(9)
+)
+
+var (
+	publicProgram  = testUUID("11111111-1111-1111-1111-111111111111")
+	privateProgram = testUUID("22222222-2222-2222-2222-222222222222")
+	linkProgram    = testUUID("33333333-3333-3333-3333-333333333333")
+	missingProgram = testUUID("44444444-4444-4444-4444-444444444444")
+	collection     = testUUID("55555555-5555-5555-5555-555555555555")
+)
+
+func testUUID(s string) pgtype.UUID {
+	var id pgtype.UUID
+	if err := id.Scan(s); err != nil {
+		panic(err)
+	}
+	return id
+}
+
+// newTestStore returns an in-memory store holding three catalog exercises,
+// a custom exercise of ownerID, a collection of ownerID and one program of
+// each visibility.
+func newTestStore() *repository.Memory {
+	strength := db.NullCategoryT{CategoryT: db.CategoryTStrength, Valid: true}
+	owner := ownerID
+
+	store := repository.NewMemory()
+	store.AddExercise(models.Exercise{Id: 1, Names: []string{"Push Up"}, Muscles: []string{"Chest", "Triceps"}, Equipment: db.EquipmentTBodyweight}, strength)
+	store.AddExercise(models.Exercise{Id: 2, Names: []string{"Squat", "Back Squat"}, Muscles: []string{"Quadriceps"}, Equipment: db.EquipmentTBarbell}, strength)
+	store.AddExercise(models.Exercise{Id: 3, Names: []string{"Plank"}, Muscles: []string{"Abdominals"}, Equipment: db.EquipmentTBodyweight}, db.NullCategoryT{CategoryT: db.CategoryTStretching, Valid: true})
+	store.AddExercise(models.Exercise{Id: 100, Names: []string{"Sled Push"}, Muscles: []string{"Quadriceps"}, Equipment: db.EquipmentTOther, OwnerID: &owner}, strength)
+	store.AddFavorite(ownerID, 2)
+	store.AddCollection(db.ExerciseCollection{ID: collection, UserID: ownerID, Name: "Core"})
+	store.AddToCollection(collection, 3)
+
+	records := []models.ProgramRecord{
+		{ExerciseId: 2, Idx: 2, Day: 1, Sets: 5, Reps: 5},
+		{ExerciseId: 1, Idx: 1, Day: 1, Sets: 3, Reps: 10},
+	}
+	store.AddProgram(db.ProgramAccess{ProgramID: publicProgram, Visibility: db.VisibilityTPublic}, records)
+	store.AddProgram(db.ProgramAccess{ProgramID: privateProgram, OwnerID: pgtype.Int8{Int64: ownerID, Valid: true}, Visibility: db.VisibilityTPrivate}, records)
+	store.AssignProgram(privateProgram, clientID)
+	store.AddProgram(db.ProgramAccess{ProgramID: linkProgram, OwnerID: pgtype.Int8{Int64: ownerID, Valid: true}, Visibility: db.VisibilityTLink}, records)
+	store.AddShareToken(linkProgram, "secret")
+	return store
+}
+
+// serve runs the request through a router holding only the given route,
+// authenticated as userID unless it is 0.
+func serve(t *testing.T, method, pattern string, handler http.HandlerFunc, target, body string, userID int64) *httptest.ResponseRecorder {
+	t.Helper()
+	r := chi.NewRouter()
+	r.Method(method, pattern, handler)
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if userID != 0 {
+		req = req.WithContext(auth.WithUserID(context.Background(), userID))
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+)
+
+// draining is set once the service is shutting down
+var draining atomic.Bool
+
+type readiness struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// Drain fails the readiness checks from now on, so no new requests are
+// routed to the service while it shuts down.
+func Drain() {
+	draining.Store(true)
+}
+
+// Healthz godoc
+// @Summary      Liveness probe
+// @Description  Report that the process is up
+// @Tags         health
+// @Produce      plain
+// @Success      200
+// @Router       /healthz [get]
+func Healthz(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("ok"))
+}
+
+// Readyz godoc
+// @Summary      Readiness probe
+// @Description  Report whether the service can take requests, which it can't once it is shutting down or while Postgres is unreachable
+// @Tags         health
+// @Produce      json
+// @Success      200
+// @Failure      503
+// @Router       /readyz [get]
+func Readyz(w http.ResponseWriter, r *http.Request) {
+	resp := readiness{Status: "ok", Checks: map[string]string{"postgres": "ok"}}
+	status := http.StatusOK
+
+	if draining.Load() {
+		resp.Status = "shutting down"
+		status = http.StatusServiceUnavailable
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+	defer cancel()
+	if err := db.GetPool().Ping(ctx); err != nil {
+		log.Printf("Error at pinging the DB: %v", err)
+		resp.Status = "unavailable"
+		resp.Checks["postgres"] = err.Error()
+		status = http.StatusServiceUnavailable
+	}
+
+	writeJSON(w, status, resp)
+}
@@ -0,0 +1,394 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/auth"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/importer"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/models"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/repository"
+)
+
+// maxImportBytes bounds the size of an uploaded sheet.
+const maxImportBytes = 5 << 20
+
+// errNothingToImport is returned when every entry of an import was skipped.
+var errNothingToImport = errors.New("every entry was skipped, there is nothing to import")
+
+// ImportProgram godoc
+// @Summary      Import a Program from a sheet
+// @Description  Parse a CSV or XLSX program sheet and match its exercise names against the catalog. When every entry matches the program is created right away, otherwise the import waits for the unmatched entries to be resolved.
+// @Tags         imports
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file		formData      file  	true	"CSV or XLSX sheet"
+// @Success      201	{object}  models.ImportReport
+// @Failure      400
+// @Failure      401
+// @Failure      413
+// @Failure      500
+// @Router       /api/programs/import [post]
+func ImportProgram(w http.ResponseWriter, r *http.Request) {
+	log.Println("POST /api/programs/import endpoint called")
+	userID, _ := auth.UserID(r.Context())
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBytes)
+	filename, data, err := readUpload(r)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		http.Error(w, "The sheet is too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		log.Printf("Invalid upload in ImportProgram: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	entries, err := importer.Parse(filename, data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	matcher, err := newImportMatcher(r.Context())
+	if err != nil {
+		log.Printf("Error at GETting the exercise names from DB: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	matcher.Match(entries)
+	if err := checkImportEntries(r.Context(), entries); err != nil {
+		log.Printf("Error at checking import entries: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	tx, err := db.GetPool().Begin(r.Context())
+	if err != nil {
+		log.Printf("Error at starting transaction: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback(r.Context())
+	qtx := db.Queriez.WithTx(tx)
+
+	status := db.ImportStatusTPending
+	var programID pgtype.UUID
+	if !models.Summarize(entries).Unresolved() {
+		programID, err = commitImport(r.Context(), qtx, entries)
+		if errors.Is(err, errNothingToImport) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			log.Printf("Error at creating imported program: %v", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		status = db.ImportStatusTCommitted
+	}
+
+	encoded, err := json.Marshal(entries)
+	if err != nil {
+		log.Printf("Error at Marshaling import entries: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	row, err := qtx.InsertProgramImport(r.Context(), db.InsertProgramImportParams{
+		UserID:    userID,
+		Filename:  filename,
+		Status:    status,
+		Entries:   encoded,
+		ProgramID: programID,
+	})
+	if err != nil {
+		log.Printf("Error at inserting program import: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("Error at committing program import: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	writeImportReport(w, http.StatusCreated, row)
+}
+
+// GetProgramImport godoc
+// @Summary      Get an import
+// @Description  Get the report of an import of the authenticated user
+// @Tags         imports
+// @Produce      json
+// @Param        id		path      string  	true	"Import UUID"
+// @Success      200	{object}  models.ImportReport
+// @Failure      400
+// @Failure      401
+// @Failure      404
+// @Failure      500
+// @Router       /api/programs/import/{id} [get]
+func GetProgramImport(w http.ResponseWriter, r *http.Request) {
+	log.Println("GET /api/programs/import/{id} endpoint called")
+	row, ok := userImport(w, r)
+	if !ok {
+		return
+	}
+	writeImportReport(w, http.StatusOK, row)
+}
+
+// ResolveProgramImport godoc
+// @Summary      Resolve the entries of an import
+// @Description  Pick the exercise of, fix the prescriptions of or skip entries of a pending import. Once no entry is left unresolved the program is created.
+// @Tags         imports
+// @Accept       json
+// @Produce      json
+// @Param        id		path      string  	true	"Import UUID"
+// @Param        resolutions	body      models.ResolveImportRequest  	true	"Decisions for the entries"
+// @Success      200	{object}  models.ImportReport
+// @Failure      400
+// @Failure      401
+// @Failure      404
+// @Failure      409
+// @Failure      500
+// @Router       /api/programs/import/{id}/resolve [post]
+func ResolveProgramImport(w http.ResponseWriter, r *http.Request) {
+	log.Println("POST /api/programs/import/{id}/resolve endpoint called")
+	row, ok := userImport(w, r)
+	if !ok {
+		return
+	}
+	if row.Status == db.ImportStatusTCommitted {
+		http.Error(w, "The import was already turned into a program", http.StatusConflict)
+		return
+	}
+
+	var req models.ResolveImportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Invalid JSON in ResolveProgramImport: %v", err)
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	var entries []models.ImportEntry
+	if err := json.Unmarshal(row.Entries, &entries); err != nil {
+		log.Printf("Error at Unmarshaling import entries: %v, import: %s", err, chi.URLParam(r, "id"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	matcher, err := newImportMatcher(r.Context())
+	if err != nil {
+		log.Printf("Error at GETting the exercise names from DB: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	for _, res := range req.Resolutions {
+		if res.Entry < 1 || res.Entry > len(entries) {
+			http.Error(w, fmt.Sprintf("entry %d: no such entry", res.Entry), http.StatusBadRequest)
+			return
+		}
+		if !matcher.Resolve(&entries[res.Entry-1], res) {
+			http.Error(w, fmt.Sprintf("entry %d: expected skip, a known exerciseId or prescriptions", res.Entry), http.StatusBadRequest)
+			return
+		}
+	}
+	if err := checkImportEntries(r.Context(), entries); err != nil {
+		log.Printf("Error at checking import entries: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	tx, err := db.GetPool().Begin(r.Context())
+	if err != nil {
+		log.Printf("Error at starting transaction: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback(r.Context())
+	qtx := db.Queriez.WithTx(tx)
+
+	status := db.ImportStatusTPending
+	var programID pgtype.UUID
+	if !models.Summarize(entries).Unresolved() {
+		programID, err = commitImport(r.Context(), qtx, entries)
+		if errors.Is(err, errNothingToImport) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			log.Printf("Error at creating imported program: %v, import: %s", err, chi.URLParam(r, "id"))
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		status = db.ImportStatusTCommitted
+	}
+
+	encoded, err := json.Marshal(entries)
+	if err != nil {
+		log.Printf("Error at Marshaling import entries: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	row, err = qtx.UpdateProgramImport(r.Context(), db.UpdateProgramImportParams{
+		ID:        row.ID,
+		Status:    status,
+		Entries:   encoded,
+		ProgramID: programID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Another request turned the import into a program meanwhile
+		http.Error(w, "The import was already turned into a program", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("Error at updating program import: %v, import: %s", err, chi.URLParam(r, "id"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("Error at committing program import: %v, import: %s", err, chi.URLParam(r, "id"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	writeImportReport(w, http.StatusOK, row)
+}
+
+// readUpload returns the sheet of a multipart upload in the file field, or
+// the raw request body named by the filename query parameter.
+func readUpload(r *http.Request) (string, []byte, error) {
+	if file, header, err := r.FormFile("file"); err == nil {
+		defer file.Close()
+		data, err := io.ReadAll(file)
+		return header.Filename, data, err
+	} else if !errors.Is(err, http.ErrNotMultipart) {
+		return "", nil, err
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(data) == 0 {
+		return "", nil, errors.New("expected a sheet in the file field or as the request body")
+	}
+	filename := r.URL.Query().Get("filename")
+	if filename == "" {
+		filename = "upload"
+	}
+	return filename, data, nil
+}
+
+func newImportMatcher(ctx context.Context) (*importer.Matcher, error) {
+	names, err := db.Queriez.GetExerciseNames(ctx, viewerID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return importer.NewMatcher(names), nil
+}
+
+// checkImportEntries validates the prescriptions of the matched entries
+// against the category of their exercise, marking the ones that don't fit
+// as invalid.
+func checkImportEntries(ctx context.Context, entries []models.ImportEntry) error {
+	ids := make([]int32, 0, len(entries))
+	for _, e := range entries {
+		if e.ExerciseId != nil {
+			ids = append(ids, int32(*e.ExerciseId))
+		}
+	}
+	rows, err := db.Queriez.GetExerciseCategories(ctx, db.GetExerciseCategoriesParams{ExerciseIds: ids, ViewerID: viewerID(ctx)})
+	if err != nil {
+		return err
+	}
+	categories := make(map[int32]db.NullCategoryT, len(rows))
+	for _, row := range rows {
+		categories[row.ID] = row.Category
+	}
+
+	for i := range entries {
+		e := &entries[i]
+		if e.Status != models.EntryMatched && e.Status != models.EntryInvalid {
+			continue
+		}
+		if err := e.Record(e.Entry).Validate(categories[int32(*e.ExerciseId)]); err != nil {
+			e.Status = models.EntryInvalid
+			e.Error = err.Error()
+		} else {
+			e.Status = models.EntryMatched
+			e.Error = ""
+		}
+	}
+	return nil
+}
+
+// commitImport creates the program of the matched entries, owned by the
+// caller.
+func commitImport(ctx context.Context, q *db.Queries, entries []models.ImportEntry) (pgtype.UUID, error) {
+	records := models.ImportRecords(entries)
+	if len(records) == 0 {
+		return pgtype.UUID{}, errNothingToImport
+	}
+
+	programID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	if err := repository.InsertProgramRecords(ctx, q, programID, records); err != nil {
+		return programID, err
+	}
+	if err := repository.InsertProgramAccess(ctx, q, programID, pgtype.UUID{}); err != nil {
+		return programID, err
+	}
+	if _, err := repository.RecordRevision(ctx, q, programID, db.RevisionSourceTImported, pgtype.Int4{}); err != nil {
+		return programID, err
+	}
+	return programID, nil
+}
+
+// userImport loads the import of the URL, writing the error response when it
+// doesn't belong to the caller.
+func userImport(w http.ResponseWriter, r *http.Request) (db.ProgramImport, bool) {
+	userID, _ := auth.UserID(r.Context())
+
+	var import_uuid pgtype.UUID
+	if err := import_uuid.Scan(chi.URLParam(r, "id")); err != nil {
+		log.Printf("Error scanning UUID Value from URL: %v, uuid: %s", err, chi.URLParam(r, "id"))
+		http.Error(w, "Invalid UUID", http.StatusBadRequest)
+		return db.ProgramImport{}, false
+	}
+
+	row, err := db.Queriez.GetProgramImport(r.Context(), db.GetProgramImportParams{ID: import_uuid, UserID: userID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		http.Error(w, "Import not found", http.StatusNotFound)
+		return row, false
+	}
+	if err != nil {
+		log.Printf("Error at GETting the import from DB: %v, uuid: %s", err, chi.URLParam(r, "id"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return row, false
+	}
+	return row, true
+}
+
+func writeImportReport(w http.ResponseWriter, status int, row db.ProgramImport) {
+	report, err := models.ImportReportFromRow(row)
+	if err != nil {
+		log.Printf("Error at Unmarshaling import entries: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, status, report)
+}
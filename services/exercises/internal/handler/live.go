@@ -0,0 +1,176 @@
+package service
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/live"
+)
+
+const (
+	// liveMaxMessage is the largest message a device may send.
+	liveMaxMessage = 4 << 10
+	liveWriteWait  = 10 * time.Second
+	livePongWait   = 60 * time.Second
+	livePingPeriod = livePongWait * 9 / 10
+)
+
+// liveSessions holds the live sessions of the workouts followed on this
+// replica.
+var liveSessions = live.NewHub()
+
+// Devices authenticate with a bearer token rather than cookies, so
+// connections from any origin are accepted.
+var liveUpgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// liveCommand is a message sent by a device.
+type liveCommand struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// GetLiveWorkout godoc
+// @Summary      Follow a workout live
+// @Description  Upgrade to a WebSocket streaming the updates of an in-progress workout of the authenticated user between its devices. Devices send {"type", "data"} messages of type set.edited, rest.started or rest.stopped; every device receives them, the sets logged through the API (set.logged) and the end of the workout (workout.finished), numbered by seq. After reconnecting, pass the seq of the last message received as since to get the messages missed; a resync message means they are gone and the workout has to be reloaded
+// @Tags         workouts
+// @Param        id		path      string  	true	"Workout UUID"
+// @Param        since	query      int  	false	"Seq of the last message received"
+// @Param        device	query      string  	false	"Name of the device, e.g. tablet"
+// @Success      101
+// @Failure      400
+// @Failure      401
+// @Failure      404
+// @Failure      409
+// @Failure      500
+// @Router       /api/workouts/{id}/live [get]
+func GetLiveWorkout(w http.ResponseWriter, r *http.Request) {
+	log.Println("GET /api/workouts/{id}/live endpoint called")
+	row, ok := userWorkout(w, r)
+	if !ok {
+		return
+	}
+	if row.FinishedAt.Valid {
+		http.Error(w, "Workout already finished", http.StatusConflict)
+		return
+	}
+
+	var since int64
+	if v := r.URL.Query().Get("since"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			http.Error(w, "since must be a sequence number", http.StatusBadRequest)
+			return
+		}
+		since = n
+	}
+	device := r.URL.Query().Get("device")
+	if len(device) > 50 {
+		http.Error(w, "device can be at most 50 characters", http.StatusBadRequest)
+		return
+	}
+
+	conn, err := liveUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// The upgrader has written the response
+		log.Printf("Error at upgrading to WebSocket: %v, id: %s", err, chi.URLParam(r, "id"))
+		return
+	}
+	defer conn.Close()
+
+	session := liveSessions.Join(uuid.UUID(row.ID.Bytes))
+	replay, ok, messages, cancel := session.Subscribe(since)
+	defer cancel()
+
+	// Only the writer below writes to conn, the reader hands it the
+	// messages for this device
+	direct := make(chan live.Message, 4)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		readLiveCommands(conn, session, device, direct)
+	}()
+
+	if !ok {
+		direct <- live.Message{Type: "resync", At: time.Now().UTC()}
+	}
+	for _, msg := range replay {
+		if !writeLiveMessage(conn, msg) {
+			return
+		}
+	}
+
+	ping := time.NewTicker(livePingPeriod)
+	defer ping.Stop()
+	for {
+		select {
+		case msg, open := <-messages:
+			if !open {
+				// Fell behind or the workout ended
+				conn.SetWriteDeadline(time.Now().Add(liveWriteWait))
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+				return
+			}
+			if !writeLiveMessage(conn, msg) {
+				return
+			}
+		case msg := <-direct:
+			if !writeLiveMessage(conn, msg) {
+				return
+			}
+		case <-ping.C:
+			conn.SetWriteDeadline(time.Now().Add(liveWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+// readLiveCommands publishes the messages of a device until it
+// disconnects. Invalid messages are answered with an error message.
+func readLiveCommands(conn *websocket.Conn, session *live.Session, device string, direct chan<- live.Message) {
+	conn.SetReadLimit(liveMaxMessage)
+	conn.SetReadDeadline(time.Now().Add(livePongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(livePongWait))
+	})
+
+	for {
+		var cmd liveCommand
+		if err := conn.ReadJSON(&cmd); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				log.Printf("Error at reading live workout message: %v", err)
+			}
+			return
+		}
+		if !live.IsDeviceType(cmd.Type) {
+			data, _ := json.Marshal(map[string]string{"message": "type must be set.edited, rest.started or rest.stopped"})
+			select {
+			case direct <- live.Message{Type: "error", Data: data, At: time.Now().UTC()}:
+			default:
+			}
+			continue
+		}
+		session.Publish(cmd.Type, device, cmd.Data)
+	}
+}
+
+func writeLiveMessage(conn *websocket.Conn, msg live.Message) bool {
+	conn.SetWriteDeadline(time.Now().Add(liveWriteWait))
+	if err := conn.WriteJSON(msg); err != nil {
+		log.Printf("Error at writing live workout message: %v", err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,512 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/auth"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/models"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/moderation"
+)
+
+// errNameTaken is returned when publishing an exercise under a name the
+// catalog already uses.
+var errNameTaken = errors.New("name already in the catalog")
+
+// RequireAdmin rejects requests of users without the admin role. It runs
+// after auth.RequireUser.
+func RequireAdmin(next http.Handler) http.Handler {
+	return requireRole(next, db.RoleTAdmin, "Only admins can do this")
+}
+
+// GetMySubmissions godoc
+// @Summary      List the submissions of the user
+// @Tags         moderation
+// @Produce      json
+// @Success      200	{array}  models.ExerciseSubmission
+// @Failure      401
+// @Failure      500
+// @Router       /api/me/submissions [get]
+func GetMySubmissions(w http.ResponseWriter, r *http.Request) {
+	log.Println("GET /api/me/submissions endpoint called")
+	userID, _ := auth.UserID(r.Context())
+
+	rows, err := db.Queriez.GetUserSubmissions(r.Context(), userID)
+	if err != nil {
+		log.Printf("Error at GETting submissions from DB: %v, user: %d", err, userID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, models.ExerciseSubmissionsFromRows(rows))
+}
+
+// GetSubmission godoc
+// @Summary      Get a submission
+// @Description  Get a submission with the exercise as submitted and the comments of the author and the reviewers. Only the author and admins can read it
+// @Tags         moderation
+// @Produce      json
+// @Param        id		path      string  	true	"Submission UUID"
+// @Success      200	{object}  models.ExerciseSubmission
+// @Failure      400
+// @Failure      401
+// @Failure      404
+// @Failure      500
+// @Router       /api/submissions/{id} [get]
+func GetSubmission(w http.ResponseWriter, r *http.Request) {
+	log.Println("GET /api/submissions/{id} endpoint called")
+
+	row, ok := visibleSubmission(w, r)
+	if !ok {
+		return
+	}
+	writeSubmission(w, r, http.StatusOK, row)
+}
+
+// SubmitSubmission godoc
+// @Summary      Submit a draft
+// @Description  Hand a draft submission to the reviewers, with the custom exercise as it is now
+// @Tags         moderation
+// @Produce      json
+// @Param        id		path      string  	true	"Submission UUID"
+// @Success      200	{object}  models.ExerciseSubmission
+// @Failure      400
+// @Failure      401
+// @Failure      404
+// @Failure      409
+// @Failure      500
+// @Router       /api/submissions/{id}/submit [post]
+func SubmitSubmission(w http.ResponseWriter, r *http.Request) {
+	log.Println("POST /api/submissions/{id}/submit endpoint called")
+	userID, _ := auth.UserID(r.Context())
+
+	row, ok := authoredSubmission(w, r)
+	if !ok {
+		return
+	}
+
+	rows, err := db.Queriez.GetCustomExercises(r.Context(), db.GetCustomExercisesParams{OwnerID: userID, ExerciseID: row.ExerciseID})
+	if err != nil || len(rows) == 0 {
+		log.Printf("Error at GETting custom exercise from DB: %v, exercise: %d", err, row.ExerciseID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	snapshot, err := json.Marshal(models.CustomExerciseFromRow(rows[0]).Request())
+	if err != nil {
+		log.Printf("Error at Marshaling submission snapshot: %v, exercise: %d", err, row.ExerciseID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	updated, err := transitionSubmission(r.Context(), db.Queriez, row, moderation.Submit, moderation.Author,
+		db.TransitionExerciseSubmissionParams{Snapshot: snapshot})
+	if err != nil {
+		writeModerationError(w, err, row)
+		return
+	}
+	writeSubmission(w, r, http.StatusOK, updated)
+}
+
+// WithdrawSubmission godoc
+// @Summary      Withdraw a submission
+// @Description  Take a pending submission back to a draft before it gets reviewed
+// @Tags         moderation
+// @Produce      json
+// @Param        id		path      string  	true	"Submission UUID"
+// @Success      200	{object}  models.ExerciseSubmission
+// @Failure      400
+// @Failure      401
+// @Failure      404
+// @Failure      409
+// @Failure      500
+// @Router       /api/submissions/{id}/withdraw [post]
+func WithdrawSubmission(w http.ResponseWriter, r *http.Request) {
+	log.Println("POST /api/submissions/{id}/withdraw endpoint called")
+
+	row, ok := authoredSubmission(w, r)
+	if !ok {
+		return
+	}
+
+	updated, err := transitionSubmission(r.Context(), db.Queriez, row, moderation.Withdraw, moderation.Author,
+		db.TransitionExerciseSubmissionParams{})
+	if err != nil {
+		writeModerationError(w, err, row)
+		return
+	}
+	writeSubmission(w, r, http.StatusOK, updated)
+}
+
+// PostSubmissionComment godoc
+// @Summary      Comment on a submission
+// @Description  Add a comment to a submission, as its author or as an admin
+// @Tags         moderation
+// @Accept       json
+// @Produce      json
+// @Param        id			path      string  	true	"Submission UUID"
+// @Param        comment	body      models.CommentRequest  true  "Comment"
+// @Success      201	{object}  models.SubmissionComment
+// @Failure      400
+// @Failure      401
+// @Failure      404
+// @Failure      500
+// @Router       /api/submissions/{id}/comments [post]
+func PostSubmissionComment(w http.ResponseWriter, r *http.Request) {
+	log.Println("POST /api/submissions/{id}/comments endpoint called")
+	userID, _ := auth.UserID(r.Context())
+
+	row, ok := visibleSubmission(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.CommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Invalid JSON in PostSubmissionComment: %v", err)
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		http.Error(w, "body is required", http.StatusBadRequest)
+		return
+	}
+
+	comment, err := db.Queriez.InsertSubmissionComment(r.Context(), db.InsertSubmissionCommentParams{
+		SubmissionID: row.ID,
+		AuthorID:     userID,
+		Body:         body,
+	})
+	if err != nil {
+		log.Printf("Error at inserting submission comment: %v, uuid: %s", err, chi.URLParam(r, "id"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, models.SubmissionCommentsFromRows([]db.ExerciseSubmissionComment{comment})[0])
+}
+
+// GetSubmissionQueue godoc
+// @Summary      Review queue
+// @Description  List the submissions in a status, pending by default, oldest first
+// @Tags         moderation
+// @Produce      json
+// @Param        status		query		string	false	"draft, pending, approved or rejected"
+// @Param		 limit		query		int		false	"Limit"
+// @Param		 offset		query		int		false	"Offset"
+// @Success      200	{array}  models.ExerciseSubmission
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      500
+// @Router       /api/admin/submissions [get]
+func GetSubmissionQueue(w http.ResponseWriter, r *http.Request) {
+	log.Println("GET /api/admin/submissions endpoint called")
+
+	status := db.SubmissionStatusT(r.URL.Query().Get("status"))
+	switch status {
+	case "":
+		status = db.SubmissionStatusTPending
+	case db.SubmissionStatusTDraft, db.SubmissionStatusTPending, db.SubmissionStatusTApproved, db.SubmissionStatusTRejected:
+	default:
+		http.Error(w, "status must be draft, pending, approved or rejected", http.StatusBadRequest)
+		return
+	}
+	limit, offset := pagination(r)
+
+	rows, err := db.Queriez.GetSubmissionQueue(r.Context(), db.GetSubmissionQueueParams{
+		Status: status,
+		Limit:  int32(limit),
+		Offset: int32(offset),
+	})
+	if err != nil {
+		log.Printf("Error at GETting the submission queue from DB: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, models.ExerciseSubmissionsFromRows(rows))
+}
+
+// ApproveSubmission godoc
+// @Summary      Approve a submission
+// @Description  Approve a pending submission, publishing the exercise as submitted into the public catalog
+// @Tags         moderation
+// @Accept       json
+// @Produce      json
+// @Param        id			path      string  	true	"Submission UUID"
+// @Param        review		body      models.ReviewRequest  false  "Comment for the author"
+// @Success      200	{object}  models.ExerciseSubmission
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      404
+// @Failure      409
+// @Failure      500
+// @Router       /api/admin/submissions/{id}/approve [post]
+func ApproveSubmission(w http.ResponseWriter, r *http.Request) {
+	log.Println("POST /api/admin/submissions/{id}/approve endpoint called")
+	reviewSubmission(w, r, moderation.Approve)
+}
+
+// RejectSubmission godoc
+// @Summary      Reject a submission
+// @Description  Reject a pending submission with the reasons in the comment
+// @Tags         moderation
+// @Accept       json
+// @Produce      json
+// @Param        id			path      string  	true	"Submission UUID"
+// @Param        review		body      models.ReviewRequest  true  "Comment for the author"
+// @Success      200	{object}  models.ExerciseSubmission
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      404
+// @Failure      409
+// @Failure      500
+// @Router       /api/admin/submissions/{id}/reject [post]
+func RejectSubmission(w http.ResponseWriter, r *http.Request) {
+	log.Println("POST /api/admin/submissions/{id}/reject endpoint called")
+	reviewSubmission(w, r, moderation.Reject)
+}
+
+// reviewSubmission approves or rejects a submission along with the comment
+// of the reviewer, publishing approved exercises, all in one transaction.
+func reviewSubmission(w http.ResponseWriter, r *http.Request, action moderation.Action) {
+	reviewerID, _ := auth.UserID(r.Context())
+
+	row, ok := fetchSubmission(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.ReviewRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Printf("Invalid JSON in review: %v", err)
+			http.Error(w, "Invalid JSON", http.StatusBadRequest)
+			return
+		}
+	}
+	comment := strings.TrimSpace(req.Comment)
+	if action == moderation.Reject && comment == "" {
+		http.Error(w, "comment is required to reject a submission", http.StatusBadRequest)
+		return
+	}
+	// Check the transition before publishing so a submission that is no
+	// longer pending can't add its exercise to the catalogue again.
+	if _, err := moderation.Next(row.Status, action, moderation.Reviewer); err != nil {
+		writeModerationError(w, err, row)
+		return
+	}
+
+	tx, err := db.GetPool().Begin(r.Context())
+	if err != nil {
+		log.Printf("Error at beginning transaction: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback(r.Context())
+	qtx := db.Queriez.WithTx(tx)
+
+	params := db.TransitionExerciseSubmissionParams{ReviewedBy: pgtype.Int8{Int64: reviewerID, Valid: true}}
+	if action == moderation.Approve {
+		exercise := models.ExerciseSubmissionFromRow(row).Exercise
+		if exercise == nil {
+			http.Error(w, "Submission has no exercise to publish", http.StatusConflict)
+			return
+		}
+		exerciseID, err := publishExercise(r.Context(), qtx, *exercise)
+		if errors.Is(err, errNameTaken) || errors.Is(err, errUnknownMuscle) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		if err != nil {
+			log.Printf("Error at publishing submission: %v, uuid: %s", err, chi.URLParam(r, "id"))
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		params.PublishedExerciseID = pgtype.Int4{Int32: exerciseID, Valid: true}
+	}
+
+	updated, err := transitionSubmission(r.Context(), qtx, row, action, moderation.Reviewer, params)
+	if err != nil {
+		writeModerationError(w, err, row)
+		return
+	}
+	if comment != "" {
+		_, err := qtx.InsertSubmissionComment(r.Context(), db.InsertSubmissionCommentParams{
+			SubmissionID: row.ID,
+			AuthorID:     reviewerID,
+			Body:         comment,
+		})
+		if err != nil {
+			log.Printf("Error at inserting review comment: %v, uuid: %s", err, chi.URLParam(r, "id"))
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("Error at committing review: %v, uuid: %s", err, chi.URLParam(r, "id"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeSubmission(w, r, http.StatusOK, updated)
+}
+
+// publishExercise inserts a submitted exercise into the public catalog,
+// with its names, muscles and visuals. The custom exercise it was
+// submitted from stays as it is.
+func publishExercise(ctx context.Context, q *db.Queries, exercise models.CustomExerciseRequest) (int32, error) {
+	if err := exercise.Normalize(); err != nil {
+		return 0, err
+	}
+
+	lower := make([]string, 0, len(exercise.Names))
+	for _, name := range exercise.Names {
+		lower = append(lower, strings.ToLower(name))
+	}
+	taken, err := q.GetCatalogNameConflicts(ctx, lower)
+	if err != nil {
+		return 0, err
+	}
+	if len(taken) > 0 {
+		return 0, fmt.Errorf("%w: %s", errNameTaken, strings.Join(taken, ", "))
+	}
+	muscles, err := resolveMuscles(ctx, exercise)
+	if err != nil {
+		return 0, err
+	}
+
+	visualsID, err := insertVisuals(ctx, q, exercise.Visuals)
+	if err != nil {
+		return 0, err
+	}
+	id, err := q.InsertCatalogExercise(ctx, db.InsertCatalogExerciseParams{
+		Equipment:    exercise.Equipment,
+		Category:     nullCategory(exercise.Category),
+		Level:        nullLevel(exercise.Level),
+		Mechanic:     nullMechanic(exercise.Mechanic),
+		Force:        nullForce(exercise.Force),
+		Instructions: exercise.Instructions,
+		VisualsID:    visualsID,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if err := insertExerciseDetails(ctx, q, id, exercise, muscles); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// transitionSubmission moves a submission along the state machine. The
+// update only applies while the submission is still in the status it was
+// read in, so concurrent reviews can't both succeed.
+func transitionSubmission(ctx context.Context, q *db.Queries, row db.ExerciseSubmission, action moderation.Action, actor moderation.Actor, params db.TransitionExerciseSubmissionParams) (db.ExerciseSubmission, error) {
+	to, err := moderation.Next(row.Status, action, actor)
+	if err != nil {
+		return db.ExerciseSubmission{}, err
+	}
+	params.ID = row.ID
+	params.FromStatus = row.Status
+	params.ToStatus = to
+
+	updated, err := q.TransitionExerciseSubmission(ctx, params)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return db.ExerciseSubmission{}, fmt.Errorf("%w: submission changed in the meantime", moderation.ErrInvalidTransition)
+	}
+	return updated, err
+}
+
+func writeModerationError(w http.ResponseWriter, err error, row db.ExerciseSubmission) {
+	switch {
+	case errors.Is(err, moderation.ErrUnknownAction):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, moderation.ErrNotAllowed):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, moderation.ErrInvalidTransition):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		log.Printf("Error at updating submission: %v, exercise: %d", err, row.ExerciseID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+// fetchSubmission loads the submission in the id URL parameter.
+func fetchSubmission(w http.ResponseWriter, r *http.Request) (db.ExerciseSubmission, bool) {
+	var submission_uuid pgtype.UUID
+	if err := submission_uuid.Scan(chi.URLParam(r, "id")); err != nil {
+		log.Printf("Error scanning UUID Value from URL: %v, uuid: %s", err, chi.URLParam(r, "id"))
+		http.Error(w, "Invalid UUID", http.StatusBadRequest)
+		return db.ExerciseSubmission{}, false
+	}
+
+	row, err := db.Queriez.GetExerciseSubmission(r.Context(), submission_uuid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		http.Error(w, "Submission not found", http.StatusNotFound)
+		return db.ExerciseSubmission{}, false
+	}
+	if err != nil {
+		log.Printf("Error at GETting submission from DB: %v, uuid: %s", err, chi.URLParam(r, "id"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return db.ExerciseSubmission{}, false
+	}
+	return row, true
+}
+
+// authoredSubmission is fetchSubmission for its author. Submissions of other
+// users are reported as not found.
+func authoredSubmission(w http.ResponseWriter, r *http.Request) (db.ExerciseSubmission, bool) {
+	userID, _ := auth.UserID(r.Context())
+	row, ok := fetchSubmission(w, r)
+	if !ok {
+		return row, false
+	}
+	if row.UserID != userID {
+		http.Error(w, "Submission not found", http.StatusNotFound)
+		return row, false
+	}
+	return row, true
+}
+
+// visibleSubmission is fetchSubmission for its author and admins.
+func visibleSubmission(w http.ResponseWriter, r *http.Request) (db.ExerciseSubmission, bool) {
+	userID, _ := auth.UserID(r.Context())
+	row, ok := fetchSubmission(w, r)
+	if !ok || row.UserID == userID {
+		return row, ok
+	}
+
+	isAdmin, err := db.Queriez.HasUserRole(r.Context(), db.HasUserRoleParams{UserID: userID, Role: db.RoleTAdmin})
+	if err != nil {
+		log.Printf("Error at GETting the roles from DB: %v, user: %d", err, userID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return row, false
+	}
+	if !isAdmin {
+		http.Error(w, "Submission not found", http.StatusNotFound)
+		return row, false
+	}
+	return row, true
+}
+
+func writeSubmission(w http.ResponseWriter, r *http.Request, status int, row db.ExerciseSubmission) {
+	comments, err := db.Queriez.GetSubmissionComments(r.Context(), row.ID)
+	if err != nil {
+		log.Printf("Error at GETting submission comments from DB: %v, exercise: %d", err, row.ExerciseID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	submission := models.ExerciseSubmissionFromRow(row)
+	submission.Comments = models.SubmissionCommentsFromRows(comments)
+	writeJSON(w, status, submission)
+}
@@ -1,17 +1,23 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 
-	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/auth"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/estimate"
 	"github.com/Farzan-kh/guddy-cn/exercises/internal/models"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/repository"
 )
 
 // GetProgram godoc
@@ -20,10 +26,12 @@ import (
 // @Tags         programs
 // @Produce      json
 // @Param        uuid		query      string  	true	"Programs UUID"
+// @Param        share		query      string  	false	"Share token of a link-only program"
 // @Success      200	{object}  models.Program
+// @Failure      404
 // @Failure      500
 // @Router       /api/program [get]
-func GetProgram(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) GetProgram(w http.ResponseWriter, r *http.Request) {
 	log.Println("GET /api/program endpoint called")
 	var program_uuid pgtype.UUID
 	err := program_uuid.Scan(chi.URLParam(r, "uuid"))
@@ -33,16 +41,23 @@ func GetProgram(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if _, err := readableProgramFrom(r.Context(), h.Programs, program_uuid, r.URL.Query().Get(shareQueryParam)); err != nil {
+		writeAccessError(w, err, chi.URLParam(r, "uuid"))
+		return
+	}
+
 	log.Printf("Fetching program by ID: %s", chi.URLParam(r, "uuid"))
-	programRows, err := db.Queriez.GetProgramById(r.Context(), program_uuid)
+	program, err := h.Programs.GetProgram(r.Context(), program_uuid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		http.Error(w, "Program not found", http.StatusNotFound)
+		return
+	}
 	if err != nil {
 		log.Printf("Error at GETting the program from DB: %v, uuid: %s", err, chi.URLParam(r, "uuid"))
 		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
 
-	program := models.ProgramFromRows(program_uuid.Bytes, programRows)
-
 	program_json, err := json.Marshal(program)
 	if err != nil {
 		log.Printf("Error at Marshaling program object: %v", err)
@@ -56,14 +71,16 @@ func GetProgram(w http.ResponseWriter, r *http.Request) {
 
 // GetCompleteProgram godoc
 // @Summary      Get Complete Program by ID
-// @Description  Get Complete Program By ID, get's all the program and related info about exercises
+// @Description  Get Complete Program By ID, get's all the program and related info about exercises, along with the estimated duration of its sessions
 // @Tags         programs
 // @Produce      json
 // @Param        uuid		query      string  	true	"Programs UUID"
+// @Param        share		query      string  	false	"Share token of a link-only program"
 // @Success      200	{object}  models.CompleteProgram
+// @Failure      404
 // @Failure      500
 // @Router       /api/completeProgram [get]
-func GetCompleteProgram(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) GetCompleteProgram(w http.ResponseWriter, r *http.Request) {
 	log.Println("GET /api/fullProgram endpoint called")
 	var program_uuid pgtype.UUID
 	err := program_uuid.Scan(chi.URLParam(r, "uuid"))
@@ -73,15 +90,20 @@ func GetCompleteProgram(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if _, err := readableProgramFrom(r.Context(), h.Programs, program_uuid, r.URL.Query().Get(shareQueryParam)); err != nil {
+		writeAccessError(w, err, chi.URLParam(r, "uuid"))
+		return
+	}
+
 	log.Printf("Fetching full program by ID: %s", chi.URLParam(r, "uuid"))
-	programRows, err := db.Queriez.GetFullProgramById(r.Context(), program_uuid)
+	program, err := h.Programs.GetCompleteProgram(r.Context(), program_uuid)
 	if err != nil {
 		log.Printf("Error at GETting the program from DB: %v, uuid: %s", err, chi.URLParam(r, "uuid"))
 		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
-
-	program := models.FullProgramFromRows(program_uuid.Bytes, programRows)
+	duration := estimate.Program(program.Records())
+	program.Duration = &duration
 
 	program_json, err := json.Marshal(program)
 	if err != nil {
@@ -94,9 +116,84 @@ func GetCompleteProgram(w http.ResponseWriter, r *http.Request) {
 	w.Write(program_json)
 }
 
+// GetPrograms godoc
+// @Summary      List the user's Programs
+// @Description  List the programs owned by the authenticated user, newest first, with the estimated duration of their sessions
+// @Tags         programs
+// @Produce      json
+// @Param        maxSessionMinutes	query      int  	false	"Only programs whose longest session fits in this many minutes"
+// @Param        limit		query      int  	false	"Page size, 50 by default"
+// @Param        offset		query      int  	false	"Page offset"
+// @Success      200	{array}  models.ProgramSummary
+// @Failure      400
+// @Failure      401
+// @Failure      500
+// @Router       /api/programs [get]
+func (h *Handler) GetPrograms(w http.ResponseWriter, r *http.Request) {
+	log.Println("GET /api/programs endpoint called")
+	userID, _ := auth.UserID(r.Context())
+	limit, offset := pagination(r)
+
+	maxMinutes := 0
+	if v := r.URL.Query().Get("maxSessionMinutes"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			http.Error(w, "maxSessionMinutes must be a positive number", http.StatusBadRequest)
+			return
+		}
+		maxMinutes = n
+	}
+
+	rows, err := h.Programs.GetUserPrograms(r.Context(), userID)
+	if err != nil {
+		log.Printf("Error at GETting the programs from DB: %v, user: %d", err, userID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	items, err := h.Programs.GetUserProgramItems(r.Context(), userID)
+	if err != nil {
+		log.Printf("Error at GETting the program items from DB: %v, user: %d", err, userID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// Durations are estimated from the items, so the filter runs before
+	// paginating.
+	programs := make([]models.ProgramSummary, 0)
+	for _, access := range rows {
+		program, ok := items[access.ProgramID.Bytes]
+		if !ok {
+			continue
+		}
+
+		duration := estimate.Program(program.Exercises)
+		if maxMinutes > 0 && duration.LongestSessionMinutes > maxMinutes {
+			continue
+		}
+		summary := models.ProgramSummary{
+			UUID:       access.ProgramID.Bytes,
+			Visibility: access.Visibility,
+			CreatedAt:  access.CreatedAt.Time,
+			Days:       len(duration.Sessions),
+			Items:      len(program.Exercises),
+			Duration:   duration,
+		}
+		if access.ForkedFrom.Valid {
+			forkedFrom := uuid.UUID(access.ForkedFrom.Bytes)
+			summary.ForkedFrom = &forkedFrom
+		}
+		programs = append(programs, summary)
+	}
+
+	start := min(offset, len(programs))
+	end := min(start+limit, len(programs))
+	writeJSON(w, http.StatusOK, programs[start:end])
+}
+
 // GetProgram godoc
 // @Summary      Create a Program
-// @Description  Create a new program and return it's UUID
+// @Description  Create a new program and return it's UUID. Programs of an authenticated user start private, anonymous ones are public.
 // @Tags         programs
 // @Accept       json
 // @Produce      json
@@ -104,9 +201,9 @@ func GetCompleteProgram(w http.ResponseWriter, r *http.Request) {
 // @Success      200	{object}  uuid.UUID
 // @Failure      500
 // @Router       /api/program [post]
-func PostProgram(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) PostProgram(w http.ResponseWriter, r *http.Request) {
 	log.Println("POST /api/program endpoint called")
-	var exercises_list []db.InsertToProgramsByIdParams
+	var exercises_list []models.ProgramRecord
 
 	//TODO: Do something about idx order_number translation
 	err := json.NewDecoder(r.Body).Decode(&exercises_list)
@@ -116,22 +213,62 @@ func PostProgram(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	uuid := uuid.New()
-	var pg_uuid pgtype.UUID
-	_ = pg_uuid.Scan(uuid.String())
+	if err := validateProgramRecords(r.Context(), h.Exercises, exercises_list); err != nil {
+		log.Printf("Invalid program in PostProgram: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	log.Printf("Inserting new program with ID: %s, num_exercises: %d", uuid.String(), len(exercises_list))
-	for _, v := range exercises_list {
-		v.ID = pg_uuid
-		err := db.Queriez.InsertToProgramsById(r.Context(), v)
-		if err != nil {
-			log.Printf("Error at inserting program items: %v, program_id: %s", err, uuid.String())
-			http.Error(w, "Error at inserting program items", http.StatusInternalServerError)
-			return
-		}
+	uuid, err := createProgram(r.Context(), h.Programs, exercises_list)
+	if err != nil {
+		log.Printf("Error at creating program: %v, program_id: %s", err, uuid.String())
+		http.Error(w, "Error at inserting program items", http.StatusInternalServerError)
+		return
 	}
 
 	w.Header().Add("Content-Type", "application/json")
 	res := fmt.Sprintf("{\n\t\"program_id\": \"%s\"\n}", uuid)
 	w.Write([]byte(res))
 }
+
+// createProgram stores a new program under a fresh ID. The records must
+// have been validated.
+func createProgram(ctx context.Context, programs repository.Programs, records []models.ProgramRecord) (uuid.UUID, error) {
+	uuid := uuid.New()
+	var pg_uuid pgtype.UUID
+	_ = pg_uuid.Scan(uuid.String())
+
+	log.Printf("Inserting new program with ID: %s, num_exercises: %d", uuid.String(), len(records))
+	return uuid, programs.CreateProgram(ctx, pg_uuid, records)
+}
+
+// validateProgramRecords checks every program item against the category of
+// its exercise, rejecting items that point at unknown exercises or at custom
+// exercises the caller can't use.
+func validateProgramRecords(ctx context.Context, exercises repository.Exercises, records []models.ProgramRecord) error {
+	ids := make([]int32, 0, len(records))
+	for _, rec := range records {
+		ids = append(ids, int32(rec.ExerciseId))
+	}
+
+	categories, err := exercises.GetExerciseCategories(ctx, ids, viewerID(ctx))
+	if err != nil {
+		return fmt.Errorf("couldn't fetch exercise categories: %w", err)
+	}
+
+	seen := make(map[int]bool, len(records))
+	for _, rec := range records {
+		category, ok := categories[int32(rec.ExerciseId)]
+		if !ok {
+			return fmt.Errorf("item %d: unknown exercise %d", rec.Idx, rec.ExerciseId)
+		}
+		if seen[rec.Idx] {
+			return fmt.Errorf("item %d: duplicate idx", rec.Idx)
+		}
+		seen[rec.Idx] = true
+		if err := rec.Validate(category); err != nil {
+			return fmt.Errorf("item %d: %w", rec.Idx, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/models"
+)
+
+// readTests are the access cases shared by the endpoints reading a program.
+var readTests = []struct {
+	name   string
+	path   string
+	userID int64
+	status int
+}{
+	{name: "invalid uuid", path: "/not-a-uuid", status: http.StatusBadRequest},
+	{name: "missing", path: "/44444444-4444-4444-4444-444444444444", status: http.StatusNotFound},
+	{name: "public", path: "/11111111-1111-1111-1111-111111111111", status: http.StatusOK},
+	{name: "private anonymous", path: "/22222222-2222-2222-2222-222222222222", status: http.StatusNotFound},
+	{name: "private of another user", path: "/22222222-2222-2222-2222-222222222222", userID: otherID, status: http.StatusNotFound},
+	{name: "private owner", path: "/22222222-2222-2222-2222-222222222222", userID: ownerID, status: http.StatusOK},
+	{name: "private assigned to the client", path: "/22222222-2222-2222-2222-222222222222", userID: clientID, status: http.StatusOK},
+	{name: "link with token", path: "/33333333-3333-3333-3333-333333333333?share=secret", status: http.StatusOK},
+	{name: "link with wrong token", path: "/33333333-3333-3333-3333-333333333333?share=guess", status: http.StatusNotFound},
+	{name: "link without token", path: "/33333333-3333-3333-3333-333333333333", userID: otherID, status: http.StatusNotFound},
+}
+
+func TestGetProgram(t *testing.T) {
+	for _, tt := range readTests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTestStore()
+			h := NewHandler(store, store)
+
+			rec := serve(t, http.MethodGet, "/api/program/{uuid}", h.GetProgram, "/api/program"+tt.path, "", tt.userID)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var program models.Program
+			if err := json.Unmarshal(rec.Body.Bytes(), &program); err != nil {
+				t.Fatalf("invalid body: %v", err)
+			}
+			if len(program.Exercises) != 2 || program.Exercises[0].Idx != 1 || program.Exercises[1].Idx != 2 {
+				t.Errorf("items = %+v, want idx 1 and 2 in order", program.Exercises)
+			}
+		})
+	}
+}
+
+func TestGetCompleteProgram(t *testing.T) {
+	for _, tt := range readTests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTestStore()
+			h := NewHandler(store, store)
+
+			rec := serve(t, http.MethodGet, "/api/completeProgram/{uuid}", h.GetCompleteProgram, "/api/completeProgram"+tt.path, "", tt.userID)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var program models.CompleteProgram
+			if err := json.Unmarshal(rec.Body.Bytes(), &program); err != nil {
+				t.Fatalf("invalid body: %v", err)
+			}
+			if len(program.Exercises) != 2 || program.Exercises[0].Exercise.Names[0] != "Push Up" {
+				t.Errorf("items = %+v, want Push Up first", program.Exercises)
+			}
+			if program.Duration == nil || len(program.Duration.Sessions) != 1 || program.Duration.TotalSeconds <= 0 {
+				t.Errorf("duration = %+v, want one estimated session", program.Duration)
+			}
+		})
+	}
+}
+
+func TestPostProgram(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		userID     int64
+		status     int
+		visibility db.VisibilityT
+	}{
+		{name: "invalid json", body: `{"exerciseId": 1}`, status: http.StatusBadRequest},
+		{name: "unknown exercise", body: `[{"exerciseId": 42, "idx": 1, "sets": 3, "reps": 10}]`, status: http.StatusBadRequest},
+		{name: "custom exercise of another user", body: `[{"exerciseId": 100, "idx": 1, "sets": 3, "reps": 10}]`, userID: otherID, status: http.StatusBadRequest},
+		{name: "duplicate idx", body: `[{"exerciseId": 1, "idx": 1, "sets": 3, "reps": 10}, {"exerciseId": 2, "idx": 1, "sets": 5, "reps": 5}]`, status: http.StatusBadRequest},
+		{name: "missing reps", body: `[{"exerciseId": 1, "idx": 1, "sets": 3}]`, status: http.StatusBadRequest},
+		{name: "invalid prescription", body: `[{"exerciseId": 2, "idx": 1, "prescriptions": [{"reps": 5, "rpe": 11}]}]`, status: http.StatusBadRequest},
+		{name: "anonymous", body: `[{"exerciseId": 1, "idx": 1, "sets": 3, "reps": 10}]`, status: http.StatusOK, visibility: db.VisibilityTPublic},
+		{name: "authenticated", body: `[{"exerciseId": 100, "idx": 1, "sets": 3, "reps": 10}]`, userID: ownerID, status: http.StatusOK, visibility: db.VisibilityTPrivate},
+		{name: "prescriptions", body: `[{"exerciseId": 2, "idx": 1, "prescriptions": [{"reps": 5, "weightKg": 100}, {"reps": 3, "weightKg": 110}]}]`, status: http.StatusOK, visibility: db.VisibilityTPublic},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTestStore()
+			h := NewHandler(store, store)
+
+			rec := serve(t, http.MethodPost, "/api/program", h.PostProgram, "/api/program", tt.body, tt.userID)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var res struct {
+				ProgramID string `json:"program_id"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+				t.Fatalf("invalid body: %v", err)
+			}
+			var id pgtype.UUID
+			if err := id.Scan(res.ProgramID); err != nil {
+				t.Fatalf("invalid program_id %q: %v", res.ProgramID, err)
+			}
+
+			access, err := store.GetProgramAccess(context.Background(), id)
+			if err != nil {
+				t.Fatalf("program wasn't stored: %v", err)
+			}
+			if access.Visibility != tt.visibility {
+				t.Errorf("visibility = %s, want %s", access.Visibility, tt.visibility)
+			}
+			if access.OwnerID.Valid != (tt.userID != 0) || access.OwnerID.Int64 != tt.userID {
+				t.Errorf("owner = %+v, want %d", access.OwnerID, tt.userID)
+			}
+
+			var want []models.ProgramRecord
+			if err := json.Unmarshal([]byte(tt.body), &want); err != nil {
+				t.Fatal(err)
+			}
+			program, err := store.GetProgram(context.Background(), id)
+			if err != nil {
+				t.Fatalf("couldn't read the program back: %v", err)
+			}
+			if len(program.Exercises) != len(want) {
+				t.Fatalf("items = %+v, want %d", program.Exercises, len(want))
+			}
+			if got := program.Exercises[0]; got.ExerciseId != want[0].ExerciseId || got.Day != 1 || got.Sets == 0 {
+				t.Errorf("item = %+v, want exercise %d normalized", got, want[0].ExerciseId)
+			}
+		})
+	}
+}
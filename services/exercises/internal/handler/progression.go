@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/auth"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/models"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/progression"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/repository"
+)
+
+// GetNextSession godoc
+// @Summary      Propose the next session
+// @Description  Propose the prescription of the next session on a program day from the authenticated user's last logged workout on it
+// @Tags         programs
+// @Produce      json
+// @Param        uuid		path      string  	true	"Programs UUID"
+// @Param        day		query      int  	false	"Program day (default 1)"
+// @Param        rule		query      string  	false	"linear (default), double or rpe"
+// @Param        increment	query      number  	false	"Load increment in kg (default 2.5)"
+// @Param        rounding	query      number  	false	"Round loads to this many kg (default 2.5)"
+// @Param        minReps	query      int  	false	"Bottom of the rep range for double progression"
+// @Param        maxReps	query      int  	false	"Top of the rep range for double progression"
+// @Param        rpe		query      number  	false	"Target RPE for the rpe rule when the program has none (default 8)"
+// @Param        share		query      string  	false	"Share token of a link-only program"
+// @Success      200	{object}  models.NextSession
+// @Failure      400
+// @Failure      401
+// @Failure      404
+// @Failure      500
+// @Router       /api/program/{uuid}/next-session [get]
+func (h *Handler) GetNextSession(w http.ResponseWriter, r *http.Request) {
+	log.Println("GET /api/program/{uuid}/next-session endpoint called")
+	userID, _ := auth.UserID(r.Context())
+
+	var program_uuid pgtype.UUID
+	if err := program_uuid.Scan(chi.URLParam(r, "uuid")); err != nil {
+		log.Printf("Error scanning UUID Value from URL: %v, uuid: %s", err, chi.URLParam(r, "uuid"))
+		http.Error(w, "Invalid UUID", http.StatusBadRequest)
+		return
+	}
+
+	day := 1
+	if d, err := strconv.Atoi(r.URL.Query().Get("day")); err == nil && d > 0 {
+		day = d
+	}
+	cfg, err := progression.ParseConfig(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.readableProgram(r, program_uuid); err != nil {
+		writeAccessError(w, err, chi.URLParam(r, "uuid"))
+		return
+	}
+
+	program, err := repository.FetchProgram(r.Context(), db.Queriez, program_uuid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		http.Error(w, "Program not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error at GETting the program from DB: %v, uuid: %s", err, chi.URLParam(r, "uuid"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	plan := program.ProgramDay(day)
+	if len(plan) == 0 {
+		http.Error(w, "Program has no such day", http.StatusBadRequest)
+		return
+	}
+
+	session := models.NextSession{
+		ProgramID: program.UUID,
+		Day:       day,
+		Rule:      string(cfg.Rule),
+		Items:     make([]models.ProgressionItem, 0, len(plan)),
+	}
+
+	performed := make(map[int][]models.WorkoutSet)
+	last, err := db.Queriez.GetLastWorkoutForProgramDay(r.Context(), db.GetLastWorkoutForProgramDayParams{
+		UserID:    userID,
+		ProgramID: program_uuid,
+		Day:       int32(day),
+	})
+	switch {
+	case err == nil:
+		workoutID := uuid.UUID(last.ID.Bytes)
+		session.BasedOnWorkout = &workoutID
+		sets, err := db.Queriez.GetWorkoutSets(r.Context(), last.ID)
+		if err != nil {
+			log.Printf("Error at GETting the workout sets from DB: %v", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		for _, row := range sets {
+			set := models.WorkoutSetFromRow(row)
+			if set.ProgramIdx != nil {
+				performed[*set.ProgramIdx] = append(performed[*set.ProgramIdx], set)
+			}
+		}
+	case !errors.Is(err, pgx.ErrNoRows):
+		log.Printf("Error at GETting the last workout from DB: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	for _, item := range plan {
+		session.Items = append(session.Items, progression.Propose(cfg, item, performed[item.Idx]))
+	}
+
+	writeJSON(w, http.StatusOK, session)
+}
+
+// AcceptNextSession godoc
+// @Summary      Accept a proposed session
+// @Description  Write proposed prescriptions, as returned by next-session, into the program
+// @Tags         programs
+// @Accept       json
+// @Produce      json
+// @Param        uuid		path      string  	true	"Programs UUID"
+// @Param        proposal	body      models.AcceptNextSessionRequest  	true	"Items with their proposed prescriptions"
+// @Success      200	{object}  models.Program
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      404
+// @Failure      500
+// @Router       /api/program/{uuid}/next-session/accept [post]
+func (h *Handler) AcceptNextSession(w http.ResponseWriter, r *http.Request) {
+	log.Println("POST /api/program/{uuid}/next-session/accept endpoint called")
+	var program_uuid pgtype.UUID
+	if err := program_uuid.Scan(chi.URLParam(r, "uuid")); err != nil {
+		log.Printf("Error scanning UUID Value from URL: %v, uuid: %s", err, chi.URLParam(r, "uuid"))
+		http.Error(w, "Invalid UUID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.AcceptNextSessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Invalid JSON in AcceptNextSession: %v", err)
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.ownedProgram(r, program_uuid); err != nil {
+		writeAccessError(w, err, chi.URLParam(r, "uuid"))
+		return
+	}
+
+	program, err := repository.FetchProgram(r.Context(), db.Queriez, program_uuid)
+	if errors.Is(err, pgx.ErrNoRows) {
+		http.Error(w, "Program not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error at GETting the program from DB: %v, uuid: %s", err, chi.URLParam(r, "uuid"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	updated, err := applyProposal(program, req.Items)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := validateProgramRecords(r.Context(), h.Exercises, updated); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tx, err := db.GetPool().Begin(r.Context())
+	if err != nil {
+		log.Printf("Error at starting transaction: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback(r.Context())
+	qtx := db.Queriez.WithTx(tx)
+
+	for _, rec := range updated {
+		if err := replaceItemPrescriptions(r.Context(), qtx, program_uuid, rec); err != nil {
+			log.Printf("Error at updating program item: %v, uuid: %s, idx: %d", err, chi.URLParam(r, "uuid"), rec.Idx)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+	if _, err := repository.RecordRevision(r.Context(), qtx, program_uuid, db.RevisionSourceTProgression, pgtype.Int4{}); err != nil {
+		log.Printf("Error at recording program revision: %v, uuid: %s", err, chi.URLParam(r, "uuid"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("Error at committing program update: %v, uuid: %s", err, chi.URLParam(r, "uuid"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	program, err = repository.FetchProgram(r.Context(), db.Queriez, program_uuid)
+	if err != nil {
+		log.Printf("Error at GETting the program from DB: %v, uuid: %s", err, chi.URLParam(r, "uuid"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, program)
+}
+
+// applyProposal returns the program items touched by the proposal with their
+// prescriptions replaced by the proposed ones.
+func applyProposal(program *models.Program, items []models.ProgressionItem) ([]models.ProgramRecord, error) {
+	if len(items) == 0 {
+		return nil, errors.New("no items to accept")
+	}
+
+	byIdx := make(map[int]models.ProgramRecord, len(program.Exercises))
+	for _, rec := range program.Exercises {
+		byIdx[rec.Idx] = rec
+	}
+
+	updated := make([]models.ProgramRecord, 0, len(items))
+	for _, item := range items {
+		rec, ok := byIdx[item.Idx]
+		if !ok {
+			return nil, fmt.Errorf("item %d: not part of the program", item.Idx)
+		}
+		if item.ExerciseId != 0 && item.ExerciseId != rec.ExerciseId {
+			return nil, fmt.Errorf("item %d: exerciseId doesn't match the program", item.Idx)
+		}
+		if len(item.Proposed) == 0 {
+			return nil, fmt.Errorf("item %d: no proposed sets", item.Idx)
+		}
+		rec.Prescriptions = item.Proposed
+		updated = append(updated, rec)
+	}
+	return updated, nil
+}
+
+// replaceItemPrescriptions rewrites the set prescriptions of one program item
+// and its summary sets and reps.
+func replaceItemPrescriptions(ctx context.Context, q *db.Queries, programID pgtype.UUID, rec models.ProgramRecord) error {
+	rec.Normalize()
+	err := q.DeleteProgramSetsByIdx(ctx, db.DeleteProgramSetsByIdxParams{ProgramID: programID, Idx: int32(rec.Idx)})
+	if err != nil {
+		return err
+	}
+	for n, set := range rec.Prescriptions {
+		if err := q.InsertToProgramSets(ctx, set.InsertParams(programID, int32(rec.Idx), int32(n+1))); err != nil {
+			return err
+		}
+	}
+	return q.UpdateProgramItemVolume(ctx, db.UpdateProgramItemVolumeParams{
+		ProgramID: programID,
+		Idx:       int32(rec.Idx),
+		Sets:      int32(rec.Sets),
+		Reps:      int32(rec.Reps),
+	})
+}
@@ -0,0 +1,367 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/models"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/repository"
+)
+
+// UpdateProgram godoc
+// @Summary      Update a Program
+// @Description  Replace the items of a program, recording the result as a new revision. Items are matched on their idx, so logged sets keep pointing at the items that stay.
+// @Tags         revisions
+// @Accept       json
+// @Produce      json
+// @Param        uuid		path      string  	true	"Programs UUID"
+// @Param        items		body      []models.ProgramRecord  	true	"New items of the program"
+// @Success      200	{object}  models.ProgramRevision
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      404
+// @Failure      500
+// @Router       /api/program/{uuid} [put]
+func (h *Handler) UpdateProgram(w http.ResponseWriter, r *http.Request) {
+	log.Println("PUT /api/program/{uuid} endpoint called")
+	var program_uuid pgtype.UUID
+	if err := program_uuid.Scan(chi.URLParam(r, "uuid")); err != nil {
+		log.Printf("Error scanning UUID Value from URL: %v, uuid: %s", err, chi.URLParam(r, "uuid"))
+		http.Error(w, "Invalid UUID", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.ownedProgram(r, program_uuid); err != nil {
+		writeAccessError(w, err, chi.URLParam(r, "uuid"))
+		return
+	}
+
+	var records []models.ProgramRecord
+	if err := json.NewDecoder(r.Body).Decode(&records); err != nil {
+		log.Printf("Invalid JSON in UpdateProgram: %v", err)
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if len(records) == 0 {
+		http.Error(w, "a program needs at least one item", http.StatusBadRequest)
+		return
+	}
+	if err := validateProgramRecords(r.Context(), h.Exercises, records); err != nil {
+		log.Printf("Invalid program in UpdateProgram: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	revision, err := writeProgramRevision(r.Context(), program_uuid, records, db.RevisionSourceTUpdated, pgtype.Int4{})
+	if err != nil {
+		log.Printf("Error at updating program: %v, uuid: %s", err, chi.URLParam(r, "uuid"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeRevision(w, revision)
+}
+
+// GetProgramRevisions godoc
+// @Summary      List the revisions of a Program
+// @Description  List every revision of a program, newest first, without their items
+// @Tags         revisions
+// @Produce      json
+// @Param        uuid		path      string  	true	"Programs UUID"
+// @Param        share		query      string  	false	"Share token of a link-only program"
+// @Success      200	{array}  models.ProgramRevision
+// @Failure      400
+// @Failure      404
+// @Failure      500
+// @Router       /api/program/{uuid}/revisions [get]
+func (h *Handler) GetProgramRevisions(w http.ResponseWriter, r *http.Request) {
+	log.Println("GET /api/program/{uuid}/revisions endpoint called")
+	var program_uuid pgtype.UUID
+	if err := program_uuid.Scan(chi.URLParam(r, "uuid")); err != nil {
+		log.Printf("Error scanning UUID Value from URL: %v, uuid: %s", err, chi.URLParam(r, "uuid"))
+		http.Error(w, "Invalid UUID", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.readableProgram(r, program_uuid); err != nil {
+		writeAccessError(w, err, chi.URLParam(r, "uuid"))
+		return
+	}
+
+	rows, err := db.Queriez.GetProgramRevisions(r.Context(), program_uuid)
+	if err != nil {
+		log.Printf("Error at GETting the program revisions from DB: %v, uuid: %s", err, chi.URLParam(r, "uuid"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, models.ProgramRevisionsFromRows(rows))
+}
+
+// GetProgramRevision godoc
+// @Summary      Get a revision of a Program
+// @Description  Get the items of a program as they were at a revision
+// @Tags         revisions
+// @Produce      json
+// @Param        uuid		path      string  	true	"Programs UUID"
+// @Param        revision	path      int  	true	"Revision number"
+// @Param        share		query      string  	false	"Share token of a link-only program"
+// @Success      200	{object}  models.ProgramRevision
+// @Failure      400
+// @Failure      404
+// @Failure      500
+// @Router       /api/program/{uuid}/revisions/{revision} [get]
+func (h *Handler) GetProgramRevision(w http.ResponseWriter, r *http.Request) {
+	log.Println("GET /api/program/{uuid}/revisions/{revision} endpoint called")
+	var program_uuid pgtype.UUID
+	if err := program_uuid.Scan(chi.URLParam(r, "uuid")); err != nil {
+		log.Printf("Error scanning UUID Value from URL: %v, uuid: %s", err, chi.URLParam(r, "uuid"))
+		http.Error(w, "Invalid UUID", http.StatusBadRequest)
+		return
+	}
+	number, err := strconv.Atoi(chi.URLParam(r, "revision"))
+	if err != nil {
+		http.Error(w, "Invalid revision", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.readableProgram(r, program_uuid); err != nil {
+		writeAccessError(w, err, chi.URLParam(r, "uuid"))
+		return
+	}
+
+	revision, err := fetchRevision(r.Context(), program_uuid, number)
+	if errors.Is(err, pgx.ErrNoRows) {
+		http.Error(w, "Revision not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error at GETting the program revision from DB: %v, uuid: %s", err, chi.URLParam(r, "uuid"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, revision)
+}
+
+// GetProgramDiff godoc
+// @Summary      Compare two revisions of a Program
+// @Description  List the items added, removed and modified between two revisions. to defaults to the latest revision and from to the one before it.
+// @Tags         revisions
+// @Produce      json
+// @Param        uuid		path      string  	true	"Programs UUID"
+// @Param        from		query      int  	false	"Older revision"
+// @Param        to		query      int  	false	"Newer revision"
+// @Param        share		query      string  	false	"Share token of a link-only program"
+// @Success      200	{object}  models.ProgramDiff
+// @Failure      400
+// @Failure      404
+// @Failure      500
+// @Router       /api/program/{uuid}/diff [get]
+func (h *Handler) GetProgramDiff(w http.ResponseWriter, r *http.Request) {
+	log.Println("GET /api/program/{uuid}/diff endpoint called")
+	var program_uuid pgtype.UUID
+	if err := program_uuid.Scan(chi.URLParam(r, "uuid")); err != nil {
+		log.Printf("Error scanning UUID Value from URL: %v, uuid: %s", err, chi.URLParam(r, "uuid"))
+		http.Error(w, "Invalid UUID", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.readableProgram(r, program_uuid); err != nil {
+		writeAccessError(w, err, chi.URLParam(r, "uuid"))
+		return
+	}
+
+	to, err := revisionParam(r, "to")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if to == 0 {
+		rows, err := db.Queriez.GetProgramRevisions(r.Context(), program_uuid)
+		if err != nil {
+			log.Printf("Error at GETting the program revisions from DB: %v, uuid: %s", err, chi.URLParam(r, "uuid"))
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		if len(rows) == 0 {
+			http.Error(w, "Revision not found", http.StatusNotFound)
+			return
+		}
+		to = int(rows[0].Revision)
+	}
+	from, err := revisionParam(r, "from")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if from == 0 {
+		from = max(to-1, 1)
+	}
+
+	revisions := make([]*models.ProgramRevision, 0, 2)
+	for _, number := range []int{from, to} {
+		revision, err := fetchRevision(r.Context(), program_uuid, number)
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, "Revision "+strconv.Itoa(number)+" not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Printf("Error at GETting the program revision from DB: %v, uuid: %s", err, chi.URLParam(r, "uuid"))
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		revisions = append(revisions, revision)
+	}
+	writeJSON(w, http.StatusOK, models.DiffRevisions(revisions[0], revisions[1]))
+}
+
+// RestoreProgramRevision godoc
+// @Summary      Restore a revision of a Program
+// @Description  Bring the items of a program back to a revision. History is kept: the restored items are recorded as a new revision.
+// @Tags         revisions
+// @Produce      json
+// @Param        uuid		path      string  	true	"Programs UUID"
+// @Param        revision	path      int  	true	"Revision number"
+// @Success      200	{object}  models.ProgramRevision
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      404
+// @Failure      409
+// @Failure      500
+// @Router       /api/program/{uuid}/revisions/{revision}/restore [post]
+func (h *Handler) RestoreProgramRevision(w http.ResponseWriter, r *http.Request) {
+	log.Println("POST /api/program/{uuid}/revisions/{revision}/restore endpoint called")
+	var program_uuid pgtype.UUID
+	if err := program_uuid.Scan(chi.URLParam(r, "uuid")); err != nil {
+		log.Printf("Error scanning UUID Value from URL: %v, uuid: %s", err, chi.URLParam(r, "uuid"))
+		http.Error(w, "Invalid UUID", http.StatusBadRequest)
+		return
+	}
+	number, err := strconv.Atoi(chi.URLParam(r, "revision"))
+	if err != nil {
+		http.Error(w, "Invalid revision", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.ownedProgram(r, program_uuid); err != nil {
+		writeAccessError(w, err, chi.URLParam(r, "uuid"))
+		return
+	}
+
+	old, err := fetchRevision(r.Context(), program_uuid, number)
+	if errors.Is(err, pgx.ErrNoRows) {
+		http.Error(w, "Revision not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error at GETting the program revision from DB: %v, uuid: %s", err, chi.URLParam(r, "uuid"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// Exercises may have been removed from the catalog since
+	if err := validateProgramRecords(r.Context(), h.Exercises, old.Exercises); err != nil {
+		http.Error(w, "the revision can't be restored: "+err.Error(), http.StatusConflict)
+		return
+	}
+
+	restoredFrom := pgtype.Int4{Int32: int32(number), Valid: true}
+	revision, err := writeProgramRevision(r.Context(), program_uuid, old.Exercises, db.RevisionSourceTRestored, restoredFrom)
+	if err != nil {
+		log.Printf("Error at restoring program revision: %v, uuid: %s", err, chi.URLParam(r, "uuid"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeRevision(w, revision)
+}
+
+// writeProgramRevision replaces the items of a program and records them as a
+// new revision, in one transaction.
+func writeProgramRevision(ctx context.Context, programID pgtype.UUID, records []models.ProgramRecord, source db.RevisionSourceT, restoredFrom pgtype.Int4) (db.ProgramRevision, error) {
+	tx, err := db.GetPool().Begin(ctx)
+	if err != nil {
+		return db.ProgramRevision{}, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := db.Queriez.WithTx(tx)
+
+	if err := replaceProgramRecords(ctx, qtx, programID, records); err != nil {
+		return db.ProgramRevision{}, err
+	}
+	revision, err := repository.RecordRevision(ctx, qtx, programID, source, restoredFrom)
+	if err != nil {
+		return revision, err
+	}
+	return revision, tx.Commit(ctx)
+}
+
+// replaceProgramRecords rewrites the items of a program. Items are
+// overwritten in place rather than deleted, so the workout sets logged
+// against an idx that is kept stay linked to it.
+func replaceProgramRecords(ctx context.Context, q *db.Queries, programID pgtype.UUID, records []models.ProgramRecord) error {
+	idxs := make([]int32, 0, len(records))
+	for _, rec := range records {
+		idxs = append(idxs, int32(rec.Idx))
+	}
+	if err := q.DeleteProgramItemsExcept(ctx, db.DeleteProgramItemsExceptParams{ProgramID: programID, Idxs: idxs}); err != nil {
+		return err
+	}
+
+	for _, rec := range records {
+		rec.Normalize()
+		err := q.UpsertProgramItem(ctx, db.UpsertProgramItemParams{
+			ID:         programID,
+			Idx:        int32(rec.Idx),
+			Day:        int32(rec.Day),
+			ExerciseID: int32(rec.ExerciseId),
+			Sets:       int32(rec.Sets),
+			Reps:       int32(rec.Reps),
+		})
+		if err != nil {
+			return err
+		}
+		if err := replaceItemPrescriptions(ctx, q, programID, rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func fetchRevision(ctx context.Context, programID pgtype.UUID, number int) (*models.ProgramRevision, error) {
+	row, err := db.Queriez.GetProgramRevision(ctx, db.GetProgramRevisionParams{ProgramID: programID, Revision: int32(number)})
+	if err != nil {
+		return nil, err
+	}
+	return models.ProgramRevisionFromRow(row)
+}
+
+func revisionParam(r *http.Request, name string) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, errors.New(name + " must be a positive revision number")
+	}
+	return n, nil
+}
+
+func writeRevision(w http.ResponseWriter, row db.ProgramRevision) {
+	revision, err := models.ProgramRevisionFromRow(row)
+	if err != nil {
+		log.Printf("Error at Unmarshaling program revision: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, revision)
+}
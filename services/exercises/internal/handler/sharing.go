@@ -0,0 +1,412 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/auth"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/models"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/repository"
+)
+
+// errNotOwner is returned for changes to a program the caller can read but
+// doesn't own.
+var errNotOwner = errors.New("only the owner of the program can do this")
+
+// shareQueryParam carries the token of a share link.
+const shareQueryParam = "share"
+
+// readableProgram returns the access row of a program the caller can read,
+// either directly, through the share link in the request or because a coach
+// assigned it to them. Programs that can't be read are reported as missing,
+// pgx.ErrNoRows, so their existence isn't leaked.
+func (h *Handler) readableProgram(r *http.Request, programID pgtype.UUID) (db.ProgramAccess, error) {
+	return h.readableProgramWithToken(r.Context(), programID, r.URL.Query().Get(shareQueryParam))
+}
+
+// readableProgramWithToken is readableProgram with the share token passed
+// in, for callers that don't carry it in the query string.
+func (h *Handler) readableProgramWithToken(ctx context.Context, programID pgtype.UUID, token string) (db.ProgramAccess, error) {
+	return readableProgramFrom(ctx, h.Programs, programID, token)
+}
+
+// readableProgramFrom is readableProgramWithToken over the given programs.
+func readableProgramFrom(ctx context.Context, programs repository.Programs, programID pgtype.UUID, token string) (db.ProgramAccess, error) {
+	access, err := programs.GetProgramAccess(ctx, programID)
+	if err != nil {
+		return access, err
+	}
+
+	userID, ok := auth.UserID(ctx)
+	if models.CanRead(access, userID, ok) {
+		return access, nil
+	}
+
+	if token != "" && access.Visibility == db.VisibilityTLink {
+		valid, err := programs.HasShareToken(ctx, programID, token)
+		if err != nil {
+			return access, err
+		}
+		if valid {
+			return access, nil
+		}
+	}
+
+	if ok {
+		assigned, err := programs.IsProgramAssigned(ctx, programID, userID)
+		if err != nil {
+			return access, err
+		}
+		if assigned {
+			return access, nil
+		}
+	}
+	return access, pgx.ErrNoRows
+}
+
+// ownedProgram returns the access row of a program the caller owns, which
+// they alone can change or share. Programs without an owner can't be
+// changed, only forked.
+func (h *Handler) ownedProgram(r *http.Request, programID pgtype.UUID) (db.ProgramAccess, error) {
+	access, err := h.readableProgram(r, programID)
+	if err != nil {
+		return access, err
+	}
+	userID, ok := auth.UserID(r.Context())
+	if !models.IsOwner(access, userID, ok) {
+		return access, errNotOwner
+	}
+	return access, nil
+}
+
+// writeAccessError reports the errors of the access helpers above.
+func writeAccessError(w http.ResponseWriter, err error, programID string) {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		http.Error(w, "Program not found", http.StatusNotFound)
+	case errors.Is(err, errNotOwner):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	default:
+		log.Printf("Error at GETting the program access from DB: %v, uuid: %s", err, programID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+func newShareToken() (string, error) {
+	b := make([]byte, 18)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// GetProgramSharing godoc
+// @Summary      Get the sharing settings of a Program
+// @Description  Get the visibility and active share links of a program owned by the authenticated user
+// @Tags         sharing
+// @Produce      json
+// @Param        uuid		path      string  	true	"Programs UUID"
+// @Success      200	{object}  models.ProgramSharing
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      404
+// @Failure      500
+// @Router       /api/program/{uuid}/sharing [get]
+func (h *Handler) GetProgramSharing(w http.ResponseWriter, r *http.Request) {
+	log.Println("GET /api/program/{uuid}/sharing endpoint called")
+	var program_uuid pgtype.UUID
+	if err := program_uuid.Scan(chi.URLParam(r, "uuid")); err != nil {
+		log.Printf("Error scanning UUID Value from URL: %v, uuid: %s", err, chi.URLParam(r, "uuid"))
+		http.Error(w, "Invalid UUID", http.StatusBadRequest)
+		return
+	}
+
+	access, err := h.ownedProgram(r, program_uuid)
+	if err != nil {
+		writeAccessError(w, err, chi.URLParam(r, "uuid"))
+		return
+	}
+
+	tokens, err := db.Queriez.GetShareTokens(r.Context(), program_uuid)
+	if err != nil {
+		log.Printf("Error at GETting the share tokens from DB: %v, uuid: %s", err, chi.URLParam(r, "uuid"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, models.ProgramSharingFromRows(access, tokens))
+}
+
+// PutProgramVisibility godoc
+// @Summary      Change the visibility of a Program
+// @Description  Make a program owned by the authenticated user private, readable through share links only, or public
+// @Tags         sharing
+// @Accept       json
+// @Produce      json
+// @Param        uuid		path      string  	true	"Programs UUID"
+// @Param        visibility	body      models.VisibilityRequest  	true	"private, link or public"
+// @Success      200	{object}  models.ProgramSharing
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      404
+// @Failure      500
+// @Router       /api/program/{uuid}/visibility [put]
+func (h *Handler) PutProgramVisibility(w http.ResponseWriter, r *http.Request) {
+	log.Println("PUT /api/program/{uuid}/visibility endpoint called")
+	var program_uuid pgtype.UUID
+	if err := program_uuid.Scan(chi.URLParam(r, "uuid")); err != nil {
+		log.Printf("Error scanning UUID Value from URL: %v, uuid: %s", err, chi.URLParam(r, "uuid"))
+		http.Error(w, "Invalid UUID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.VisibilityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Invalid JSON in PutProgramVisibility: %v", err)
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	switch req.Visibility {
+	case db.VisibilityTPrivate, db.VisibilityTLink, db.VisibilityTPublic:
+	default:
+		http.Error(w, "visibility must be private, link or public", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.ownedProgram(r, program_uuid); err != nil {
+		writeAccessError(w, err, chi.URLParam(r, "uuid"))
+		return
+	}
+
+	access, err := db.Queriez.UpdateProgramVisibility(r.Context(), db.UpdateProgramVisibilityParams{
+		ProgramID:  program_uuid,
+		Visibility: req.Visibility,
+	})
+	if err != nil {
+		log.Printf("Error at updating program visibility: %v, uuid: %s", err, chi.URLParam(r, "uuid"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	tokens, err := db.Queriez.GetShareTokens(r.Context(), program_uuid)
+	if err != nil {
+		log.Printf("Error at GETting the share tokens from DB: %v, uuid: %s", err, chi.URLParam(r, "uuid"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, models.ProgramSharingFromRows(access, tokens))
+}
+
+// PostShareLink godoc
+// @Summary      Create a share link
+// @Description  Create a share link for a program owned by the authenticated user. A private program becomes readable through share links.
+// @Tags         sharing
+// @Produce      json
+// @Param        uuid		path      string  	true	"Programs UUID"
+// @Success      201	{object}  models.ShareLink
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      404
+// @Failure      500
+// @Router       /api/program/{uuid}/share-links [post]
+func (h *Handler) PostShareLink(w http.ResponseWriter, r *http.Request) {
+	log.Println("POST /api/program/{uuid}/share-links endpoint called")
+	userID, _ := auth.UserID(r.Context())
+
+	var program_uuid pgtype.UUID
+	if err := program_uuid.Scan(chi.URLParam(r, "uuid")); err != nil {
+		log.Printf("Error scanning UUID Value from URL: %v, uuid: %s", err, chi.URLParam(r, "uuid"))
+		http.Error(w, "Invalid UUID", http.StatusBadRequest)
+		return
+	}
+
+	access, err := h.ownedProgram(r, program_uuid)
+	if err != nil {
+		writeAccessError(w, err, chi.URLParam(r, "uuid"))
+		return
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		log.Printf("Error at generating share token: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	tx, err := db.GetPool().Begin(r.Context())
+	if err != nil {
+		log.Printf("Error at starting transaction: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback(r.Context())
+	qtx := db.Queriez.WithTx(tx)
+
+	if access.Visibility == db.VisibilityTPrivate {
+		_, err := qtx.UpdateProgramVisibility(r.Context(), db.UpdateProgramVisibilityParams{
+			ProgramID:  program_uuid,
+			Visibility: db.VisibilityTLink,
+		})
+		if err != nil {
+			log.Printf("Error at updating program visibility: %v, uuid: %s", err, chi.URLParam(r, "uuid"))
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	row, err := qtx.InsertShareToken(r.Context(), db.InsertShareTokenParams{
+		Token:     token,
+		ProgramID: program_uuid,
+		CreatedBy: userID,
+	})
+	if err != nil {
+		log.Printf("Error at inserting share token: %v, uuid: %s", err, chi.URLParam(r, "uuid"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("Error at committing share token: %v, uuid: %s", err, chi.URLParam(r, "uuid"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, models.ShareLinkFromRow(row))
+}
+
+// DeleteShareLink godoc
+// @Summary      Revoke a share link
+// @Description  Revoke a share link of a program owned by the authenticated user
+// @Tags         sharing
+// @Param        uuid		path      string  	true	"Programs UUID"
+// @Param        token		path      string  	true	"Share token"
+// @Success      204
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      404
+// @Failure      500
+// @Router       /api/program/{uuid}/share-links/{token} [delete]
+func (h *Handler) DeleteShareLink(w http.ResponseWriter, r *http.Request) {
+	log.Println("DELETE /api/program/{uuid}/share-links/{token} endpoint called")
+	var program_uuid pgtype.UUID
+	if err := program_uuid.Scan(chi.URLParam(r, "uuid")); err != nil {
+		log.Printf("Error scanning UUID Value from URL: %v, uuid: %s", err, chi.URLParam(r, "uuid"))
+		http.Error(w, "Invalid UUID", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.ownedProgram(r, program_uuid); err != nil {
+		writeAccessError(w, err, chi.URLParam(r, "uuid"))
+		return
+	}
+
+	revoked, err := db.Queriez.RevokeShareToken(r.Context(), db.RevokeShareTokenParams{
+		ProgramID: program_uuid,
+		Token:     chi.URLParam(r, "token"),
+	})
+	if err != nil {
+		log.Printf("Error at revoking share token: %v, uuid: %s", err, chi.URLParam(r, "uuid"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if revoked == 0 {
+		http.Error(w, "Share link not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ForkProgram godoc
+// @Summary      Fork a Program
+// @Description  Copy a program the authenticated user can read, possibly through a share link, into a new private program they own
+// @Tags         sharing
+// @Produce      json
+// @Param        uuid		path      string  	true	"Programs UUID"
+// @Param        share		query      string  	false	"Share token of a link-only program"
+// @Success      201	{object}  models.Program
+// @Failure      400
+// @Failure      401
+// @Failure      404
+// @Failure      500
+// @Router       /api/program/{uuid}/fork [post]
+func (h *Handler) ForkProgram(w http.ResponseWriter, r *http.Request) {
+	log.Println("POST /api/program/{uuid}/fork endpoint called")
+	var source_uuid pgtype.UUID
+	if err := source_uuid.Scan(chi.URLParam(r, "uuid")); err != nil {
+		log.Printf("Error scanning UUID Value from URL: %v, uuid: %s", err, chi.URLParam(r, "uuid"))
+		http.Error(w, "Invalid UUID", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.readableProgram(r, source_uuid); err != nil {
+		writeAccessError(w, err, chi.URLParam(r, "uuid"))
+		return
+	}
+
+	programID := uuid.New()
+	pg_uuid := pgtype.UUID{Bytes: programID, Valid: true}
+
+	tx, err := db.GetPool().Begin(r.Context())
+	if err != nil {
+		log.Printf("Error at starting transaction: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback(r.Context())
+	qtx := db.Queriez.WithTx(tx)
+
+	log.Printf("Forking program %s into %s", chi.URLParam(r, "uuid"), programID)
+	copies := db.CopyProgramItemsParams{NewID: pg_uuid, SourceID: source_uuid}
+	if err := qtx.CopyProgramItems(r.Context(), copies); err != nil {
+		log.Printf("Error at copying program items: %v, uuid: %s", err, chi.URLParam(r, "uuid"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if err := qtx.CopyProgramSets(r.Context(), db.CopyProgramSetsParams(copies)); err != nil {
+		log.Printf("Error at copying program sets: %v, uuid: %s", err, chi.URLParam(r, "uuid"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if err := repository.InsertProgramAccess(r.Context(), qtx, pg_uuid, source_uuid); err != nil {
+		log.Printf("Error at inserting program access: %v, program_id: %s", err, programID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if _, err := repository.RecordRevision(r.Context(), qtx, pg_uuid, db.RevisionSourceTForked, pgtype.Int4{}); err != nil {
+		log.Printf("Error at recording program revision: %v, program_id: %s", err, programID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	program, err := repository.FetchProgram(r.Context(), qtx, pg_uuid)
+	if err != nil {
+		log.Printf("Error at GETting the program from DB: %v, program_id: %s", err, programID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("Error at committing fork: %v, program_id: %s", err, programID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, program)
+}
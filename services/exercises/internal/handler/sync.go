@@ -0,0 +1,470 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/auth"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/models"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/repository"
+)
+
+// maxSyncChanges is the most changes a client may push at once.
+const maxSyncChanges = 500
+
+// GetSync godoc
+// @Summary      Pull the changes since a checkpoint
+// @Description  Get the programs, workouts with their sets, favorites and catalog exercises of the authenticated user changed after the checkpoint, with the rows deleted since. Without a checkpoint everything is returned. Pass the checkpoint of the response on the next pull; rows changed around it may come twice and replace the copy of the client by ID
+// @Tags         sync
+// @Produce      json
+// @Param        checkpoint	query      string  	false	"Checkpoint of the previous pull"
+// @Success      200	{object}  models.SyncPull
+// @Failure      400
+// @Failure      401
+// @Failure      500
+// @Router       /api/sync [get]
+func GetSync(w http.ResponseWriter, r *http.Request) {
+	log.Println("GET /api/sync endpoint called")
+	userID, _ := auth.UserID(r.Context())
+
+	since, err := models.ParseCheckpoint(r.URL.Query().Get("checkpoint"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Every row is read from the same snapshot, taken at the clock the
+	// checkpoint is issued from
+	tx, err := db.GetPool().BeginTx(r.Context(), pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		log.Printf("Error at starting transaction: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback(r.Context())
+	qtx := db.Queriez.WithTx(tx)
+
+	pull, err := pullChanges(r.Context(), qtx, userID, since)
+	if err != nil {
+		log.Printf("Error at pulling changes: %v, user: %d", err, userID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, pull)
+}
+
+// PostSync godoc
+// @Summary      Push the changes made offline
+// @Description  Apply the changes made offline by the authenticated user, in order, each on its own. Changes are favorite.add and favorite.remove ({exerciseId}), workout.start ({id, programId, day, startedAt}), set.log ({id, workoutId, performedAt} and the fields of a logged set), workout.finish ({workoutId, finishedAt, notes}) and program.update ({programId, baseRevision, items}). IDs are generated by the client, so pushing a change again reports it as a duplicate. The server wins conflicts: sets logged or finishes of a workout finished already and updates of a program whose latest revision isn't baseRevision are not applied, the result carries the state of the server
+// @Tags         sync
+// @Accept       json
+// @Produce      json
+// @Param        changes	body      models.SyncPush  	true	"Changes in the order they were made"
+// @Success      200	{object}  models.SyncPushResponse
+// @Failure      400
+// @Failure      401
+// @Failure      500
+// @Router       /api/sync [post]
+func (h *Handler) PostSync(w http.ResponseWriter, r *http.Request) {
+	log.Println("POST /api/sync endpoint called")
+	userID, _ := auth.UserID(r.Context())
+
+	var req models.SyncPush
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Invalid JSON in PostSync: %v", err)
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if len(req.Changes) > maxSyncChanges {
+		http.Error(w, "at most 500 changes can be pushed at once", http.StatusBadRequest)
+		return
+	}
+
+	// The changes applied before an error stay applied, pushing them
+	// again reports them as duplicates
+	results := make([]models.SyncResult, 0, len(req.Changes))
+	for _, change := range req.Changes {
+		result, err := h.applySyncChange(r, userID, change)
+		if err != nil {
+			log.Printf("Error at applying %s change: %v, user: %d", change.Type, err, userID)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		result.ID = change.ID
+		results = append(results, result)
+	}
+
+	writeJSON(w, http.StatusOK, models.SyncPushResponse{Results: results})
+}
+
+func pullChanges(ctx context.Context, q *db.Queries, userID int64, since pgtype.Timestamptz) (*models.SyncPull, error) {
+	clock, err := q.GetSyncClock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pull := &models.SyncPull{Checkpoint: models.EncodeCheckpoint(clock.Time)}
+
+	programRows, err := q.GetProgramsChangedSince(ctx, db.GetProgramsChangedSinceParams{OwnerID: userID, Since: since})
+	if err != nil {
+		return nil, err
+	}
+	pull.Programs = make([]models.SyncProgram, 0, len(programRows))
+	for _, row := range programRows {
+		program, err := syncProgram(ctx, q, row)
+		if err != nil {
+			return nil, err
+		}
+		pull.Programs = append(pull.Programs, program)
+	}
+
+	workoutRows, err := q.GetWorkoutsChangedSince(ctx, db.GetWorkoutsChangedSinceParams{UserID: userID, Since: since})
+	if err != nil {
+		return nil, err
+	}
+	var setRows []db.WorkoutSet
+	if len(workoutRows) > 0 {
+		ids := make([]pgtype.UUID, 0, len(workoutRows))
+		for _, row := range workoutRows {
+			ids = append(ids, row.ID)
+		}
+		if setRows, err = q.GetWorkoutSetsByWorkoutIds(ctx, ids); err != nil {
+			return nil, err
+		}
+	}
+	pull.Workouts = models.SyncWorkoutsFromRows(workoutRows, setRows)
+
+	favoriteRows, err := q.GetFavoritesChangedSince(ctx, db.GetFavoritesChangedSinceParams{UserID: userID, Since: since})
+	if err != nil {
+		return nil, err
+	}
+	pull.Favorites = models.SyncFavoritesFromRows(favoriteRows)
+
+	exerciseRows, err := q.GetExercisesChangedSince(ctx, db.GetExercisesChangedSinceParams{Since: since, ViewerID: viewerID(ctx)})
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]db.GetExercisesRow, 0, len(exerciseRows))
+	for _, row := range exerciseRows {
+		rows = append(rows, db.GetExercisesRow(row))
+	}
+	pull.Exercises = *models.ExerciseFromRows(rows)
+
+	tombstones, err := q.GetSyncTombstonesSince(ctx, db.GetSyncTombstonesSinceParams{UserID: userID, Since: since})
+	if err != nil {
+		return nil, err
+	}
+	pull.Deleted = models.SyncTombstonesFromRows(tombstones)
+	return pull, nil
+}
+
+// syncProgram attaches the items to a changed program.
+func syncProgram(ctx context.Context, q *db.Queries, row db.GetProgramsChangedSinceRow) (models.SyncProgram, error) {
+	program := models.SyncProgram{
+		ID:         row.ProgramID.Bytes,
+		Visibility: row.Visibility,
+		Revision:   int(row.Revision),
+		Items:      []models.ProgramRecord{},
+		UpdatedAt:  row.UpdatedAt.Time,
+	}
+	items, err := repository.FetchProgram(ctx, q, row.ProgramID)
+	switch {
+	case err == nil:
+		program.Items = items.Exercises
+	case !errors.Is(err, pgx.ErrNoRows):
+		return program, err
+	}
+	return program, nil
+}
+
+// applySyncChange applies one pushed change. Changes the client can't make
+// are rejected, the error is only returned when the server failed.
+func (h *Handler) applySyncChange(r *http.Request, userID int64, change models.SyncChange) (models.SyncResult, error) {
+	switch change.Type {
+	case models.SyncFavoriteAdd, models.SyncFavoriteRemove:
+		var data models.SyncFavoriteChange
+		if err := json.Unmarshal(change.Data, &data); err != nil {
+			return syncRejected("invalid data"), nil
+		}
+		return syncFavorite(r.Context(), userID, data, change.Type == models.SyncFavoriteAdd)
+	case models.SyncWorkoutStart:
+		var data models.SyncWorkoutStartChange
+		if err := json.Unmarshal(change.Data, &data); err != nil {
+			return syncRejected("invalid data"), nil
+		}
+		return h.syncWorkoutStart(r, userID, data)
+	case models.SyncSetLog:
+		var data models.SyncSetLogChange
+		if err := json.Unmarshal(change.Data, &data); err != nil {
+			return syncRejected("invalid data"), nil
+		}
+		return syncSetLog(r.Context(), userID, data)
+	case models.SyncWorkoutFinish:
+		var data models.SyncWorkoutFinishChange
+		if err := json.Unmarshal(change.Data, &data); err != nil {
+			return syncRejected("invalid data"), nil
+		}
+		return syncWorkoutFinish(r.Context(), userID, data)
+	case models.SyncProgramUpdate:
+		var data models.SyncProgramUpdateChange
+		if err := json.Unmarshal(change.Data, &data); err != nil {
+			return syncRejected("invalid data"), nil
+		}
+		return h.syncProgramUpdate(r, data)
+	}
+	return syncRejected("unknown change type"), nil
+}
+
+func syncFavorite(ctx context.Context, userID int64, data models.SyncFavoriteChange, add bool) (models.SyncResult, error) {
+	if data.ExerciseID <= 0 {
+		return syncRejected("exerciseId is required"), nil
+	}
+	exerciseID := int32(data.ExerciseID)
+
+	if !add {
+		deleted, err := removeFavorite(ctx, userID, exerciseID)
+		if err != nil {
+			return models.SyncResult{}, err
+		}
+		if deleted == 0 {
+			return models.SyncResult{Status: models.SyncDuplicate}, nil
+		}
+		return models.SyncResult{Status: models.SyncApplied}, nil
+	}
+
+	if err := checkExercisesExist(ctx, []int32{exerciseID}); err != nil {
+		if errors.Is(err, errUnknownExercise) {
+			return syncRejected(err.Error()), nil
+		}
+		return models.SyncResult{}, err
+	}
+	if err := addFavorite(ctx, userID, exerciseID); err != nil {
+		return models.SyncResult{}, err
+	}
+	return models.SyncResult{Status: models.SyncApplied}, nil
+}
+
+func (h *Handler) syncWorkoutStart(r *http.Request, userID int64, data models.SyncWorkoutStartChange) (models.SyncResult, error) {
+	ctx := r.Context()
+	if data.ID == uuid.Nil {
+		return syncRejected("id is required"), nil
+	}
+	if data.Day == 0 {
+		data.Day = 1
+	}
+
+	programID := pgtype.UUID{Bytes: data.ProgramID, Valid: true}
+	if _, err := h.readableProgram(r, programID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return syncRejected("program not found"), nil
+		}
+		return models.SyncResult{}, err
+	}
+	program, err := repository.FetchProgram(ctx, db.Queriez, programID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return syncRejected("program not found"), nil
+	}
+	if err != nil {
+		return models.SyncResult{}, err
+	}
+	if len(program.ProgramDay(data.Day)) == 0 {
+		return syncRejected("program has no such day"), nil
+	}
+
+	params := db.InsertWorkoutParams{
+		ID:        pgtype.UUID{Bytes: data.ID, Valid: true},
+		UserID:    userID,
+		ProgramID: programID,
+		Day:       int32(data.Day),
+	}
+	if !data.StartedAt.IsZero() {
+		params.StartedAt = pgtype.Timestamptz{Time: data.StartedAt, Valid: true}
+	}
+	_, err = startWorkout(ctx, params)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// The ID exists already
+		row, err := db.Queriez.GetWorkoutById(ctx, params.ID)
+		if err != nil {
+			return models.SyncResult{}, err
+		}
+		if row.UserID != userID {
+			return syncRejected("id is taken"), nil
+		}
+		return models.SyncResult{Status: models.SyncDuplicate}, nil
+	}
+	if err != nil {
+		return models.SyncResult{}, err
+	}
+	return models.SyncResult{Status: models.SyncApplied}, nil
+}
+
+func syncSetLog(ctx context.Context, userID int64, data models.SyncSetLogChange) (models.SyncResult, error) {
+	if data.ID == uuid.Nil {
+		return syncRejected("id is required"), nil
+	}
+	if err := data.Validate(); err != nil {
+		return syncRejected(err.Error()), nil
+	}
+	row, ok, err := syncUserWorkout(ctx, userID, data.WorkoutID)
+	if !ok || err != nil {
+		return syncRejected("workout not found"), err
+	}
+
+	logged, err := db.Queriez.GetWorkoutSets(ctx, row.ID)
+	if err != nil {
+		return models.SyncResult{}, err
+	}
+	for _, s := range logged {
+		if s.ClientID.Valid && uuid.UUID(s.ClientID.Bytes) == data.ID {
+			return models.SyncResult{Status: models.SyncDuplicate}, nil
+		}
+	}
+	if row.FinishedAt.Valid {
+		return syncWorkoutConflict(ctx, row)
+	}
+
+	exerciseID, err := resolveSetExercise(ctx, row, data.LogSetRequest)
+	if err != nil {
+		return syncRejected(err.Error()), nil
+	}
+	params := data.InsertParams(row, exerciseID, models.NextSetNumber(logged, exerciseID, data.ProgramIdx))
+	params.ClientID = pgtype.UUID{Bytes: data.ID, Valid: true}
+	if !data.PerformedAt.IsZero() {
+		params.PerformedAt = pgtype.Timestamptz{Time: data.PerformedAt, Valid: true}
+	}
+
+	_, err = logWorkoutSet(ctx, row, params)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Logged by a concurrent push
+		return models.SyncResult{Status: models.SyncDuplicate}, nil
+	}
+	if err != nil {
+		return models.SyncResult{}, err
+	}
+	return models.SyncResult{Status: models.SyncApplied}, nil
+}
+
+func syncWorkoutFinish(ctx context.Context, userID int64, data models.SyncWorkoutFinishChange) (models.SyncResult, error) {
+	row, ok, err := syncUserWorkout(ctx, userID, data.WorkoutID)
+	if !ok || err != nil {
+		return syncRejected("workout not found"), err
+	}
+
+	// The database keeps microseconds
+	finishedAt := data.FinishedAt.Truncate(time.Microsecond)
+	if row.FinishedAt.Valid {
+		if !finishedAt.IsZero() && row.FinishedAt.Time.Equal(finishedAt) {
+			return models.SyncResult{Status: models.SyncDuplicate}, nil
+		}
+		return syncWorkoutConflict(ctx, row)
+	}
+
+	params := models.FinishWorkoutRequest{Notes: data.Notes}.Params(row.ID)
+	if !finishedAt.IsZero() {
+		params.FinishedAt = pgtype.Timestamptz{Time: finishedAt, Valid: true}
+	}
+	_, err = finishWorkout(ctx, params)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Finished by a concurrent request
+		row, err := db.Queriez.GetWorkoutById(ctx, row.ID)
+		if err != nil {
+			return models.SyncResult{}, err
+		}
+		return syncWorkoutConflict(ctx, row)
+	}
+	if err != nil {
+		return models.SyncResult{}, err
+	}
+	return models.SyncResult{Status: models.SyncApplied}, nil
+}
+
+func (h *Handler) syncProgramUpdate(r *http.Request, data models.SyncProgramUpdateChange) (models.SyncResult, error) {
+	ctx := r.Context()
+	programID := pgtype.UUID{Bytes: data.ProgramID, Valid: true}
+	access, err := h.ownedProgram(r, programID)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return syncRejected("program not found"), nil
+	case errors.Is(err, errNotOwner):
+		return syncRejected(err.Error()), nil
+	case err != nil:
+		return models.SyncResult{}, err
+	}
+
+	if len(data.Items) == 0 {
+		return syncRejected("a program needs at least one item"), nil
+	}
+	if err := validateProgramRecords(ctx, h.Exercises, data.Items); err != nil {
+		return syncRejected(err.Error()), nil
+	}
+
+	tx, err := db.GetPool().Begin(ctx)
+	if err != nil {
+		return models.SyncResult{}, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := db.Queriez.WithTx(tx)
+
+	latest, err := qtx.LockProgramRevision(ctx, programID)
+	if err != nil {
+		return models.SyncResult{}, err
+	}
+	if int(latest) != data.BaseRevision {
+		program, err := syncProgram(ctx, qtx, db.GetProgramsChangedSinceRow{
+			ProgramID:  programID,
+			Visibility: access.Visibility,
+			UpdatedAt:  access.UpdatedAt,
+			Revision:   latest,
+		})
+		if err != nil {
+			return models.SyncResult{}, err
+		}
+		return models.SyncResult{Status: models.SyncConflict, Server: program}, nil
+	}
+
+	if err := replaceProgramRecords(ctx, qtx, programID, data.Items); err != nil {
+		return models.SyncResult{}, err
+	}
+	if _, err := repository.RecordRevision(ctx, qtx, programID, db.RevisionSourceTUpdated, pgtype.Int4{}); err != nil {
+		return models.SyncResult{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return models.SyncResult{}, err
+	}
+	return models.SyncResult{Status: models.SyncApplied}, nil
+}
+
+// syncUserWorkout loads a workout of the user, ok is false when it doesn't
+// exist or belongs to someone else.
+func syncUserWorkout(ctx context.Context, userID int64, workoutID uuid.UUID) (db.Workout, bool, error) {
+	row, err := db.Queriez.GetWorkoutById(ctx, pgtype.UUID{Bytes: workoutID, Valid: true})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return row, false, nil
+	}
+	if err != nil {
+		return row, false, err
+	}
+	return row, row.UserID == userID, nil
+}
+
+// syncWorkoutConflict reports a change to a workout finished on the server.
+func syncWorkoutConflict(ctx context.Context, row db.Workout) (models.SyncResult, error) {
+	workout, err := completeWorkout(ctx, row)
+	if err != nil {
+		return models.SyncResult{}, err
+	}
+	return models.SyncResult{Status: models.SyncConflict, Error: "workout already finished", Server: workout}, nil
+}
+
+func syncRejected(message string) models.SyncResult {
+	return models.SyncResult{Status: models.SyncRejected, Error: message}
+}
@@ -0,0 +1,344 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/auth"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/events"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/models"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/webhooks"
+)
+
+// maxWebhooks is how many webhooks a user may register.
+const maxWebhooks = 10
+
+// PostWebhook godoc
+// @Summary      Register a webhook
+// @Description  Register a URL to be called back on events of the authenticated user: program.created, program.updated, workout.started, workout.set_logged or workout.completed. The returned secret signs the payloads and is only shown once
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        webhook	body      models.WebhookRequest  	true	"URL and events"
+// @Success      201	{object}  models.Webhook
+// @Failure      400
+// @Failure      401
+// @Failure      409
+// @Failure      500
+// @Router       /api/webhooks [post]
+func PostWebhook(w http.ResponseWriter, r *http.Request) {
+	log.Println("POST /api/webhooks endpoint called")
+	userID, _ := auth.UserID(r.Context())
+
+	var req models.WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Invalid JSON in PostWebhook: %v", err)
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if err := validateWebhookRequest(r.Context(), &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	hooks, err := db.Queriez.GetWebhooks(r.Context(), userID)
+	if err != nil {
+		log.Printf("Error at GETting the webhooks from DB: %v, user: %d", err, userID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if len(hooks) >= maxWebhooks {
+		http.Error(w, fmt.Sprintf("a user can register at most %d webhooks", maxWebhooks), http.StatusConflict)
+		return
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		log.Printf("Error at generating webhook secret: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	row, err := db.Queriez.InsertWebhook(r.Context(), db.InsertWebhookParams{
+		UserID: userID,
+		Url:    req.URL,
+		Secret: secret,
+		Events: req.Events,
+	})
+	if err != nil {
+		log.Printf("Error at inserting webhook: %v, user: %d", err, userID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	webhook := models.WebhookFromRow(row)
+	webhook.Secret = row.Secret
+	writeJSON(w, http.StatusCreated, webhook)
+}
+
+// GetWebhooks godoc
+// @Summary      List webhooks
+// @Description  List the webhooks of the authenticated user, without their secrets
+// @Tags         webhooks
+// @Produce      json
+// @Success      200	{array}  models.Webhook
+// @Failure      401
+// @Failure      500
+// @Router       /api/webhooks [get]
+func GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	log.Println("GET /api/webhooks endpoint called")
+	userID, _ := auth.UserID(r.Context())
+
+	rows, err := db.Queriez.GetWebhooks(r.Context(), userID)
+	if err != nil {
+		log.Printf("Error at GETting the webhooks from DB: %v, user: %d", err, userID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	webhooks := make([]models.Webhook, 0, len(rows))
+	for _, row := range rows {
+		webhooks = append(webhooks, models.WebhookFromRow(row))
+	}
+	writeJSON(w, http.StatusOK, webhooks)
+}
+
+// DeleteWebhook godoc
+// @Summary      Delete a webhook
+// @Description  Remove a webhook of the authenticated user along with its deliveries
+// @Tags         webhooks
+// @Param        id		path      string  	true	"Webhook UUID"
+// @Success      204
+// @Failure      400
+// @Failure      401
+// @Failure      404
+// @Failure      500
+// @Router       /api/webhooks/{id} [delete]
+func DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	log.Println("DELETE /api/webhooks/{id} endpoint called")
+	userID, _ := auth.UserID(r.Context())
+
+	var webhook_uuid pgtype.UUID
+	if err := webhook_uuid.Scan(chi.URLParam(r, "id")); err != nil {
+		log.Printf("Error scanning UUID Value from URL: %v, uuid: %s", err, chi.URLParam(r, "id"))
+		http.Error(w, "Invalid UUID", http.StatusBadRequest)
+		return
+	}
+
+	deleted, err := db.Queriez.DeleteWebhook(r.Context(), db.DeleteWebhookParams{ID: webhook_uuid, UserID: userID})
+	if err != nil {
+		log.Printf("Error at deleting webhook: %v, uuid: %s", err, chi.URLParam(r, "id"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if deleted == 0 {
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetWebhookDeliveries godoc
+// @Summary      List the deliveries of a webhook
+// @Description  List the events sent, or waiting to be sent, to a webhook of the authenticated user, newest first, with the outcome of their last attempt and the history of every attempt
+// @Tags         webhooks
+// @Produce      json
+// @Param        id		path      string  	true	"Webhook UUID"
+// @Param        status	query      string  	false	"pending, succeeded or failed"
+// @Param		 limit		query		int		false	"Limit"
+// @Param		 offset		query		int		false	"Offset"
+// @Success      200	{array}  models.WebhookDelivery
+// @Failure      400
+// @Failure      401
+// @Failure      404
+// @Failure      500
+// @Router       /api/webhooks/{id}/deliveries [get]
+func GetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	log.Println("GET /api/webhooks/{id}/deliveries endpoint called")
+	hook, ok := userWebhook(w, r)
+	if !ok {
+		return
+	}
+	limit, offset := pagination(r)
+
+	params := db.GetWebhookDeliveriesParams{
+		WebhookID: hook.ID,
+		Limit:     int32(limit),
+		Offset:    int32(offset),
+	}
+	if status := r.URL.Query().Get("status"); status != "" {
+		switch db.DeliveryStatusT(status) {
+		case db.DeliveryStatusTPending, db.DeliveryStatusTSucceeded, db.DeliveryStatusTFailed:
+			params.Status = db.NullDeliveryStatusT{DeliveryStatusT: db.DeliveryStatusT(status), Valid: true}
+		default:
+			http.Error(w, "status must be pending, succeeded or failed", http.StatusBadRequest)
+			return
+		}
+	}
+
+	rows, err := db.Queriez.GetWebhookDeliveries(r.Context(), params)
+	if err != nil {
+		log.Printf("Error at GETting the webhook deliveries from DB: %v, uuid: %s", err, chi.URLParam(r, "id"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	ids := make([]pgtype.UUID, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+	attemptRows, err := db.Queriez.GetWebhookDeliveryAttempts(r.Context(), ids)
+	if err != nil {
+		log.Printf("Error at GETting the webhook delivery attempts from DB: %v, uuid: %s", err, chi.URLParam(r, "id"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	attempts := make(map[pgtype.UUID][]models.WebhookDeliveryAttempt)
+	for _, row := range attemptRows {
+		attempts[row.DeliveryID] = append(attempts[row.DeliveryID], models.WebhookDeliveryAttemptFromRow(row))
+	}
+
+	deliveries := make([]models.WebhookDelivery, 0, len(rows))
+	for _, row := range rows {
+		delivery := models.WebhookDeliveryFromRow(row)
+		delivery.AttemptHistory = attempts[row.ID]
+		deliveries = append(deliveries, delivery)
+	}
+	writeJSON(w, http.StatusOK, deliveries)
+}
+
+// ReplayWebhookDelivery godoc
+// @Summary      Replay a failed delivery
+// @Description  Send a delivery that ran out of attempts again, with a fresh set of attempts
+// @Tags         webhooks
+// @Produce      json
+// @Param        id			path      string  	true	"Webhook UUID"
+// @Param        deliveryId	path      string  	true	"Delivery UUID"
+// @Success      202	{object}  models.WebhookDelivery
+// @Failure      400
+// @Failure      401
+// @Failure      404
+// @Failure      409
+// @Failure      500
+// @Router       /api/webhooks/{id}/deliveries/{deliveryId}/replay [post]
+func ReplayWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	log.Println("POST /api/webhooks/{id}/deliveries/{deliveryId}/replay endpoint called")
+	hook, ok := userWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	var delivery_uuid pgtype.UUID
+	if err := delivery_uuid.Scan(chi.URLParam(r, "deliveryId")); err != nil {
+		log.Printf("Error scanning UUID Value from URL: %v, uuid: %s", err, chi.URLParam(r, "deliveryId"))
+		http.Error(w, "Invalid UUID", http.StatusBadRequest)
+		return
+	}
+
+	row, err := db.Queriez.ReplayWebhookDelivery(r.Context(), db.ReplayWebhookDeliveryParams{ID: delivery_uuid, WebhookID: hook.ID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		http.Error(w, "Delivery not found or not failed", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("Error at replaying webhook delivery: %v, uuid: %s", err, chi.URLParam(r, "deliveryId"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, models.WebhookDeliveryFromRow(row))
+}
+
+// ReplayFailedWebhookDeliveries godoc
+// @Summary      Replay the failed deliveries of a webhook
+// @Description  Send every delivery of a webhook that ran out of attempts again, e.g. once the endpoint is back up
+// @Tags         webhooks
+// @Produce      json
+// @Param        id		path      string  	true	"Webhook UUID"
+// @Success      202	{object}  map[string]int
+// @Failure      400
+// @Failure      401
+// @Failure      404
+// @Failure      500
+// @Router       /api/webhooks/{id}/replay [post]
+func ReplayFailedWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	log.Println("POST /api/webhooks/{id}/replay endpoint called")
+	hook, ok := userWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	replayed, err := db.Queriez.ReplayFailedWebhookDeliveries(r.Context(), hook.ID)
+	if err != nil {
+		log.Printf("Error at replaying webhook deliveries: %v, uuid: %s", err, chi.URLParam(r, "id"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]int{"replayed": int(replayed)})
+}
+
+// userWebhook loads the webhook named in the URL and makes sure it belongs
+// to the authenticated user. Webhooks of other users are reported as not
+// found. On failure the response has already been written.
+func userWebhook(w http.ResponseWriter, r *http.Request) (db.Webhook, bool) {
+	userID, _ := auth.UserID(r.Context())
+
+	var webhook_uuid pgtype.UUID
+	if err := webhook_uuid.Scan(chi.URLParam(r, "id")); err != nil {
+		log.Printf("Error scanning UUID Value from URL: %v, uuid: %s", err, chi.URLParam(r, "id"))
+		http.Error(w, "Invalid UUID", http.StatusBadRequest)
+		return db.Webhook{}, false
+	}
+
+	hook, err := db.Queriez.GetWebhook(r.Context(), db.GetWebhookParams{ID: webhook_uuid, UserID: userID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+		return db.Webhook{}, false
+	}
+	if err != nil {
+		log.Printf("Error at GETting the webhook from DB: %v, uuid: %s", err, chi.URLParam(r, "id"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return db.Webhook{}, false
+	}
+	return hook, true
+}
+
+// validateWebhookRequest checks the URL is an absolute http(s) URL of a
+// public host and the events are known, dropping repeated ones.
+func validateWebhookRequest(ctx context.Context, req *models.WebhookRequest) error {
+	if err := webhooks.CheckURL(ctx, req.URL); err != nil {
+		return err
+	}
+	if len(req.Events) == 0 {
+		return errors.New("at least one event is required")
+	}
+	eventTypes := make([]string, 0, len(req.Events))
+	for _, eventType := range req.Events {
+		if !events.IsType(eventType) {
+			return fmt.Errorf("unknown event %q", eventType)
+		}
+		if !slices.Contains(eventTypes, eventType) {
+			eventTypes = append(eventTypes, eventType)
+		}
+	}
+	req.Events = eventTypes
+	return nil
+}
+
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
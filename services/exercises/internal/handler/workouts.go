@@ -0,0 +1,418 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/analytics"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/auth"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/events"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/live"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/models"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/repository"
+)
+
+// StartWorkout godoc
+// @Summary      Start a workout
+// @Description  Start a workout session for the authenticated user from a day of a program
+// @Tags         workouts
+// @Accept       json
+// @Produce      json
+// @Param        workout	body      models.StartWorkoutRequest  	true	"Program and day to train"
+// @Param        share		query      string  	false	"Share token of a link-only program"
+// @Success      201	{object}  models.Workout
+// @Failure      400
+// @Failure      401
+// @Failure      404
+// @Failure      500
+// @Router       /api/workouts [post]
+func (h *Handler) StartWorkout(w http.ResponseWriter, r *http.Request) {
+	log.Println("POST /api/workouts endpoint called")
+	userID, _ := auth.UserID(r.Context())
+
+	var req models.StartWorkoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Invalid JSON in StartWorkout: %v", err)
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if req.Day == 0 {
+		req.Day = 1
+	}
+
+	programID := pgtype.UUID{Bytes: req.ProgramID, Valid: true}
+	if _, err := h.readableProgram(r, programID); err != nil {
+		writeAccessError(w, err, req.ProgramID.String())
+		return
+	}
+
+	program, err := repository.FetchProgram(r.Context(), db.Queriez, programID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		http.Error(w, "Program not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error at GETting the program from DB: %v, uuid: %s", err, req.ProgramID)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	plan := program.ProgramDay(req.Day)
+	if len(plan) == 0 {
+		http.Error(w, "Program has no such day", http.StatusBadRequest)
+		return
+	}
+
+	log.Printf("Starting workout for user: %d, program: %s, day: %d", userID, req.ProgramID, req.Day)
+	row, err := startWorkout(r.Context(), db.InsertWorkoutParams{
+		UserID:    userID,
+		ProgramID: programID,
+		Day:       int32(req.Day),
+	})
+	if err != nil {
+		log.Printf("Error at inserting workout: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, models.WorkoutFromRows(row, plan, nil))
+}
+
+// GetWorkouts godoc
+// @Summary      List workouts
+// @Description  Get the workout history of the authenticated user, most recent first
+// @Tags         workouts
+// @Produce      json
+// @Param		 limit		query		int		false	"Limit"
+// @Param		 offset		query		int		false	"Offset"
+// @Success      200	{array}  models.WorkoutSummary
+// @Failure      401
+// @Failure      500
+// @Router       /api/workouts [get]
+func GetWorkouts(w http.ResponseWriter, r *http.Request) {
+	log.Println("GET /api/workouts endpoint called")
+	userID, _ := auth.UserID(r.Context())
+	limit, offset := pagination(r)
+
+	rows, err := db.Queriez.GetWorkoutsByUser(r.Context(), db.GetWorkoutsByUserParams{
+		UserID: userID,
+		Limit:  int32(limit),
+		Offset: int32(offset),
+	})
+	if err != nil {
+		log.Printf("Couldn't Fetch workouts from db: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, models.WorkoutSummariesFromRows(rows))
+}
+
+// GetWorkout godoc
+// @Summary      Get Workout by ID
+// @Description  Get a workout of the authenticated user with its plan and logged sets
+// @Tags         workouts
+// @Produce      json
+// @Param        id		path      string  	true	"Workout UUID"
+// @Success      200	{object}  models.Workout
+// @Failure      401
+// @Failure      404
+// @Failure      500
+// @Router       /api/workouts/{id} [get]
+func GetWorkout(w http.ResponseWriter, r *http.Request) {
+	log.Println("GET /api/workouts/{id} endpoint called")
+	row, ok := userWorkout(w, r)
+	if !ok {
+		return
+	}
+
+	workout, err := completeWorkout(r.Context(), row)
+	if err != nil {
+		log.Printf("Error at GETting the workout from DB: %v, id: %s", err, chi.URLParam(r, "id"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, workout)
+}
+
+// PostWorkoutSet godoc
+// @Summary      Log a set
+// @Description  Record a performed set in an unfinished workout, either against a program item or off-plan
+// @Tags         workouts
+// @Accept       json
+// @Produce      json
+// @Param        id		path      string  	true	"Workout UUID"
+// @Param        set	body      models.LogSetRequest  	true	"Performed set"
+// @Success      201	{object}  models.WorkoutSet
+// @Failure      400
+// @Failure      401
+// @Failure      404
+// @Failure      409
+// @Failure      500
+// @Router       /api/workouts/{id}/sets [post]
+func PostWorkoutSet(w http.ResponseWriter, r *http.Request) {
+	log.Println("POST /api/workouts/{id}/sets endpoint called")
+	row, ok := userWorkout(w, r)
+	if !ok {
+		return
+	}
+	if row.FinishedAt.Valid {
+		http.Error(w, "Workout already finished", http.StatusConflict)
+		return
+	}
+
+	var req models.LogSetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Invalid JSON in PostWorkoutSet: %v", err)
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	exerciseID, err := resolveSetExercise(r.Context(), row, req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	logged, err := db.Queriez.GetWorkoutSets(r.Context(), row.ID)
+	if err != nil {
+		log.Printf("Error at GETting the workout sets from DB: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	setNumber := models.NextSetNumber(logged, exerciseID, req.ProgramIdx)
+
+	set, err := logWorkoutSet(r.Context(), row, req.InsertParams(row, exerciseID, setNumber))
+	if err != nil {
+		log.Printf("Error at inserting workout set: %v, id: %s", err, chi.URLParam(r, "id"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, set)
+}
+
+// FinishWorkout godoc
+// @Summary      Finish a workout
+// @Description  Mark a workout of the authenticated user as finished, optionally with notes, and report the personal records it broke
+// @Tags         workouts
+// @Accept       json
+// @Produce      json
+// @Param        id		path      string  	true	"Workout UUID"
+// @Param        notes	body      models.FinishWorkoutRequest  	false	"Closing notes"
+// @Success      200	{object}  models.Workout
+// @Failure      400
+// @Failure      401
+// @Failure      404
+// @Failure      409
+// @Failure      500
+// @Router       /api/workouts/{id}/finish [post]
+func FinishWorkout(w http.ResponseWriter, r *http.Request) {
+	log.Println("POST /api/workouts/{id}/finish endpoint called")
+	row, ok := userWorkout(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.FinishWorkoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		log.Printf("Invalid JSON in FinishWorkout: %v", err)
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	workout, err := finishWorkout(r.Context(), req.Params(row.ID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		http.Error(w, "Workout already finished", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("Error at finishing workout: %v, id: %s", err, chi.URLParam(r, "id"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, workout)
+}
+
+// startWorkout inserts a workout with its started event. An ID already
+// taken by the user's workout is left as is, sync retries the start.
+func startWorkout(ctx context.Context, params db.InsertWorkoutParams) (db.Workout, error) {
+	tx, err := db.GetPool().Begin(ctx)
+	if err != nil {
+		return db.Workout{}, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := db.Queriez.WithTx(tx)
+
+	row, err := qtx.InsertWorkout(ctx, params)
+	if err != nil {
+		return db.Workout{}, err
+	}
+	if err := recordWorkoutEvent(ctx, qtx, events.WorkoutStarted, row, nil); err != nil {
+		return db.Workout{}, err
+	}
+	return row, tx.Commit(ctx)
+}
+
+// logWorkoutSet inserts a set of an in-progress workout with its event and
+// sends it to the devices following the workout.
+func logWorkoutSet(ctx context.Context, row db.Workout, params db.InsertWorkoutSetParams) (models.WorkoutSet, error) {
+	tx, err := db.GetPool().Begin(ctx)
+	if err != nil {
+		return models.WorkoutSet{}, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := db.Queriez.WithTx(tx)
+
+	inserted, err := qtx.InsertWorkoutSet(ctx, params)
+	if err != nil {
+		return models.WorkoutSet{}, err
+	}
+	if err := qtx.TouchWorkout(ctx, row.ID); err != nil {
+		return models.WorkoutSet{}, err
+	}
+	set := models.WorkoutSetFromRow(inserted)
+	if err := recordWorkoutEvent(ctx, qtx, events.WorkoutSetLogged, row, &set); err != nil {
+		return models.WorkoutSet{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return models.WorkoutSet{}, err
+	}
+
+	if err := liveSessions.Publish(uuid.UUID(row.ID.Bytes), live.SetLogged, set); err != nil {
+		log.Printf("Error at publishing logged set: %v, id: %s", err, uuid.UUID(row.ID.Bytes))
+	}
+	return set, nil
+}
+
+// finishWorkout finishes an in-progress workout, updating the analytics
+// and personal records, and ends its live session. pgx.ErrNoRows means
+// the workout was finished already.
+func finishWorkout(ctx context.Context, params db.FinishWorkoutParams) (*models.Workout, error) {
+	tx, err := db.GetPool().Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := db.Queriez.WithTx(tx)
+
+	finished, err := qtx.FinishWorkout(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	records, err := analytics.ProcessWorkout(ctx, qtx, finished)
+	if err != nil {
+		return nil, err
+	}
+	if err := recordWorkoutEvent(ctx, qtx, events.WorkoutCompleted, finished, nil); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	workout, err := completeWorkout(ctx, finished)
+	if err != nil {
+		return nil, err
+	}
+	workout.NewRecords = models.PersonalRecordsFromRows(records)
+	if err := liveSessions.End(uuid.UUID(finished.ID.Bytes), workout); err != nil {
+		log.Printf("Error at ending live workout: %v, id: %s", err, uuid.UUID(finished.ID.Bytes))
+	}
+	return workout, nil
+}
+
+// recordWorkoutEvent records an event of the workout, q is expected to run
+// inside the transaction that changed it.
+func recordWorkoutEvent(ctx context.Context, q *db.Queries, eventType string, row db.Workout, set *models.WorkoutSet) error {
+	payload := events.WorkoutEvent(row)
+	payload.Set = set
+	return events.Record(ctx, q, eventType, payload.WorkoutID.String(), payload)
+}
+
+// userWorkout loads the workout named in the URL and makes sure it belongs
+// to the authenticated user. Workouts of other users are reported as not
+// found. On failure the response has already been written.
+func userWorkout(w http.ResponseWriter, r *http.Request) (db.Workout, bool) {
+	userID, _ := auth.UserID(r.Context())
+
+	var workoutID pgtype.UUID
+	if err := workoutID.Scan(chi.URLParam(r, "id")); err != nil {
+		log.Printf("Error scanning UUID Value from URL: %v, id: %s", err, chi.URLParam(r, "id"))
+		http.Error(w, "Invalid UUID", http.StatusBadRequest)
+		return db.Workout{}, false
+	}
+
+	row, err := db.Queriez.GetWorkoutById(r.Context(), workoutID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && row.UserID != userID) {
+		http.Error(w, "Workout not found", http.StatusNotFound)
+		return db.Workout{}, false
+	}
+	if err != nil {
+		log.Printf("Error at GETting the workout from DB: %v, id: %s", err, chi.URLParam(r, "id"))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return db.Workout{}, false
+	}
+	return row, true
+}
+
+// completeWorkout attaches the program day and the logged sets to a workout.
+func completeWorkout(ctx context.Context, row db.Workout) (*models.Workout, error) {
+	var plan []models.ProgramRecord
+	program, err := repository.FetchProgram(ctx, db.Queriez, row.ProgramID)
+	switch {
+	case err == nil:
+		plan = program.ProgramDay(int(row.Day))
+	case !errors.Is(err, pgx.ErrNoRows):
+		return nil, err
+	}
+
+	sets, err := db.Queriez.GetWorkoutSets(ctx, row.ID)
+	if err != nil {
+		return nil, err
+	}
+	return models.WorkoutFromRows(row, plan, sets), nil
+}
+
+// resolveSetExercise finds the exercise a logged set is about, either from
+// the planned item it refers to or from the off-plan exercise ID.
+func resolveSetExercise(ctx context.Context, row db.Workout, req models.LogSetRequest) (int32, error) {
+	if req.ProgramIdx != nil {
+		program, err := repository.FetchProgram(ctx, db.Queriez, row.ProgramID)
+		if err != nil {
+			return 0, errors.New("program of the workout is not available")
+		}
+		for _, item := range program.ProgramDay(int(row.Day)) {
+			if item.Idx != *req.ProgramIdx {
+				continue
+			}
+			if req.ExerciseId != nil && *req.ExerciseId != item.ExerciseId {
+				return 0, errors.New("exerciseId doesn't match the program item")
+			}
+			return int32(item.ExerciseId), nil
+		}
+		return 0, errors.New("programIdx is not part of the workout's day")
+	}
+
+	rows, err := db.Queriez.GetExerciseCategories(ctx, db.GetExerciseCategoriesParams{ExerciseIds: []int32{int32(*req.ExerciseId)}, ViewerID: viewerID(ctx)})
+	if err != nil || len(rows) == 0 {
+		return 0, errors.New("unknown exercise")
+	}
+	return rows[0].ID, nil
+}
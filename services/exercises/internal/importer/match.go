@@ -0,0 +1,240 @@
+package importer
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/models"
+)
+
+const (
+	// matchScore is the similarity above which a name is taken as a match,
+	// as long as no other exercise comes within matchMargin of it.
+	matchScore  = 0.85
+	matchMargin = 0.05
+	// candidateScore is the similarity above which an exercise is offered
+	// as a candidate for review.
+	candidateScore = 0.55
+	maxCandidates  = 3
+	// wordScore is the similarity above which two words count as the same.
+	wordScore = 0.8
+)
+
+type catalogName struct {
+	exerciseID int
+	name       string
+	normalized string
+	sorted     string
+}
+
+// Matcher finds catalog exercises by name, tolerating case, punctuation,
+// word order and typos.
+type Matcher struct {
+	names []catalogName
+	exact map[string]catalogName
+	byID  map[int]catalogName
+}
+
+func NewMatcher(rows []db.GetExerciseNamesRow) *Matcher {
+	m := &Matcher{
+		names: make([]catalogName, 0, len(rows)),
+		exact: make(map[string]catalogName, len(rows)),
+		byID:  make(map[int]catalogName, len(rows)),
+	}
+	for _, row := range rows {
+		n := catalogName{
+			exerciseID: int(row.ExerciseID),
+			name:       row.Name,
+			normalized: normalize(row.Name),
+		}
+		n.sorted = sortWords(n.normalized)
+		m.names = append(m.names, n)
+		if _, ok := m.exact[n.normalized]; !ok {
+			m.exact[n.normalized] = n
+		}
+		if _, ok := m.byID[n.exerciseID]; !ok {
+			m.byID[n.exerciseID] = n
+		}
+	}
+	return m
+}
+
+// Match sets the status, exercise and candidates of every entry. Entries
+// that carry a known exercise ID, like sheets from the CSV export, match it
+// directly.
+func (m *Matcher) Match(entries []models.ImportEntry) {
+	for i := range entries {
+		m.match(&entries[i])
+	}
+}
+
+func (m *Matcher) match(e *models.ImportEntry) {
+	e.Candidates = nil
+
+	if e.ExerciseId != nil {
+		if n, ok := m.byID[*e.ExerciseId]; ok {
+			m.accept(e, n, 1)
+			return
+		}
+		e.ExerciseId = nil
+	}
+
+	normalized := normalize(e.Name)
+	if n, ok := m.exact[normalized]; ok {
+		m.accept(e, n, 1)
+		return
+	}
+
+	// Keep the best scoring name of every exercise. Only the spelling counts
+	// towards an automatic match, shared words only rank the candidates.
+	sorted := sortWords(normalized)
+	words := strings.Fields(normalized)
+	best := make(map[int]models.ImportCandidate)
+	spelling := make(map[int]float64)
+	for _, n := range m.names {
+		spelled := max(similarity(normalized, n.normalized), similarity(sorted, n.sorted))
+		score := max(spelled, sharedWords(words, strings.Fields(n.normalized)))
+		if c, ok := best[n.exerciseID]; !ok || score > c.Score {
+			best[n.exerciseID] = models.ImportCandidate{ExerciseId: n.exerciseID, Name: n.name, Score: round(score)}
+		}
+		spelling[n.exerciseID] = max(spelling[n.exerciseID], spelled)
+	}
+
+	candidates := make([]models.ImportCandidate, 0, len(best))
+	for _, c := range best {
+		if c.Score >= candidateScore {
+			candidates = append(candidates, c)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		return candidates[i].ExerciseId < candidates[j].ExerciseId
+	})
+
+	switch {
+	case len(candidates) == 0:
+		e.Status = models.EntryUnknown
+		e.ExerciseId = nil
+	case spelling[candidates[0].ExerciseId] >= matchScore && (len(candidates) == 1 || candidates[0].Score-candidates[1].Score >= matchMargin):
+		top := candidates[0]
+		m.accept(e, catalogName{exerciseID: top.ExerciseId, name: top.Name}, top.Score)
+	default:
+		e.Status = models.EntryAmbiguous
+		e.ExerciseId = nil
+		e.Candidates = candidates[:min(len(candidates), maxCandidates)]
+	}
+}
+
+func (m *Matcher) accept(e *models.ImportEntry, n catalogName, score float64) {
+	id := n.exerciseID
+	e.Status = models.EntryMatched
+	e.Error = ""
+	e.ExerciseId = &id
+	e.MatchedName = n.name
+	e.Score = round(score)
+}
+
+// Resolve applies a decision of the review step, reporting false for an
+// exercise that isn't in the catalog or a resolution that decides nothing.
+func (m *Matcher) Resolve(e *models.ImportEntry, r models.ImportResolution) bool {
+	if r.Skip {
+		e.Status = models.EntrySkipped
+		e.Error = ""
+		e.ExerciseId = nil
+		e.MatchedName = ""
+		e.Score = 0
+		return true
+	}
+	if len(r.Prescriptions) > 0 {
+		e.Prescriptions = r.Prescriptions
+	}
+	if r.ExerciseId == nil {
+		// Fixing the prescriptions only keeps the matched exercise
+		if e.ExerciseId == nil || len(r.Prescriptions) == 0 {
+			return false
+		}
+		e.Status = models.EntryMatched
+		return true
+	}
+	n, ok := m.byID[*r.ExerciseId]
+	if !ok {
+		return false
+	}
+	m.accept(e, n, 1)
+	e.Candidates = nil
+	return true
+}
+
+// normalize lowercases a name and keeps only its letters and digits, words
+// separated by single spaces.
+func normalize(s string) string {
+	fields := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(fields, " ")
+}
+
+func sortWords(s string) string {
+	words := strings.Fields(s)
+	sort.Strings(words)
+	return strings.Join(words, " ")
+}
+
+// similarity is 1 minus the edit distance relative to the longer string.
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// sharedWords is the Dice coefficient of the words of both names, words
+// spelled alike counting as shared.
+func sharedWords(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	used := make([]bool, len(b))
+	for _, wa := range a {
+		for j, wb := range b {
+			if !used[j] && similarity(wa, wb) >= wordScore {
+				used[j] = true
+				shared++
+				break
+			}
+		}
+	}
+	return 2 * float64(shared) / float64(len(a)+len(b))
+}
+
+func round(score float64) float64 {
+	return math.Round(score*100) / 100
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
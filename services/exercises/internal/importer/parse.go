@@ -0,0 +1,359 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/models"
+)
+
+// headerSearchRows bounds how far down a sheet the header row is looked for,
+// coaches often put a title or notes above it.
+const headerSearchRows = 10
+
+// columns maps the header names understood in sheets, normalised by
+// normalizeHeader, to the field they fill.
+var columns = map[string]string{
+	"day": "day", "session": "day", "workout": "day",
+	"exercise": "exercise", "exercise_name": "exercise", "name": "exercise", "movement": "exercise", "lift": "exercise",
+	"exercise_id": "exercise_id", "exerciseid": "exercise_id",
+	"set": "set", "set_number": "set", "set_no": "set",
+	"sets": "sets",
+	"reps": "reps", "repetitions": "reps", "rep": "reps",
+	"weight": "weight", "weight_kg": "weight", "load": "weight", "kg": "weight",
+	"one_rm_percent": "one_rm_percent", "%1rm": "one_rm_percent", "percent": "one_rm_percent", "intensity": "one_rm_percent",
+	"rpe":   "rpe",
+	"rir":   "rir",
+	"tempo": "tempo",
+	"rest":  "rest", "rest_seconds": "rest",
+	"duration": "duration", "duration_seconds": "duration", "time": "duration",
+	"distance": "distance", "distance_meters": "distance",
+}
+
+// Parse reads the program entries of a CSV or XLSX sheet, the format being
+// picked from the file name or, failing that, the content. Rows of a sheet
+// with a set column, like the CSV export, are grouped into one entry per
+// exercise; otherwise every row is an entry repeated for its sets.
+func Parse(filename string, data []byte) ([]models.ImportEntry, error) {
+	var table [][]string
+	var err error
+	if isXLSX(filename, data) {
+		table, err = readXLSX(data)
+	} else {
+		table, err = readCSV(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return entriesFromTable(table)
+}
+
+func isXLSX(filename string, data []byte) bool {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".xlsx", ".xlsm":
+		return true
+	case ".csv", ".txt":
+		return false
+	}
+	// XLSX files are zip archives
+	return bytes.HasPrefix(data, []byte("PK\x03\x04"))
+}
+
+func readCSV(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	// Spreadsheets in many locales export with semicolons
+	head := data
+	if lines := bytes.SplitN(data, []byte("\n"), headerSearchRows+1); len(lines) > headerSearchRows {
+		head = data[:len(data)-len(lines[headerSearchRows])]
+	}
+	if bytes.Count(head, []byte(";")) > bytes.Count(head, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	table, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+	return table, nil
+}
+
+// readXLSX reads the first sheet of the workbook.
+func readXLSX(data []byte) ([][]string, error) {
+	book, err := excelize.OpenReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid XLSX: %w", err)
+	}
+	defer book.Close()
+
+	sheets := book.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, errors.New("the workbook has no sheet")
+	}
+	table, err := book.GetRows(sheets[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid XLSX: %w", err)
+	}
+	return table, nil
+}
+
+func normalizeHeader(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.NewReplacer(" ", "_", "-", "_", "(", "", ")", "").Replace(s)
+	return s
+}
+
+// findHeader returns the row of the header and the column of every field it
+// names.
+func findHeader(table [][]string) (int, map[string]int, error) {
+	for i := 0; i < len(table) && i < headerSearchRows; i++ {
+		fields := make(map[string]int)
+		for col, cell := range table[i] {
+			if field, ok := columns[normalizeHeader(cell)]; ok {
+				if _, seen := fields[field]; !seen {
+					fields[field] = col
+				}
+			}
+		}
+		if _, ok := fields["exercise"]; ok {
+			return i, fields, nil
+		}
+	}
+	return 0, nil, errors.New("no header row with an exercise column was found")
+}
+
+func entriesFromTable(table [][]string) ([]models.ImportEntry, error) {
+	header, fields, err := findHeader(table)
+	if err != nil {
+		return nil, err
+	}
+	_, perSet := fields["set"]
+
+	// Days are numbers, or labels such as "Monday" or "Push" numbered in
+	// order of appearance.
+	dayLabels := make(map[string]int)
+	entries := make([]models.ImportEntry, 0)
+
+	for i := header + 1; i < len(table); i++ {
+		line := i + 1
+		row := table[i]
+		cell := func(field string) string {
+			col, ok := fields[field]
+			if !ok || col >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[col])
+		}
+
+		name := cell("exercise")
+		if name == "" {
+			continue
+		}
+
+		r := rowReader{line: line}
+		day := 1
+		if label := cell("day"); label != "" {
+			if n, err := strconv.Atoi(digits(label)); err == nil && n > 0 {
+				day = n
+			} else {
+				if _, ok := dayLabels[label]; !ok {
+					dayLabels[label] = len(dayLabels) + 1
+				}
+				day = dayLabels[label]
+			}
+		}
+
+		set := r.prescription(cell)
+		var exerciseID *int
+		if id := r.integer("exercise_id", cell("exercise_id")); id != nil && *id > 0 {
+			exerciseID = id
+		}
+
+		// A row of a per-set sheet continues the previous entry when it's
+		// the next set of the same exercise on the same day.
+		if perSet && len(entries) > 0 {
+			prev := &entries[len(entries)-1]
+			setNumber := r.integer("set", cell("set"))
+			if prev.Day == day && prev.Name == name && setNumber != nil && *setNumber == len(prev.Prescriptions)+1 {
+				prev.Rows = append(prev.Rows, line)
+				prev.Prescriptions = append(prev.Prescriptions, set)
+				prev.Warnings = append(prev.Warnings, r.warnings...)
+				continue
+			}
+		}
+
+		sets := 1
+		if !perSet {
+			if n := r.integer("sets", cell("sets")); n != nil && *n > 0 {
+				sets = *n
+			}
+		}
+		prescriptions := make([]models.SetPrescription, sets)
+		for n := range prescriptions {
+			prescriptions[n] = set
+		}
+
+		entries = append(entries, models.ImportEntry{
+			Entry:         len(entries) + 1,
+			Rows:          []int{line},
+			Day:           day,
+			Name:          name,
+			Prescriptions: prescriptions,
+			ExerciseId:    exerciseID,
+			Warnings:      r.warnings,
+		})
+	}
+
+	if len(entries) == 0 {
+		return nil, errors.New("the sheet has no exercise rows")
+	}
+	return entries, nil
+}
+
+// rowReader parses the cells of a row, collecting a warning for each cell it
+// can't read instead of failing the import.
+type rowReader struct {
+	line     int
+	warnings []string
+}
+
+func (r *rowReader) warn(field, value string) {
+	r.warnings = append(r.warnings, fmt.Sprintf("row %d: invalid %s %q", r.line, field, value))
+}
+
+func (r *rowReader) prescription(cell func(string) string) models.SetPrescription {
+	set := models.SetPrescription{
+		Reps:            r.integer("reps", cell("reps")),
+		RPE:             r.float("rpe", cell("rpe")),
+		RIR:             r.integer("rir", cell("rir")),
+		OneRmPercent:    r.float("one_rm_percent", cell("one_rm_percent")),
+		RestSeconds:     r.seconds("rest", cell("rest")),
+		DurationSeconds: r.seconds("duration", cell("duration")),
+		DistanceMeters:  r.meters("distance", cell("distance")),
+	}
+	if tempo := cell("tempo"); tempo != "" {
+		set.Tempo = &tempo
+	}
+
+	// A weight written as a percentage is relative to the 1RM
+	if weight := cell("weight"); strings.HasSuffix(weight, "%") {
+		set.OneRmPercent = r.float("weight", strings.TrimSuffix(weight, "%"))
+	} else if weight != "" {
+		set.WeightKg = r.kilograms("weight", weight)
+	}
+	return set
+}
+
+var leadingNumber = regexp.MustCompile(`^\d+(?:[.,]\d+)?`)
+
+// number reads the number a cell starts with, so "8-10" reads as 8 and
+// "80 kg" as 80.
+func number(s string) (float64, string, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	match := leadingNumber.FindString(s)
+	if match == "" {
+		return 0, s, false
+	}
+	f, err := strconv.ParseFloat(strings.Replace(match, ",", ".", 1), 64)
+	if err != nil {
+		return 0, s, false
+	}
+	return f, strings.TrimSpace(s[len(match):]), true
+}
+
+func (r *rowReader) float(field, s string) *float64 {
+	if s == "" {
+		return nil
+	}
+	f, _, ok := number(s)
+	if !ok {
+		r.warn(field, s)
+		return nil
+	}
+	return &f
+}
+
+func (r *rowReader) integer(field, s string) *int {
+	f := r.float(field, s)
+	if f == nil {
+		return nil
+	}
+	i := int(*f)
+	return &i
+}
+
+// kilograms reads a weight, converting pounds.
+func (r *rowReader) kilograms(field, s string) *float64 {
+	f, unit, ok := number(s)
+	if !ok {
+		r.warn(field, s)
+		return nil
+	}
+	if strings.HasPrefix(unit, "lb") {
+		f = math.Round(f*0.45359237*10) / 10
+	}
+	return &f
+}
+
+// seconds reads "90", "90s", "2min", "2m" or "1:30".
+func (r *rowReader) seconds(field, s string) *int {
+	if s == "" {
+		return nil
+	}
+	if minutes, secs, ok := strings.Cut(s, ":"); ok {
+		m, err1 := strconv.Atoi(strings.TrimSpace(minutes))
+		sec, err2 := strconv.Atoi(strings.TrimSpace(secs))
+		if err1 != nil || err2 != nil {
+			r.warn(field, s)
+			return nil
+		}
+		total := m*60 + sec
+		return &total
+	}
+
+	f, unit, ok := number(s)
+	if !ok {
+		r.warn(field, s)
+		return nil
+	}
+	switch {
+	case strings.HasPrefix(unit, "m"):
+		f *= 60
+	case strings.HasPrefix(unit, "h"):
+		f *= 3600
+	}
+	total := int(f)
+	return &total
+}
+
+// meters reads "2000", "2000m" or "2km".
+func (r *rowReader) meters(field, s string) *float64 {
+	if s == "" {
+		return nil
+	}
+	f, unit, ok := number(s)
+	if !ok {
+		r.warn(field, s)
+		return nil
+	}
+	if strings.HasPrefix(unit, "km") {
+		f *= 1000
+	}
+	return &f
+}
+
+func digits(s string) string {
+	return strings.TrimFunc(s, func(r rune) bool { return r < '0' || r > '9' })
+}
@@ -0,0 +1,218 @@
+package live
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Types of the messages of a session. Devices send set edits and the rest
+// timer, the service sends the sets logged through the API and the end of
+// the workout.
+const (
+	SetEdited       = "set.edited"
+	RestStarted     = "rest.started"
+	RestStopped     = "rest.stopped"
+	SetLogged       = "set.logged"
+	WorkoutFinished = "workout.finished"
+)
+
+const (
+	// backlogSize is how many messages of a session are kept for resuming.
+	backlogSize = 256
+	// subscriberBuffer is how many messages a device may fall behind before
+	// it is dropped, it resumes once it reconnects.
+	subscriberBuffer = 64
+	// idleTimeout is how long a session without devices is kept.
+	idleTimeout = 6 * time.Hour
+)
+
+// IsDeviceType reports whether devices may send messages of the type.
+func IsDeviceType(messageType string) bool {
+	switch messageType {
+	case SetEdited, RestStarted, RestStopped:
+		return true
+	}
+	return false
+}
+
+// Message is one update of a session. Seq is 0 for the messages sent to a
+// single device, which aren't kept.
+type Message struct {
+	Seq    int64           `json:"seq"`
+	Type   string          `json:"type"`
+	Device string          `json:"device,omitempty"`
+	Data   json.RawMessage `json:"data,omitempty"`
+	At     time.Time       `json:"at"`
+}
+
+// Session keeps the devices following an in-progress workout in sync.
+// Every message gets the next sequence number and the latest ones are kept,
+// so a device that reconnects resumes after the last message it saw.
+type Session struct {
+	mu       sync.Mutex
+	seq      int64
+	backlog  []Message
+	subs     map[chan Message]struct{}
+	closed   bool
+	activeAt time.Time
+}
+
+func newSession() *Session {
+	return &Session{subs: make(map[chan Message]struct{}), activeAt: time.Now()}
+}
+
+// Publish sequences a message and sends it to every device.
+func (s *Session) Publish(messageType, device string, data json.RawMessage) Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+	msg := Message{Seq: s.seq, Type: messageType, Device: device, Data: data, At: time.Now().UTC()}
+	if len(s.backlog) == backlogSize {
+		s.backlog = append(s.backlog[:0], s.backlog[1:]...)
+	}
+	s.backlog = append(s.backlog, msg)
+	s.activeAt = msg.At
+
+	for ch := range s.subs {
+		select {
+		case ch <- msg:
+		default:
+			// Too far behind, the device has to resume
+			delete(s.subs, ch)
+			close(ch)
+		}
+	}
+	return msg
+}
+
+// Subscribe returns the kept messages after since and a channel of the
+// following ones. ok is false when messages after since were dropped
+// already, or when since is ahead of the session because the replica
+// restarted and numbering began again; the device then has to reload the
+// workout. The channel starts after the latest message either way, it is
+// closed when the device falls behind, the session ends or cancel is
+// called.
+func (s *Session) Subscribe(since int64) (replay []Message, ok bool, ch <-chan Message, cancel func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := make(chan Message, subscriberBuffer)
+	if s.closed {
+		close(c)
+	} else {
+		s.subs[c] = struct{}{}
+	}
+	s.activeAt = time.Now()
+
+	ok = since >= s.seq-int64(len(s.backlog)) && since <= s.seq
+	if ok {
+		for _, msg := range s.backlog {
+			if msg.Seq > since {
+				replay = append(replay, msg)
+			}
+		}
+	}
+
+	cancel = func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if _, ok := s.subs[c]; ok {
+			delete(s.subs, c)
+			close(c)
+		}
+		s.activeAt = time.Now()
+	}
+	return replay, ok, c, cancel
+}
+
+// Seq returns the number of the latest message.
+func (s *Session) Seq() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.seq
+}
+
+func (s *Session) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for ch := range s.subs {
+		delete(s.subs, ch)
+		close(ch)
+	}
+}
+
+func (s *Session) idle(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.subs) == 0 && now.Sub(s.activeAt) > idleTimeout
+}
+
+// Hub holds the sessions of the workouts followed on this replica.
+type Hub struct {
+	mu       sync.Mutex
+	sessions map[uuid.UUID]*Session
+}
+
+func NewHub() *Hub {
+	return &Hub{sessions: make(map[uuid.UUID]*Session)}
+}
+
+// Join returns the session of a workout, starting it if needed. Sessions
+// left idle are dropped on the way.
+func (h *Hub) Join(workoutID uuid.UUID) *Session {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	for id, s := range h.sessions {
+		if id != workoutID && s.idle(now) {
+			delete(h.sessions, id)
+		}
+	}
+
+	s, ok := h.sessions[workoutID]
+	if !ok {
+		s = newSession()
+		h.sessions[workoutID] = s
+	}
+	return s
+}
+
+// Publish sends a message to the session of a workout, if devices follow
+// it.
+func (h *Hub) Publish(workoutID uuid.UUID, messageType string, data any) error {
+	h.mu.Lock()
+	s, ok := h.sessions[workoutID]
+	h.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	body, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	s.Publish(messageType, "", body)
+	return nil
+}
+
+// End sends the last message of a workout and disconnects its devices.
+func (h *Hub) End(workoutID uuid.UUID, data any) error {
+	if err := h.Publish(workoutID, WorkoutFinished, data); err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	s, ok := h.sessions[workoutID]
+	delete(h.sessions, workoutID)
+	h.mu.Unlock()
+	if ok {
+		s.close()
+	}
+	return nil
+}
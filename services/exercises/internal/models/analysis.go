@@ -0,0 +1,52 @@
+package models
+
+import (
+	"github.com/google/uuid"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+)
+
+// ProgramAnalysis is the balance of a program over one pass through all of
+// its days, typically a week.
+type ProgramAnalysis struct {
+	ProgramID uuid.UUID         `json:"programId" example:"123e4567-e89b-12d3-a456-426614174000"`
+	TotalSets int               `json:"totalSets" example:"54"`
+	Muscles   []MuscleSets      `json:"muscles"`
+	PushPull  ForceBalance      `json:"pushPull"`
+	Mechanics MechanicBalance   `json:"mechanics"`
+	Equipment []db.EquipmentT   `json:"equipment" example:"Barbell,Dumbbells"`
+	Warnings  []AnalysisWarning `json:"warnings"`
+}
+
+// MuscleSets counts the sets training a muscle. Sets of exercises where the
+// muscle is secondary count for half in WeightedSets.
+type MuscleSets struct {
+	Muscle        string  `json:"muscle" example:"hamstrings"`
+	PrimarySets   int     `json:"primarySets" example:"6"`
+	SecondarySets int     `json:"secondarySets" example:"4"`
+	WeightedSets  float64 `json:"weightedSets" example:"8"`
+}
+
+// ForceBalance counts the sets of pushing and pulling exercises. Ratio is
+// push over pull, left out when nothing pulls.
+type ForceBalance struct {
+	PushSets   int      `json:"pushSets" example:"18"`
+	PullSets   int      `json:"pullSets" example:"12"`
+	StaticSets int      `json:"staticSets" example:"3"`
+	Ratio      *float64 `json:"ratio,omitempty" example:"1.5"`
+}
+
+// MechanicBalance counts the sets of compound and isolation exercises.
+// Ratio is compound over isolation, left out without isolation sets.
+type MechanicBalance struct {
+	CompoundSets  int      `json:"compoundSets" example:"24"`
+	IsolationSets int      `json:"isolationSets" example:"12"`
+	Ratio         *float64 `json:"ratio,omitempty" example:"2"`
+}
+
+// AnalysisWarning flags an imbalance, e.g. an untrained muscle group.
+type AnalysisWarning struct {
+	Code    string `json:"code" example:"untrained"`
+	Group   string `json:"group,omitempty" example:"hamstrings"`
+	Message string `json:"message" example:"no exercise trains the hamstrings"`
+}
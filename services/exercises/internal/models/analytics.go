@@ -0,0 +1,87 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+)
+
+type MuscleVolume struct {
+	WeekStart time.Time `json:"weekStart" example:"2024-01-01T00:00:00Z"`
+	Muscle    string    `json:"muscle" example:"chest"`
+	Sets      int       `json:"sets" example:"12"`
+	Reps      int       `json:"reps" example:"96"`
+	TonnageKg float64   `json:"tonnageKg" example:"5400"`
+}
+
+type E1RMPoint struct {
+	WorkoutID   uuid.UUID `json:"workoutId" example:"123e4567-e89b-12d3-a456-426614174000"`
+	PerformedAt time.Time `json:"performedAt"`
+	EstimateKg  float64   `json:"estimateKg" example:"112.5"`
+}
+
+type E1RMTrend struct {
+	ExerciseId int         `json:"exerciseId" example:"12"`
+	Formula    string      `json:"formula" example:"epley"`
+	Points     []E1RMPoint `json:"points"`
+}
+
+type PersonalRecord struct {
+	ExerciseId    int       `json:"exerciseId" example:"12"`
+	Type          string    `json:"type" example:"e1rm"`
+	Value         float64   `json:"value" example:"112.5"`
+	PreviousValue *float64  `json:"previousValue,omitempty" example:"110"`
+	WorkoutID     uuid.UUID `json:"workoutId" example:"123e4567-e89b-12d3-a456-426614174000"`
+	WorkoutSetId  int64     `json:"workoutSetId" example:"42"`
+	AchievedAt    time.Time `json:"achievedAt"`
+}
+
+func MuscleVolumeFromRows(rows []db.GetWeeklyMuscleVolumeRow) *[]MuscleVolume {
+	volume := make([]MuscleVolume, 0, len(rows))
+	for _, row := range rows {
+		volume = append(volume, MuscleVolume{
+			WeekStart: row.WeekStart.Time,
+			Muscle:    row.Muscle.String,
+			Sets:      int(row.Sets),
+			Reps:      int(row.Reps),
+			TonnageKg: row.TonnageKg,
+		})
+	}
+	return &volume
+}
+
+// E1RMTrendFromRows picks the estimate of the requested formula out of the
+// cached rows, which hold all of them.
+func E1RMTrendFromRows(exerciseID int, formula string, rows []db.GetE1rmTrendRow) *E1RMTrend {
+	points := make([]E1RMPoint, 0, len(rows))
+	for _, row := range rows {
+		estimate := row.EpleyKg
+		if formula == "brzycki" {
+			estimate = row.BrzyckiKg
+		}
+		points = append(points, E1RMPoint{
+			WorkoutID:   row.WorkoutID.Bytes,
+			PerformedAt: row.PerformedAt.Time,
+			EstimateKg:  estimate,
+		})
+	}
+	return &E1RMTrend{ExerciseId: exerciseID, Formula: formula, Points: points}
+}
+
+func PersonalRecordsFromRows(rows []db.PersonalRecord) []PersonalRecord {
+	records := make([]PersonalRecord, 0, len(rows))
+	for _, row := range rows {
+		records = append(records, PersonalRecord{
+			ExerciseId:    int(row.ExerciseID),
+			Type:          string(row.RecordType),
+			Value:         row.Value,
+			PreviousValue: fromFloat8(row.PreviousValue),
+			WorkoutID:     row.WorkoutID.Bytes,
+			WorkoutSetId:  row.WorkoutSetID,
+			AchievedAt:    row.AchievedAt.Time,
+		})
+	}
+	return records
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+)
+
+// ScheduleRequest lays the days of a program out on the calendar: the
+// sessions fall on the given weekdays, starting on startDate and for the
+// given number of weeks, and go through the program days in order.
+type ScheduleRequest struct {
+	ProgramID       uuid.UUID `json:"programId" example:"123e4567-e89b-12d3-a456-426614174000"`
+	StartDate       string    `json:"startDate" example:"2026-11-02"`
+	Weekdays        []string  `json:"weekdays" example:"monday,wednesday,friday"`
+	Weeks           int       `json:"weeks" example:"8"`
+	StartTime       *string   `json:"startTime,omitempty" example:"18:30"`
+	DurationMinutes int       `json:"durationMinutes" example:"60"`
+	Timezone        string    `json:"timezone" example:"Europe/Berlin"`
+}
+
+type ProgramSchedule struct {
+	ID              uuid.UUID `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	ProgramID       uuid.UUID `json:"programId" example:"123e4567-e89b-12d3-a456-426614174000"`
+	StartDate       string    `json:"startDate" example:"2026-11-02"`
+	Weekdays        []string  `json:"weekdays" example:"monday,wednesday,friday"`
+	Weeks           int       `json:"weeks" example:"8"`
+	StartTime       *string   `json:"startTime,omitempty" example:"18:30"`
+	DurationMinutes int       `json:"durationMinutes" example:"60"`
+	Timezone        string    `json:"timezone" example:"Europe/Berlin"`
+	CreatedAt       time.Time `json:"createdAt"`
+}
+
+// AgendaEntry is one scheduled session. Start and End are only set for
+// sessions with a start time, the others take the whole date.
+type AgendaEntry struct {
+	ScheduleID uuid.UUID  `json:"scheduleId" example:"123e4567-e89b-12d3-a456-426614174000"`
+	ProgramID  uuid.UUID  `json:"programId" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Session    int        `json:"session" example:"4"`
+	Day        int        `json:"day" example:"1"`
+	Date       string     `json:"date" example:"2026-11-09"`
+	Start      *time.Time `json:"start,omitempty"`
+	End        *time.Time `json:"end,omitempty"`
+	Exercises  []string   `json:"exercises"`
+}
+
+// CalendarFeed is the secret address of the iCalendar feed of a user.
+type CalendarFeed struct {
+	Token     string    `json:"token" example:"q5bY1z0k3m8Jc2VhX9t7Ww"`
+	Path      string    `json:"path" example:"/api/calendar/q5bY1z0k3m8Jc2VhX9t7Ww.ics"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func ProgramScheduleFromRow(row db.ProgramSchedule) ProgramSchedule {
+	schedule := ProgramSchedule{
+		ID:              row.ID.Bytes,
+		ProgramID:       row.ProgramID.Bytes,
+		StartDate:       row.StartDate.Time.Format("2006-01-02"),
+		Weekdays:        make([]string, 0, len(row.Weekdays)),
+		Weeks:           int(row.Weeks),
+		DurationMinutes: int(row.DurationMinutes),
+		Timezone:        row.Timezone,
+		CreatedAt:       row.CreatedAt.Time,
+	}
+	for _, day := range row.Weekdays {
+		schedule.Weekdays = append(schedule.Weekdays, strings.ToLower(time.Weekday(day).String()))
+	}
+	if row.StartTime.Valid {
+		minutes := row.StartTime.Microseconds / int64(time.Minute/time.Microsecond)
+		startTime := fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+		schedule.StartTime = &startTime
+	}
+	return schedule
+}
+
+func CalendarFeedFromRow(row db.CalendarFeed) CalendarFeed {
+	return CalendarFeed{
+		Token:     row.Token,
+		Path:      "/api/calendar/" + row.Token + ".ics",
+		CreatedAt: row.CreatedAt.Time,
+	}
+}
@@ -0,0 +1,116 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+)
+
+// Invitation states
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationRevoked  = "revoked"
+	InvitationExpired  = "expired"
+)
+
+type UserRoles struct {
+	UserID int64      `json:"userId" example:"42"`
+	Roles  []db.RoleT `json:"roles" example:"coach"`
+}
+
+// CoachInvitation is a single use code a coach hands to a client. Accepting
+// it links the two accounts.
+type CoachInvitation struct {
+	Code       string    `json:"code" example:"q5bY1z0k3m8Jc2VhX9t7Ww"`
+	Status     string    `json:"status" example:"pending"`
+	CreatedAt  time.Time `json:"createdAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	AcceptedBy *int64    `json:"acceptedBy,omitempty" example:"7"`
+}
+
+// CoachLink is the link between a coach and a client, with what the client
+// consents to.
+type CoachLink struct {
+	CoachID          int64     `json:"coachId" example:"42"`
+	ClientID         int64     `json:"clientId" example:"7"`
+	ShareWorkouts    bool      `json:"shareWorkouts" example:"true"`
+	AllowAssignments bool      `json:"allowAssignments" example:"true"`
+	Since            time.Time `json:"since"`
+}
+
+// ConsentRequest changes what a client consents to, fields left out are
+// kept.
+type ConsentRequest struct {
+	ShareWorkouts    *bool `json:"shareWorkouts,omitempty" example:"false"`
+	AllowAssignments *bool `json:"allowAssignments,omitempty" example:"true"`
+}
+
+type AssignProgramRequest struct {
+	ProgramID uuid.UUID `json:"programId" example:"123e4567-e89b-12d3-a456-426614174000"`
+}
+
+type ProgramAssignment struct {
+	ID         uuid.UUID `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	CoachID    int64     `json:"coachId" example:"42"`
+	ClientID   int64     `json:"clientId" example:"7"`
+	ProgramID  uuid.UUID `json:"programId" example:"123e4567-e89b-12d3-a456-426614174000"`
+	AssignedAt time.Time `json:"assignedAt"`
+}
+
+func CoachInvitationFromRow(row db.CoachInvitation, now time.Time) CoachInvitation {
+	invitation := CoachInvitation{
+		Code:      row.Code,
+		Status:    InvitationPending,
+		CreatedAt: row.CreatedAt.Time,
+		ExpiresAt: row.ExpiresAt.Time,
+	}
+	switch {
+	case row.AcceptedBy.Valid:
+		invitation.Status = InvitationAccepted
+		invitation.AcceptedBy = &row.AcceptedBy.Int64
+	case row.RevokedAt.Valid:
+		invitation.Status = InvitationRevoked
+	case !now.Before(row.ExpiresAt.Time):
+		invitation.Status = InvitationExpired
+	}
+	return invitation
+}
+
+func CoachLinkFromRow(row db.CoachClient) CoachLink {
+	return CoachLink{
+		CoachID:          row.CoachID,
+		ClientID:         row.ClientID,
+		ShareWorkouts:    row.ShareWorkouts,
+		AllowAssignments: row.AllowAssignments,
+		Since:            row.CreatedAt.Time,
+	}
+}
+
+func CoachLinksFromRows(rows []db.CoachClient) []CoachLink {
+	links := make([]CoachLink, 0, len(rows))
+	for _, row := range rows {
+		links = append(links, CoachLinkFromRow(row))
+	}
+	return links
+}
+
+func ProgramAssignmentFromRow(row db.ProgramAssignment) ProgramAssignment {
+	return ProgramAssignment{
+		ID:         row.ID.Bytes,
+		CoachID:    row.CoachID,
+		ClientID:   row.ClientID,
+		ProgramID:  row.ProgramID.Bytes,
+		AssignedAt: row.AssignedAt.Time,
+	}
+}
+
+func ProgramAssignmentsFromRows(rows []db.ProgramAssignment) []ProgramAssignment {
+	assignments := make([]ProgramAssignment, 0, len(rows))
+	for _, row := range rows {
+		assignments = append(assignments, ProgramAssignmentFromRow(row))
+	}
+	return assignments
+}
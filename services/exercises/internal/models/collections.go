@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+)
+
+// CollectionRequest creates or renames a collection. ExerciseIds is only
+// read on creation.
+type CollectionRequest struct {
+	Name        string  `json:"name" example:"home gym"`
+	ExerciseIds []int32 `json:"exerciseIds,omitempty" example:"12,31"`
+}
+
+// ExerciseCollection is a named set of exercises of a user.
+type ExerciseCollection struct {
+	ID            uuid.UUID `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Name          string    `json:"name" example:"home gym"`
+	ExerciseCount int       `json:"exerciseCount" example:"2"`
+	ExerciseIds   []int32   `json:"exerciseIds,omitempty" example:"12,31"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+func ExerciseCollectionFromRow(row db.ExerciseCollection, exerciseIds []int32) ExerciseCollection {
+	if exerciseIds == nil {
+		exerciseIds = []int32{}
+	}
+	return ExerciseCollection{
+		ID:            row.ID.Bytes,
+		Name:          row.Name,
+		ExerciseCount: len(exerciseIds),
+		ExerciseIds:   exerciseIds,
+		CreatedAt:     row.CreatedAt.Time,
+	}
+}
+
+func ExerciseCollectionsFromRows(rows []db.GetExerciseCollectionsRow) []ExerciseCollection {
+	collections := make([]ExerciseCollection, 0, len(rows))
+	for _, row := range rows {
+		collections = append(collections, ExerciseCollection{
+			ID:            row.ID.Bytes,
+			Name:          row.Name,
+			ExerciseCount: int(row.ExerciseCount),
+			CreatedAt:     row.CreatedAt.Time,
+		})
+	}
+	return collections
+}
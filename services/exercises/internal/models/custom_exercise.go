@@ -0,0 +1,301 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+)
+
+const (
+	maxExerciseNames = 10
+	maxNameLength    = 255
+	// The visuals of an exercise share one visuals row, comma separated.
+	maxVisualsLength = 255
+)
+
+// CustomExerciseRequest creates or replaces a custom exercise. Muscles are
+// the primary muscles, both lists have to name known muscles.
+type CustomExerciseRequest struct {
+	Names            []string      `json:"names" example:"Banded Face Pull"`
+	Muscles          []string      `json:"muscles" example:"shoulders"`
+	SecondaryMuscles []string      `json:"secondaryMuscles,omitempty" example:"middle back"`
+	Equipment        db.EquipmentT `json:"equipment" example:"Band"`
+	Category         *db.CategoryT `json:"category,omitempty" example:"strength"`
+	Level            *db.LevelT    `json:"level,omitempty" example:"beginner"`
+	Mechanic         *db.MechanicT `json:"mechanic,omitempty" example:"isolation"`
+	Force            *db.ForceT    `json:"force,omitempty" example:"pull"`
+	Instructions     []string      `json:"instructions,omitempty" example:"Anchor the band at face height,Pull towards your forehead"`
+	Visuals          []string      `json:"visuals,omitempty" example:"https://example.com/face-pull.jpg"`
+}
+
+// Normalize trims the request, drops empty and repeated entries and checks
+// its values. A muscle listed as primary is dropped from the secondary
+// muscles.
+func (r *CustomExerciseRequest) Normalize() error {
+	r.Names = uniqueTrimmed(r.Names)
+	if len(r.Names) == 0 {
+		return errors.New("at least one name is required")
+	}
+	if len(r.Names) > maxExerciseNames {
+		return fmt.Errorf("at most %d names are allowed", maxExerciseNames)
+	}
+	for _, name := range r.Names {
+		if len(name) > maxNameLength {
+			return fmt.Errorf("name %q is longer than %d characters", name, maxNameLength)
+		}
+	}
+
+	r.Muscles = uniqueTrimmed(r.Muscles)
+	if len(r.Muscles) == 0 {
+		return errors.New("at least one muscle is required")
+	}
+	primary := make(map[string]bool, len(r.Muscles))
+	for _, m := range r.Muscles {
+		primary[strings.ToLower(m)] = true
+	}
+	secondary := make([]string, 0, len(r.SecondaryMuscles))
+	for _, m := range uniqueTrimmed(r.SecondaryMuscles) {
+		if !primary[strings.ToLower(m)] {
+			secondary = append(secondary, m)
+		}
+	}
+	r.SecondaryMuscles = secondary
+
+	if r.Equipment == "" {
+		r.Equipment = db.EquipmentTOther
+	}
+	if !IsEquipment(r.Equipment) {
+		return fmt.Errorf("unknown equipment %q", r.Equipment)
+	}
+	if r.Category != nil {
+		switch *r.Category {
+		case db.CategoryTStrength, db.CategoryTStretching, db.CategoryTPlyometrics, db.CategoryTPowerlifting,
+			db.CategoryTOlympicweightlifting, db.CategoryTStrongman, db.CategoryTCardio:
+		default:
+			return fmt.Errorf("unknown category %q", *r.Category)
+		}
+	}
+	if r.Level != nil {
+		switch *r.Level {
+		case db.LevelTBeginner, db.LevelTIntermediate, db.LevelTExpert:
+		default:
+			return fmt.Errorf("unknown level %q, expected beginner, intermediate or expert", *r.Level)
+		}
+	}
+	if r.Mechanic != nil {
+		switch *r.Mechanic {
+		case db.MechanicTCompound, db.MechanicTIsolation:
+		default:
+			return fmt.Errorf("unknown mechanic %q, expected compound or isolation", *r.Mechanic)
+		}
+	}
+	if r.Force != nil {
+		switch *r.Force {
+		case db.ForceTPush, db.ForceTPull, db.ForceTStatic:
+		default:
+			return fmt.Errorf("unknown force %q, expected push, pull or static", *r.Force)
+		}
+	}
+
+	r.Instructions = trimmed(r.Instructions)
+	r.Visuals = uniqueTrimmed(r.Visuals)
+	for _, v := range r.Visuals {
+		if strings.Contains(v, ",") {
+			return fmt.Errorf("visual %q can't contain a comma", v)
+		}
+	}
+	if len(strings.Join(r.Visuals, ",")) > maxVisualsLength {
+		return fmt.Errorf("visuals are longer than %d characters together", maxVisualsLength)
+	}
+	return nil
+}
+
+// CustomExercise is an exercise created by a user. It can be used in
+// programs like a catalog exercise by its owner and their clients.
+type CustomExercise struct {
+	Id               int32                 `json:"id" example:"3012"`
+	Names            []string              `json:"names" example:"Banded Face Pull"`
+	Muscles          []string              `json:"muscles" example:"shoulders"`
+	SecondaryMuscles []string              `json:"secondaryMuscles" example:"middle back"`
+	Equipment        db.EquipmentT         `json:"equipment" example:"Band"`
+	Category         *db.CategoryT         `json:"category,omitempty" example:"strength"`
+	Level            *db.LevelT            `json:"level,omitempty" example:"beginner"`
+	Mechanic         *db.MechanicT         `json:"mechanic,omitempty" example:"isolation"`
+	Force            *db.ForceT            `json:"force,omitempty" example:"pull"`
+	Instructions     []string              `json:"instructions" example:"Anchor the band at face height,Pull towards your forehead"`
+	Visuals          []string              `json:"visuals" example:"https://example.com/face-pull.jpg"`
+	SubmissionStatus *db.SubmissionStatusT `json:"submissionStatus,omitempty" example:"pending"`
+}
+
+func CustomExerciseFromRow(row db.GetCustomExercisesRow) CustomExercise {
+	ex := CustomExercise{
+		Id:               row.ID,
+		Names:            row.Names,
+		Muscles:          row.PrimaryMuscles,
+		SecondaryMuscles: row.SecondaryMuscles,
+		Equipment:        row.Equipment.EquipmentT,
+		Instructions:     row.Instructions,
+		Visuals:          []string{},
+	}
+	if row.Category.Valid {
+		ex.Category = &row.Category.CategoryT
+	}
+	if row.Level.Valid {
+		ex.Level = &row.Level.LevelT
+	}
+	if row.Mechanic.Valid {
+		ex.Mechanic = &row.Mechanic.MechanicT
+	}
+	if row.Force.Valid {
+		ex.Force = &row.Force.ForceT
+	}
+	if row.Visuals.Valid && row.Visuals.String != "" {
+		ex.Visuals = strings.Split(row.Visuals.String, ",")
+	}
+	if row.SubmissionStatus.Valid {
+		ex.SubmissionStatus = &row.SubmissionStatus.SubmissionStatusT
+	}
+	return ex
+}
+
+func CustomExercisesFromRows(rows []db.GetCustomExercisesRow) []CustomExercise {
+	exercises := make([]CustomExercise, 0, len(rows))
+	for _, row := range rows {
+		exercises = append(exercises, CustomExerciseFromRow(row))
+	}
+	return exercises
+}
+
+// Request is the exercise as it would be created, which is what a
+// submission keeps of it.
+func (ex CustomExercise) Request() CustomExerciseRequest {
+	return CustomExerciseRequest{
+		Names:            ex.Names,
+		Muscles:          ex.Muscles,
+		SecondaryMuscles: ex.SecondaryMuscles,
+		Equipment:        ex.Equipment,
+		Category:         ex.Category,
+		Level:            ex.Level,
+		Mechanic:         ex.Mechanic,
+		Force:            ex.Force,
+		Instructions:     ex.Instructions,
+		Visuals:          ex.Visuals,
+	}
+}
+
+// SubmitExerciseRequest submits a custom exercise, or only starts a draft
+// of the submission when Draft is set.
+type SubmitExerciseRequest struct {
+	Note  string `json:"note,omitempty" example:"Common in shoulder rehab"`
+	Draft bool   `json:"draft,omitempty" example:"false"`
+}
+
+// ReviewRequest carries the comment of a reviewer, which is required to
+// reject a submission.
+type ReviewRequest struct {
+	Comment string `json:"comment,omitempty" example:"Already in the catalog as Face Pull"`
+}
+
+type CommentRequest struct {
+	Body string `json:"body" example:"Added a second name"`
+}
+
+// ExerciseSubmission asks for a custom exercise to be promoted to the
+// public catalog. Exercise is the custom exercise as it was submitted.
+type ExerciseSubmission struct {
+	ID                  uuid.UUID              `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	ExerciseID          int32                  `json:"exerciseId" example:"3012"`
+	UserID              int64                  `json:"userId" example:"42"`
+	Status              db.SubmissionStatusT   `json:"status" example:"pending"`
+	Note                string                 `json:"note,omitempty" example:"Common in shoulder rehab"`
+	SubmittedAt         time.Time              `json:"submittedAt"`
+	Exercise            *CustomExerciseRequest `json:"exercise,omitempty"`
+	ReviewedBy          *int64                 `json:"reviewedBy,omitempty" example:"1"`
+	ReviewedAt          *time.Time             `json:"reviewedAt,omitempty"`
+	PublishedExerciseID *int32                 `json:"publishedExerciseId,omitempty" example:"874"`
+	Comments            []SubmissionComment    `json:"comments,omitempty"`
+}
+
+type SubmissionComment struct {
+	ID        uuid.UUID `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	AuthorID  int64     `json:"authorId" example:"1"`
+	Body      string    `json:"body" example:"Already in the catalog as Face Pull"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func ExerciseSubmissionFromRow(row db.ExerciseSubmission) ExerciseSubmission {
+	submission := ExerciseSubmission{
+		ID:          row.ID.Bytes,
+		ExerciseID:  row.ExerciseID,
+		UserID:      row.UserID,
+		Status:      row.Status,
+		Note:        row.Note,
+		SubmittedAt: row.SubmittedAt.Time,
+	}
+	if len(row.Snapshot) > 0 {
+		var exercise CustomExerciseRequest
+		if err := json.Unmarshal(row.Snapshot, &exercise); err == nil {
+			submission.Exercise = &exercise
+		}
+	}
+	if row.ReviewedBy.Valid {
+		submission.ReviewedBy = &row.ReviewedBy.Int64
+	}
+	if row.ReviewedAt.Valid {
+		submission.ReviewedAt = &row.ReviewedAt.Time
+	}
+	if row.PublishedExerciseID.Valid {
+		submission.PublishedExerciseID = &row.PublishedExerciseID.Int32
+	}
+	return submission
+}
+
+func SubmissionCommentsFromRows(rows []db.ExerciseSubmissionComment) []SubmissionComment {
+	comments := make([]SubmissionComment, 0, len(rows))
+	for _, row := range rows {
+		comments = append(comments, SubmissionComment{
+			ID:        row.ID.Bytes,
+			AuthorID:  row.AuthorID,
+			Body:      row.Body,
+			CreatedAt: row.CreatedAt.Time,
+		})
+	}
+	return comments
+}
+
+func ExerciseSubmissionsFromRows(rows []db.ExerciseSubmission) []ExerciseSubmission {
+	submissions := make([]ExerciseSubmission, 0, len(rows))
+	for _, row := range rows {
+		submissions = append(submissions, ExerciseSubmissionFromRow(row))
+	}
+	return submissions
+}
+
+func trimmed(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// uniqueTrimmed is trimmed without repeated values, ignoring case.
+func uniqueTrimmed(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	out := make([]string, 0, len(values))
+	for _, v := range trimmed(values) {
+		if key := strings.ToLower(v); !seen[key] {
+			seen[key] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
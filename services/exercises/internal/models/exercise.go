@@ -12,6 +12,22 @@ type Exercise struct {
 	Muscles   []string      `json:"muscles" example:"Chest, Triceps, Shoulders"`
 	Equipment db.EquipmentT `json:"equipment" example:"Bodyweight"`
 	Visuals   []string      `json:"visuals" example:"pushup.jpg,pushup2.jpg"`
+	// OwnerID is only set on custom exercises.
+	OwnerID *int64 `json:"ownerId,omitempty" example:"42"`
+	// Instructions are only loaded along with complete programs.
+	Instructions []string `json:"instructions,omitempty" example:"Lie on the floor,Push yourself up"`
+}
+
+// IsEquipment reports whether e is one of the values of equipment_t.
+func IsEquipment(e db.EquipmentT) bool {
+	switch e {
+	case db.EquipmentTDumbbells, db.EquipmentTBarbell, db.EquipmentTMachine, db.EquipmentTBodyweight,
+		db.EquipmentTMedicineBall, db.EquipmentTKettlebells, db.EquipmentTStreches, db.EquipmentTCables,
+		db.EquipmentTBand, db.EquipmentTPlate, db.EquipmentTTRX, db.EquipmentTBosuBall,
+		db.EquipmentTFoamroll, db.EquipmentTExerciseBall, db.EquipmentTOther:
+		return true
+	}
+	return false
 }
 
 func ExerciseFromRows(rows []db.GetExercisesRow) *[]Exercise {
@@ -34,6 +50,10 @@ func ExerciseFromRows(rows []db.GetExercisesRow) *[]Exercise {
 			Muscles:   muscles,
 			Visuals:   visuals,
 		}
+		if v.OwnerID.Valid {
+			owner := v.OwnerID.Int64
+			e.OwnerID = &owner
+		}
 
 		exercises = append(exercises, e)
 	}
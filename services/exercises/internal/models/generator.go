@@ -0,0 +1,25 @@
+package models
+
+import (
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+)
+
+// GenerateProgramRequest describes the program to generate. Bodyweight
+// exercises are always available on top of the listed equipment. The same
+// seed and catalog always produce the same program.
+type GenerateProgramRequest struct {
+	Goal           string          `json:"goal" example:"hypertrophy"`
+	DaysPerWeek    int             `json:"daysPerWeek" example:"3"`
+	SessionMinutes int             `json:"sessionMinutes" example:"60"`
+	Equipment      []db.EquipmentT `json:"equipment" example:"Barbell,Dumbbells"`
+	Level          string          `json:"level" example:"beginner"`
+	Seed           *int64          `json:"seed,omitempty" example:"42"`
+}
+
+// GeneratedProgram is a stored generated program along with the seed that
+// produced it, which can be sent again to reproduce it.
+type GeneratedProgram struct {
+	Program
+	Goal string `json:"goal" example:"hypertrophy"`
+	Seed int64  `json:"seed" example:"42"`
+}
@@ -0,0 +1,144 @@
+package models
+
+import (
+	"encoding/json"
+
+	"github.com/google/uuid"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+)
+
+// Statuses of an import entry. Ambiguous, unknown and invalid entries have
+// to be resolved, or skipped, before the program is created.
+const (
+	EntryMatched   = "matched"
+	EntryAmbiguous = "ambiguous"
+	EntryUnknown   = "unknown"
+	EntryInvalid   = "invalid"
+	EntrySkipped   = "skipped"
+)
+
+// ImportEntry is a program item read from one or more rows of a sheet, with
+// how its exercise name matched the catalog.
+type ImportEntry struct {
+	Entry         int               `json:"entry" example:"1"`
+	Rows          []int             `json:"rows" example:"2,3,4"`
+	Day           int               `json:"day" example:"1"`
+	Name          string            `json:"name" example:"Barbell bench press"`
+	Prescriptions []SetPrescription `json:"prescriptions"`
+	Status        string            `json:"status" example:"matched"`
+	ExerciseId    *int              `json:"exerciseId,omitempty" example:"12"`
+	MatchedName   string            `json:"matchedName,omitempty" example:"Barbell Bench Press - Medium Grip"`
+	Score         float64           `json:"score,omitempty" example:"0.92"`
+	Candidates    []ImportCandidate `json:"candidates,omitempty"`
+	// Error tells why the prescriptions of an invalid entry don't fit its
+	// exercise.
+	Error string `json:"error,omitempty" example:"set 1: weightKg is not allowed for stretching exercises"`
+	// Warnings list the cells that couldn't be read and were left out.
+	Warnings []string `json:"warnings,omitempty" example:"row 3: invalid reps \"x\""`
+}
+
+type ImportCandidate struct {
+	ExerciseId int     `json:"exerciseId" example:"12"`
+	Name       string  `json:"name" example:"Barbell Bench Press - Medium Grip"`
+	Score      float64 `json:"score" example:"0.81"`
+}
+
+type ImportSummary struct {
+	Matched   int `json:"matched" example:"18"`
+	Ambiguous int `json:"ambiguous" example:"1"`
+	Unknown   int `json:"unknown" example:"2"`
+	Invalid   int `json:"invalid" example:"0"`
+	Skipped   int `json:"skipped" example:"0"`
+}
+
+// ImportReport is the state of an import. ProgramID is set once every entry
+// is matched or skipped and the program was created.
+type ImportReport struct {
+	ID        uuid.UUID        `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Filename  string           `json:"filename" example:"block-1.xlsx"`
+	Status    db.ImportStatusT `json:"status" example:"pending"`
+	ProgramID *uuid.UUID       `json:"programId,omitempty" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Summary   ImportSummary    `json:"summary"`
+	Entries   []ImportEntry    `json:"entries"`
+}
+
+// ResolveImportRequest picks the exercise of, fixes the prescriptions of or
+// skips entries that didn't match on their own. Matched entries can be
+// corrected the same way.
+type ResolveImportRequest struct {
+	Resolutions []ImportResolution `json:"resolutions"`
+}
+
+type ImportResolution struct {
+	Entry         int               `json:"entry" example:"3"`
+	ExerciseId    *int              `json:"exerciseId,omitempty" example:"12"`
+	Prescriptions []SetPrescription `json:"prescriptions,omitempty"`
+	Skip          bool              `json:"skip,omitempty" example:"false"`
+}
+
+func Summarize(entries []ImportEntry) ImportSummary {
+	var summary ImportSummary
+	for _, e := range entries {
+		switch e.Status {
+		case EntryMatched:
+			summary.Matched++
+		case EntryAmbiguous:
+			summary.Ambiguous++
+		case EntryUnknown:
+			summary.Unknown++
+		case EntryInvalid:
+			summary.Invalid++
+		case EntrySkipped:
+			summary.Skipped++
+		}
+	}
+	return summary
+}
+
+// Unresolved reports whether some entries still need a decision.
+func (s ImportSummary) Unresolved() bool {
+	return s.Ambiguous > 0 || s.Unknown > 0 || s.Invalid > 0
+}
+
+// Record is the program item of a matched entry.
+func (e ImportEntry) Record(idx int) ProgramRecord {
+	record := ProgramRecord{Idx: idx, Day: e.Day, Prescriptions: e.Prescriptions}
+	if e.ExerciseId != nil {
+		record.ExerciseId = *e.ExerciseId
+	}
+	return record
+}
+
+// ImportRecords turns the matched entries into program items, numbered in
+// the order of the sheet.
+func ImportRecords(entries []ImportEntry) []ProgramRecord {
+	records := make([]ProgramRecord, 0, len(entries))
+	for _, e := range entries {
+		if e.Status != EntryMatched || e.ExerciseId == nil {
+			continue
+		}
+		records = append(records, e.Record(len(records)+1))
+	}
+	return records
+}
+
+func ImportReportFromRow(row db.ProgramImport) (*ImportReport, error) {
+	var entries []ImportEntry
+	if err := json.Unmarshal(row.Entries, &entries); err != nil {
+		return nil, err
+	}
+
+	report := &ImportReport{
+		ID:       row.ID.Bytes,
+		Filename: row.Filename,
+		Status:   row.Status,
+		Summary:  Summarize(entries),
+		Entries:  entries,
+	}
+	if row.ProgramID.Valid {
+		programID := uuid.UUID(row.ProgramID.Bytes)
+		report.ProgramID = &programID
+	}
+	return report, nil
+}
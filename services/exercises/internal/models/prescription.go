@@ -0,0 +1,206 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+)
+
+// SetPrescription describes a single prescribed set of a program item.
+// Every field is optional, which of them make sense depends on the category
+// of the exercise (e.g. "8 reps @ RPE 8, 90s rest", "30s hold", "2000m row").
+type SetPrescription struct {
+	Reps            *int     `json:"reps,omitempty" example:"8"`
+	WeightKg        *float64 `json:"weightKg,omitempty" example:"80"`
+	OneRmPercent    *float64 `json:"oneRmPercent,omitempty" example:"75"`
+	RPE             *float64 `json:"rpe,omitempty" example:"8"`
+	RIR             *int     `json:"rir,omitempty" example:"2"`
+	Tempo           *string  `json:"tempo,omitempty" example:"3-1-1-0"`
+	RestSeconds     *int     `json:"restSeconds,omitempty" example:"90"`
+	DurationSeconds *int     `json:"durationSeconds,omitempty" example:"30"`
+	DistanceMeters  *float64 `json:"distanceMeters,omitempty" example:"2000"`
+}
+
+// categoryRule lists which prescription fields are meaningful for a category.
+type categoryRule struct {
+	load     bool // absolute weight
+	oneRm    bool // weight as a percentage of 1RM
+	effort   bool // RPE / RIR
+	tempo    bool
+	distance bool
+	timed    bool // needs a duration or a distance instead of reps
+}
+
+var categoryRules = map[db.CategoryT]categoryRule{
+	db.CategoryTStrength:             {load: true, oneRm: true, effort: true, tempo: true},
+	db.CategoryTPowerlifting:         {load: true, oneRm: true, effort: true, tempo: true},
+	db.CategoryTOlympicweightlifting: {load: true, oneRm: true, effort: true},
+	db.CategoryTStrongman:            {load: true, oneRm: true, effort: true, distance: true},
+	db.CategoryTPlyometrics:          {load: true, effort: true, distance: true},
+	db.CategoryTStretching:           {},
+	db.CategoryTCardio:               {effort: true, distance: true, timed: true},
+}
+
+// tempoPattern accepts eccentric-pause-concentric-pause notation such as
+// "3-1-1-0" or "31X0", where X stands for an explosive phase.
+var tempoPattern = regexp.MustCompile(`^[0-9X]-?[0-9X]-?[0-9X]-?[0-9X]$`)
+
+// Validate checks the prescription on its own and against the rules of the
+// exercise's category. An unknown category only gets the generic checks.
+func (s SetPrescription) Validate(category db.NullCategoryT) error {
+	if s.Reps == nil && s.DurationSeconds == nil && s.DistanceMeters == nil {
+		return errors.New("one of reps, durationSeconds or distanceMeters is required")
+	}
+	if s.Reps != nil && *s.Reps <= 0 {
+		return errors.New("reps must be positive")
+	}
+	if s.DurationSeconds != nil && *s.DurationSeconds <= 0 {
+		return errors.New("durationSeconds must be positive")
+	}
+	if s.DistanceMeters != nil && *s.DistanceMeters <= 0 {
+		return errors.New("distanceMeters must be positive")
+	}
+	if s.RestSeconds != nil && *s.RestSeconds < 0 {
+		return errors.New("restSeconds can't be negative")
+	}
+	if s.WeightKg != nil && s.OneRmPercent != nil {
+		return errors.New("weightKg and oneRmPercent are mutually exclusive")
+	}
+	if s.WeightKg != nil && *s.WeightKg < 0 {
+		return errors.New("weightKg can't be negative")
+	}
+	if s.OneRmPercent != nil && (*s.OneRmPercent <= 0 || *s.OneRmPercent > 110) {
+		return errors.New("oneRmPercent must be in (0, 110]")
+	}
+	if s.RPE != nil && s.RIR != nil {
+		return errors.New("rpe and rir are mutually exclusive")
+	}
+	if s.RPE != nil && (*s.RPE < 1 || *s.RPE > 10 || math.Mod(*s.RPE*2, 1) != 0) {
+		return errors.New("rpe must be between 1 and 10 in steps of 0.5")
+	}
+	if s.RIR != nil && (*s.RIR < 0 || *s.RIR > 10) {
+		return errors.New("rir must be between 0 and 10")
+	}
+	if s.Tempo != nil && !tempoPattern.MatchString(*s.Tempo) {
+		return fmt.Errorf("invalid tempo %q, expected something like 3-1-1-0", *s.Tempo)
+	}
+
+	if !category.Valid {
+		return nil
+	}
+	rule, ok := categoryRules[category.CategoryT]
+	if !ok {
+		return nil
+	}
+	switch {
+	case s.WeightKg != nil && !rule.load:
+		return fmt.Errorf("weightKg is not allowed for %s exercises", category.CategoryT)
+	case s.OneRmPercent != nil && !rule.oneRm:
+		return fmt.Errorf("oneRmPercent is not allowed for %s exercises", category.CategoryT)
+	case (s.RPE != nil || s.RIR != nil) && !rule.effort:
+		return fmt.Errorf("rpe and rir are not allowed for %s exercises", category.CategoryT)
+	case s.Tempo != nil && !rule.tempo:
+		return fmt.Errorf("tempo is not allowed for %s exercises", category.CategoryT)
+	case s.DistanceMeters != nil && !rule.distance:
+		return fmt.Errorf("distanceMeters is not allowed for %s exercises", category.CategoryT)
+	case rule.timed && s.DurationSeconds == nil && s.DistanceMeters == nil:
+		return fmt.Errorf("%s exercises need a durationSeconds or distanceMeters", category.CategoryT)
+	}
+	return nil
+}
+
+// Validate checks a program item, including each of its set prescriptions.
+func (r ProgramRecord) Validate(category db.NullCategoryT) error {
+	if r.Idx < 0 {
+		return errors.New("idx can't be negative")
+	}
+	if r.Day < 0 {
+		return errors.New("day can't be negative")
+	}
+	if len(r.Prescriptions) == 0 {
+		if r.Sets <= 0 || r.Reps <= 0 {
+			return errors.New("sets and reps must be positive when no prescriptions are given")
+		}
+		return nil
+	}
+	for i, set := range r.Prescriptions {
+		if err := set.Validate(category); err != nil {
+			return fmt.Errorf("set %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// Normalize keeps the summary Sets and Reps fields of the record in line
+// with its prescriptions, so clients that only read them still get a
+// sensible value. Items without a day belong to the first one.
+func (r *ProgramRecord) Normalize() {
+	if r.Day == 0 {
+		r.Day = 1
+	}
+	if len(r.Prescriptions) == 0 {
+		return
+	}
+	r.Sets = len(r.Prescriptions)
+	r.Reps = 0
+	if r.Prescriptions[0].Reps != nil {
+		r.Reps = *r.Prescriptions[0].Reps
+	}
+}
+
+// SetList returns the prescriptions of the record, expanding plain sets and
+// reps into identical sets when none were given.
+func (r ProgramRecord) SetList() []SetPrescription {
+	if len(r.Prescriptions) > 0 {
+		return r.Prescriptions
+	}
+	sets := make([]SetPrescription, 0, r.Sets)
+	for i := 0; i < r.Sets; i++ {
+		reps := r.Reps
+		sets = append(sets, SetPrescription{Reps: &reps})
+	}
+	return sets
+}
+
+// InsertParams maps the prescription to the sqlc insert parameters of the
+// setNumber-th set of the program item at idx.
+func (s SetPrescription) InsertParams(programID pgtype.UUID, idx, setNumber int32) db.InsertToProgramSetsParams {
+	return db.InsertToProgramSetsParams{
+		ProgramID:       programID,
+		Idx:             idx,
+		SetNumber:       setNumber,
+		Reps:            toInt4(s.Reps),
+		WeightKg:        toFloat8(s.WeightKg),
+		OneRmPercent:    toFloat8(s.OneRmPercent),
+		Rpe:             toFloat8(s.RPE),
+		Rir:             toInt4(s.RIR),
+		Tempo:           toText(s.Tempo),
+		RestSeconds:     toInt4(s.RestSeconds),
+		DurationSeconds: toInt4(s.DurationSeconds),
+		DistanceMeters:  toFloat8(s.DistanceMeters),
+	}
+}
+
+// PrescriptionsFromRows groups the set rows of a program by item idx.
+func PrescriptionsFromRows(rows []db.GetProgramSetsByIdRow) map[int32][]SetPrescription {
+	sets := make(map[int32][]SetPrescription)
+	for _, row := range rows {
+		sets[row.Idx] = append(sets[row.Idx], SetPrescription{
+			Reps:            fromInt4(row.Reps),
+			WeightKg:        fromFloat8(row.WeightKg),
+			OneRmPercent:    fromFloat8(row.OneRmPercent),
+			RPE:             fromFloat8(row.Rpe),
+			RIR:             fromInt4(row.Rir),
+			Tempo:           fromText(row.Tempo),
+			RestSeconds:     fromInt4(row.RestSeconds),
+			DurationSeconds: fromInt4(row.DurationSeconds),
+			DistanceMeters:  fromFloat8(row.DistanceMeters),
+		})
+	}
+	return sets
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -11,6 +12,34 @@ import (
 type CompleteProgram struct {
 	UUID      uuid.UUID `json:"uuid" example:"123e4567-e89b-12d3-a456-426614174000"`
 	Exercises []ProgramExercise
+	Duration  *ProgramDuration `json:"duration,omitempty"`
+}
+
+// ProgramDuration is the estimated length of each session of a program, one
+// per day, and of the whole program. Minutes are rounded up.
+type ProgramDuration struct {
+	Sessions              []SessionDuration `json:"sessions"`
+	TotalSeconds          int               `json:"totalSeconds" example:"10800"`
+	TotalMinutes          int               `json:"totalMinutes" example:"180"`
+	LongestSessionMinutes int               `json:"longestSessionMinutes" example:"65"`
+	AverageSessionMinutes int               `json:"averageSessionMinutes" example:"60"`
+}
+
+type SessionDuration struct {
+	Day     int `json:"day" example:"1"`
+	Seconds int `json:"seconds" example:"3600"`
+	Minutes int `json:"minutes" example:"60"`
+}
+
+// ProgramSummary is a program in the program list of a user.
+type ProgramSummary struct {
+	UUID       uuid.UUID       `json:"uuid" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Visibility db.VisibilityT  `json:"visibility" example:"private"`
+	ForkedFrom *uuid.UUID      `json:"forkedFrom,omitempty" example:"123e4567-e89b-12d3-a456-426614174000"`
+	CreatedAt  time.Time       `json:"createdAt"`
+	Days       int             `json:"days" example:"3"`
+	Items      int             `json:"items" example:"15"`
+	Duration   ProgramDuration `json:"duration"`
 }
 
 type Program struct {
@@ -19,20 +48,26 @@ type Program struct {
 }
 
 type ProgramRecord struct {
-	ExerciseId int `json:"exerciseId" example:"12"`
-	Idx        int `json:"idx" example:"1"`
-	Sets       int `json:"sets" example:"3"`
-	Reps       int `json:"reps" example:"10"`
+	ExerciseId    int               `json:"exerciseId" example:"12"`
+	Idx           int               `json:"idx" example:"1"`
+	Day           int               `json:"day" example:"1"`
+	Sets          int               `json:"sets" example:"3"`
+	Reps          int               `json:"reps" example:"10"`
+	Prescriptions []SetPrescription `json:"prescriptions,omitempty"`
 }
 
 type ProgramExercise struct {
-	Exercise Exercise
-	Idx      int `json:"idx" example:"1"`
-	Sets     int `json:"sets" example:"3"`
-	Reps     int `json:"reps" example:"10"`
+	Exercise      Exercise
+	Idx           int               `json:"idx" example:"1"`
+	Day           int               `json:"day" example:"1"`
+	Sets          int               `json:"sets" example:"3"`
+	Reps          int               `json:"reps" example:"10"`
+	Prescriptions []SetPrescription `json:"prescriptions,omitempty"`
 }
 
-func FullProgramFromRows(uuid uuid.UUID, rows []db.GetFullProgramByIdRow) *CompleteProgram {
+func FullProgramFromRows(uuid uuid.UUID, rows []db.GetFullProgramByIdRow, setRows []db.GetProgramSetsByIdRow) *CompleteProgram {
+	prescriptions := PrescriptionsFromRows(setRows)
+
 	exercises := make([]ProgramExercise, 0, len(rows))
 	for _, row := range rows {
 		exerciseNames := strings.Split(string(row.NamesGrouped), ",")
@@ -40,14 +75,18 @@ func FullProgramFromRows(uuid uuid.UUID, rows []db.GetFullProgramByIdRow) *Compl
 		visuals := strings.Split(string(row.MusclesGrouped), ",")
 
 		exercise := ProgramExercise{
-			Idx:  int(row.Idx),
-			Sets: int(row.Sets),
-			Reps: int(row.Reps),
+			Idx:           int(row.Idx),
+			Day:           int(row.Day),
+			Sets:          int(row.Sets),
+			Reps:          int(row.Reps),
+			Prescriptions: prescriptions[row.Idx],
 			Exercise: Exercise{
-				Names:     exerciseNames,
-				Equipment: row.Equipment.EquipmentT,
-				Muscles:   muscles,
-				Visuals:   visuals,
+				Id:           row.ExerciseID,
+				Names:        exerciseNames,
+				Equipment:    row.Equipment.EquipmentT,
+				Muscles:      muscles,
+				Visuals:      visuals,
+				Instructions: row.Instructions,
 			},
 		}
 
@@ -55,19 +94,22 @@ func FullProgramFromRows(uuid uuid.UUID, rows []db.GetFullProgramByIdRow) *Compl
 	}
 
 	return &CompleteProgram{
-		uuid,
-		exercises,
+		UUID:      uuid,
+		Exercises: exercises,
 	}
 }
 
-func ProgramFromRows(uuid uuid.UUID, rows []db.GetProgramByIdRow) *Program {
+func ProgramFromRows(uuid uuid.UUID, rows []db.GetProgramByIdRow, setRows []db.GetProgramSetsByIdRow) *Program {
+	prescriptions := PrescriptionsFromRows(setRows)
 	exercises := make([]ProgramRecord, 0, len(rows))
 	for _, row := range rows {
 		exercise := ProgramRecord{
-			Idx:        int(row.Idx),
-			Sets:       int(row.Sets),
-			Reps:       int(row.Reps),
-			ExerciseId: int(row.ExerciseID),
+			Idx:           int(row.Idx),
+			Day:           int(row.Day),
+			Sets:          int(row.Sets),
+			Reps:          int(row.Reps),
+			ExerciseId:    int(row.ExerciseID),
+			Prescriptions: prescriptions[row.Idx],
 		}
 
 		exercises = append(exercises, exercise)
@@ -78,3 +120,30 @@ func ProgramFromRows(uuid uuid.UUID, rows []db.GetProgramByIdRow) *Program {
 		exercises,
 	}
 }
+
+// Records returns the items of the program without their exercise details.
+func (p *CompleteProgram) Records() []ProgramRecord {
+	records := make([]ProgramRecord, 0, len(p.Exercises))
+	for _, ex := range p.Exercises {
+		records = append(records, ProgramRecord{
+			ExerciseId:    int(ex.Exercise.Id),
+			Idx:           ex.Idx,
+			Day:           ex.Day,
+			Sets:          ex.Sets,
+			Reps:          ex.Reps,
+			Prescriptions: ex.Prescriptions,
+		})
+	}
+	return records
+}
+
+// ProgramDay returns the items of a program that belong to the given day.
+func (p *Program) ProgramDay(day int) []ProgramRecord {
+	items := make([]ProgramRecord, 0)
+	for _, item := range p.Exercises {
+		if item.Day == day {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// NextSession is a proposed prescription for the next workout on a program
+// day, derived from the last logged workout on that day.
+type NextSession struct {
+	ProgramID      uuid.UUID         `json:"programId" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Day            int               `json:"day" example:"1"`
+	Rule           string            `json:"rule" example:"linear"`
+	BasedOnWorkout *uuid.UUID        `json:"basedOnWorkout,omitempty" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Items          []ProgressionItem `json:"items"`
+}
+
+type ProgressionItem struct {
+	Idx        int               `json:"idx" example:"1"`
+	ExerciseId int               `json:"exerciseId" example:"12"`
+	Current    []SetPrescription `json:"current"`
+	Proposed   []SetPrescription `json:"proposed"`
+	Reason     string            `json:"reason" example:"all sets completed, adding 2.5kg"`
+}
+
+// AcceptNextSessionRequest carries the proposed prescriptions to write into
+// the program, typically the items of a NextSession as returned.
+type AcceptNextSessionRequest struct {
+	Items []ProgressionItem `json:"items"`
+}
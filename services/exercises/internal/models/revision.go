@@ -0,0 +1,136 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+)
+
+// ProgramRevision is an immutable snapshot of the items of a program, taken
+// every time they change. Items are left out of revision listings.
+type ProgramRevision struct {
+	Revision     int                `json:"revision" example:"3"`
+	Source       db.RevisionSourceT `json:"source" example:"updated"`
+	RestoredFrom *int               `json:"restoredFrom,omitempty" example:"1"`
+	CreatedBy    *int64             `json:"createdBy,omitempty" example:"42"`
+	CreatedAt    time.Time          `json:"createdAt"`
+	ItemCount    int                `json:"itemCount" example:"6"`
+	Exercises    []ProgramRecord    `json:"exercises,omitempty"`
+}
+
+// ProgramDiff lists the items added, removed and modified between two
+// revisions. Items are matched on their idx.
+type ProgramDiff struct {
+	From     int             `json:"from" example:"2"`
+	To       int             `json:"to" example:"3"`
+	Added    []ProgramRecord `json:"added"`
+	Removed  []ProgramRecord `json:"removed"`
+	Modified []ItemChange    `json:"modified"`
+}
+
+// ItemChange is an item present in both revisions with the fields that
+// differ.
+type ItemChange struct {
+	Idx    int           `json:"idx" example:"1"`
+	Fields []string      `json:"fields" example:"prescriptions"`
+	Before ProgramRecord `json:"before"`
+	After  ProgramRecord `json:"after"`
+}
+
+func ProgramRevisionFromRow(row db.ProgramRevision) (*ProgramRevision, error) {
+	var items []ProgramRecord
+	if err := json.Unmarshal(row.Items, &items); err != nil {
+		return nil, err
+	}
+	revision := &ProgramRevision{
+		Revision:     int(row.Revision),
+		Source:       row.Source,
+		RestoredFrom: fromInt4(row.RestoredFrom),
+		CreatedAt:    row.CreatedAt.Time,
+		ItemCount:    len(items),
+		Exercises:    items,
+	}
+	if row.CreatedBy.Valid {
+		revision.CreatedBy = &row.CreatedBy.Int64
+	}
+	return revision, nil
+}
+
+func ProgramRevisionsFromRows(rows []db.GetProgramRevisionsRow) []ProgramRevision {
+	revisions := make([]ProgramRevision, 0, len(rows))
+	for _, row := range rows {
+		revision := ProgramRevision{
+			Revision:     int(row.Revision),
+			Source:       row.Source,
+			RestoredFrom: fromInt4(row.RestoredFrom),
+			CreatedAt:    row.CreatedAt.Time,
+			ItemCount:    int(row.ItemCount),
+		}
+		if row.CreatedBy.Valid {
+			revision.CreatedBy = &row.CreatedBy.Int64
+		}
+		revisions = append(revisions, revision)
+	}
+	return revisions
+}
+
+// DiffRevisions compares the items of two revisions.
+func DiffRevisions(from, to *ProgramRevision) ProgramDiff {
+	diff := ProgramDiff{
+		From:     from.Revision,
+		To:       to.Revision,
+		Added:    make([]ProgramRecord, 0),
+		Removed:  make([]ProgramRecord, 0),
+		Modified: make([]ItemChange, 0),
+	}
+
+	before := make(map[int]ProgramRecord, len(from.Exercises))
+	for _, item := range from.Exercises {
+		before[item.Idx] = item
+	}
+	after := make(map[int]bool, len(to.Exercises))
+	for _, item := range to.Exercises {
+		after[item.Idx] = true
+		old, ok := before[item.Idx]
+		if !ok {
+			diff.Added = append(diff.Added, item)
+			continue
+		}
+		if fields := changedFields(old, item); len(fields) > 0 {
+			diff.Modified = append(diff.Modified, ItemChange{Idx: item.Idx, Fields: fields, Before: old, After: item})
+		}
+	}
+	for _, item := range from.Exercises {
+		if !after[item.Idx] {
+			diff.Removed = append(diff.Removed, item)
+		}
+	}
+
+	sort.Slice(diff.Added, func(i, j int) bool { return diff.Added[i].Idx < diff.Added[j].Idx })
+	sort.Slice(diff.Removed, func(i, j int) bool { return diff.Removed[i].Idx < diff.Removed[j].Idx })
+	sort.Slice(diff.Modified, func(i, j int) bool { return diff.Modified[i].Idx < diff.Modified[j].Idx })
+	return diff
+}
+
+func changedFields(a, b ProgramRecord) []string {
+	fields := make([]string, 0)
+	if a.ExerciseId != b.ExerciseId {
+		fields = append(fields, "exerciseId")
+	}
+	if a.Day != b.Day {
+		fields = append(fields, "day")
+	}
+	if a.Sets != b.Sets {
+		fields = append(fields, "sets")
+	}
+	if a.Reps != b.Reps {
+		fields = append(fields, "reps")
+	}
+	if (len(a.Prescriptions) > 0 || len(b.Prescriptions) > 0) && !reflect.DeepEqual(a.Prescriptions, b.Prescriptions) {
+		fields = append(fields, "prescriptions")
+	}
+	return fields
+}
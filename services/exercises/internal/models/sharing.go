@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+)
+
+// ProgramSharing tells who can read a program. Share links are only listed
+// for the owner.
+type ProgramSharing struct {
+	ProgramID  uuid.UUID      `json:"programId" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Visibility db.VisibilityT `json:"visibility" example:"link"`
+	ForkedFrom *uuid.UUID     `json:"forkedFrom,omitempty" example:"123e4567-e89b-12d3-a456-426614174000"`
+	ShareLinks []ShareLink    `json:"shareLinks"`
+}
+
+// ShareLink grants read access to a link-only program to whoever passes its
+// token as the share query parameter.
+type ShareLink struct {
+	Token     string    `json:"token" example:"q5bY1z0k3m8Jc2VhX9t7Ww"`
+	Path      string    `json:"path" example:"/api/program/123e4567-e89b-12d3-a456-426614174000?share=q5bY1z0k3m8Jc2VhX9t7Ww"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type VisibilityRequest struct {
+	Visibility db.VisibilityT `json:"visibility" example:"public"`
+}
+
+// CanRead reports whether the user can read the program without a share
+// link: public and unowned programs are readable by anyone, the others only
+// by their owner.
+func CanRead(access db.ProgramAccess, userID int64, authenticated bool) bool {
+	if access.Visibility == db.VisibilityTPublic || !access.OwnerID.Valid {
+		return true
+	}
+	return authenticated && access.OwnerID.Int64 == userID
+}
+
+// IsOwner reports whether the user owns the program.
+func IsOwner(access db.ProgramAccess, userID int64, authenticated bool) bool {
+	return authenticated && access.OwnerID.Valid && access.OwnerID.Int64 == userID
+}
+
+func ShareLinkFromRow(row db.ProgramShareToken) ShareLink {
+	programID := uuid.UUID(row.ProgramID.Bytes)
+	return ShareLink{
+		Token:     row.Token,
+		Path:      "/api/program/" + programID.String() + "?share=" + row.Token,
+		CreatedAt: row.CreatedAt.Time,
+	}
+}
+
+func ProgramSharingFromRows(access db.ProgramAccess, tokens []db.ProgramShareToken) *ProgramSharing {
+	sharing := &ProgramSharing{
+		ProgramID:  access.ProgramID.Bytes,
+		Visibility: access.Visibility,
+		ShareLinks: make([]ShareLink, 0, len(tokens)),
+	}
+	if access.ForkedFrom.Valid {
+		forkedFrom := uuid.UUID(access.ForkedFrom.Bytes)
+		sharing.ForkedFrom = &forkedFrom
+	}
+	for _, token := range tokens {
+		sharing.ShareLinks = append(sharing.ShareLinks, ShareLinkFromRow(token))
+	}
+	return sharing
+}
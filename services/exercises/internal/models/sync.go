@@ -0,0 +1,217 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+)
+
+// Entities of the tombstones, the rows clients drop when they sync.
+const (
+	SyncEntityFavorite = "favorite"
+	SyncEntityExercise = "exercise"
+)
+
+// Types of the changes clients push.
+const (
+	SyncFavoriteAdd    = "favorite.add"
+	SyncFavoriteRemove = "favorite.remove"
+	SyncWorkoutStart   = "workout.start"
+	SyncSetLog         = "set.log"
+	SyncWorkoutFinish  = "workout.finish"
+	SyncProgramUpdate  = "program.update"
+)
+
+// Outcomes of a pushed change. A duplicate was applied by an earlier push,
+// a conflict lost to the state of the server, which comes with the result.
+const (
+	SyncApplied   = "applied"
+	SyncDuplicate = "duplicate"
+	SyncConflict  = "conflict"
+	SyncRejected  = "rejected"
+)
+
+// SyncOverlap is how far before the clock of the database checkpoints are
+// issued, so rows written by transactions still running when a client
+// pulls are sent again on its next pull.
+const SyncOverlap = time.Minute
+
+// SyncPull holds the changes after a checkpoint. Rows changed around the
+// checkpoint may come again, clients replace them by ID.
+type SyncPull struct {
+	Checkpoint string          `json:"checkpoint" example:"MTcxMDAwMDAwMDAwMDAwMDAwMA"`
+	Programs   []SyncProgram   `json:"programs"`
+	Workouts   []SyncWorkout   `json:"workouts"`
+	Favorites  []SyncFavorite  `json:"favorites"`
+	Exercises  []Exercise      `json:"exercises"`
+	Deleted    []SyncTombstone `json:"deleted"`
+}
+
+// SyncProgram is a program of the user at its latest revision, the base
+// revision of the updates pushed for it.
+type SyncProgram struct {
+	ID         uuid.UUID       `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Visibility db.VisibilityT  `json:"visibility" example:"private"`
+	Revision   int             `json:"revision" example:"3"`
+	Items      []ProgramRecord `json:"items"`
+	UpdatedAt  time.Time       `json:"updatedAt"`
+}
+
+type SyncWorkout struct {
+	ID         uuid.UUID    `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	ProgramID  uuid.UUID    `json:"programId" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Day        int          `json:"day" example:"1"`
+	StartedAt  time.Time    `json:"startedAt"`
+	FinishedAt *time.Time   `json:"finishedAt,omitempty"`
+	Notes      *string      `json:"notes,omitempty" example:"Felt strong"`
+	Sets       []WorkoutSet `json:"sets"`
+	UpdatedAt  time.Time    `json:"updatedAt"`
+}
+
+type SyncFavorite struct {
+	ExerciseID int       `json:"exerciseId" example:"12"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// SyncTombstone is a row deleted after the checkpoint.
+type SyncTombstone struct {
+	Entity    string    `json:"entity" example:"favorite"`
+	ID        string    `json:"id" example:"12"`
+	DeletedAt time.Time `json:"deletedAt"`
+}
+
+// SyncPush carries the changes made offline, applied in order.
+type SyncPush struct {
+	Changes []SyncChange `json:"changes"`
+}
+
+// SyncChange is one change made offline. ID is chosen by the client to
+// match the results, Data depends on Type.
+type SyncChange struct {
+	ID   string          `json:"id" example:"c1"`
+	Type string          `json:"type" example:"set.log"`
+	Data json.RawMessage `json:"data" swaggertype:"object"`
+}
+
+type SyncResult struct {
+	ID     string `json:"id" example:"c1"`
+	Status string `json:"status" example:"applied"`
+	Error  string `json:"error,omitempty"`
+	// Server is the state kept by the server, set on conflicts.
+	Server any `json:"server,omitempty"`
+}
+
+type SyncPushResponse struct {
+	Results []SyncResult `json:"results"`
+}
+
+// SyncFavoriteChange is the data of favorite.add and favorite.remove.
+type SyncFavoriteChange struct {
+	ExerciseID int `json:"exerciseId" example:"12"`
+}
+
+// SyncWorkoutStartChange is the data of workout.start, ID is generated by
+// the client.
+type SyncWorkoutStartChange struct {
+	ID        uuid.UUID `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	ProgramID uuid.UUID `json:"programId" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Day       int       `json:"day" example:"1"`
+	StartedAt time.Time `json:"startedAt"`
+}
+
+// SyncSetLogChange is the data of set.log, ID is generated by the client.
+type SyncSetLogChange struct {
+	ID          uuid.UUID `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	WorkoutID   uuid.UUID `json:"workoutId" example:"123e4567-e89b-12d3-a456-426614174000"`
+	PerformedAt time.Time `json:"performedAt"`
+	LogSetRequest
+}
+
+// SyncWorkoutFinishChange is the data of workout.finish.
+type SyncWorkoutFinishChange struct {
+	WorkoutID  uuid.UUID `json:"workoutId" example:"123e4567-e89b-12d3-a456-426614174000"`
+	FinishedAt time.Time `json:"finishedAt"`
+	Notes      *string   `json:"notes,omitempty" example:"Felt strong"`
+}
+
+// SyncProgramUpdateChange is the data of program.update, BaseRevision is
+// the revision the client edited.
+type SyncProgramUpdateChange struct {
+	ProgramID    uuid.UUID       `json:"programId" example:"123e4567-e89b-12d3-a456-426614174000"`
+	BaseRevision int             `json:"baseRevision" example:"3"`
+	Items        []ProgramRecord `json:"items"`
+}
+
+var errCheckpoint = errors.New("invalid checkpoint")
+
+// EncodeCheckpoint issues the checkpoint of a pull served at clock.
+func EncodeCheckpoint(clock time.Time) string {
+	since := clock.Add(-SyncOverlap).UnixNano()
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(since, 10)))
+}
+
+// ParseCheckpoint returns the time changes are pulled after. An empty
+// checkpoint pulls everything.
+func ParseCheckpoint(checkpoint string) (pgtype.Timestamptz, error) {
+	if checkpoint == "" {
+		return pgtype.Timestamptz{Time: time.Unix(0, 0), Valid: true}, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(checkpoint)
+	if err != nil {
+		return pgtype.Timestamptz{}, errCheckpoint
+	}
+	nanos, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil || nanos < 0 {
+		return pgtype.Timestamptz{}, errCheckpoint
+	}
+	return pgtype.Timestamptz{Time: time.Unix(0, nanos).UTC(), Valid: true}, nil
+}
+
+func SyncWorkoutsFromRows(rows []db.Workout, setRows []db.WorkoutSet) []SyncWorkout {
+	sets := make(map[[16]byte][]WorkoutSet, len(rows))
+	for _, s := range setRows {
+		sets[s.WorkoutID.Bytes] = append(sets[s.WorkoutID.Bytes], WorkoutSetFromRow(s))
+	}
+
+	workouts := make([]SyncWorkout, 0, len(rows))
+	for _, row := range rows {
+		workout := SyncWorkout{
+			ID:         row.ID.Bytes,
+			ProgramID:  row.ProgramID.Bytes,
+			Day:        int(row.Day),
+			StartedAt:  row.StartedAt.Time,
+			FinishedAt: fromTimestamptz(row.FinishedAt),
+			Notes:      fromText(row.Notes),
+			Sets:       sets[row.ID.Bytes],
+			UpdatedAt:  row.UpdatedAt.Time,
+		}
+		if workout.Sets == nil {
+			workout.Sets = []WorkoutSet{}
+		}
+		workouts = append(workouts, workout)
+	}
+	return workouts
+}
+
+func SyncFavoritesFromRows(rows []db.ExerciseFavorite) []SyncFavorite {
+	favorites := make([]SyncFavorite, 0, len(rows))
+	for _, row := range rows {
+		favorites = append(favorites, SyncFavorite{ExerciseID: int(row.ExerciseID), CreatedAt: row.CreatedAt.Time})
+	}
+	return favorites
+}
+
+func SyncTombstonesFromRows(rows []db.SyncTombstone) []SyncTombstone {
+	deleted := make([]SyncTombstone, 0, len(rows))
+	for _, row := range rows {
+		deleted = append(deleted, SyncTombstone{Entity: row.Entity, ID: row.EntityID, DeletedAt: row.DeletedAt.Time})
+	}
+	return deleted
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+)
+
+// WebhookRequest registers a URL to be called back on the given events.
+type WebhookRequest struct {
+	URL    string   `json:"url" example:"https://example.com/hooks/guddy"`
+	Events []string `json:"events" example:"program.created,workout.completed"`
+}
+
+// Webhook is a registered callback URL. The secret signing its payloads is
+// only returned when it is created.
+type Webhook struct {
+	ID        uuid.UUID `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	URL       string    `json:"url" example:"https://example.com/hooks/guddy"`
+	Events    []string  `json:"events" example:"program.created,workout.completed"`
+	Secret    string    `json:"secret,omitempty" example:"q5bY1z0k3m8Jc2VhX9t7WwAb3kLp0sQe"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// WebhookDelivery is an event sent, or to be sent, to a webhook with the
+// outcome of its last attempt. The listing adds every attempt made.
+type WebhookDelivery struct {
+	ID             uuid.UUID                `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	EventID        uuid.UUID                `json:"eventId" example:"123e4567-e89b-12d3-a456-426614174000"`
+	EventType      string                   `json:"eventType" example:"workout.completed"`
+	Payload        json.RawMessage          `json:"payload" swaggertype:"object"`
+	Status         string                   `json:"status" example:"failed"`
+	Attempts       int                      `json:"attempts" example:"8"`
+	ResponseStatus *int                     `json:"responseStatus,omitempty" example:"503"`
+	LastError      *string                  `json:"lastError,omitempty" example:"unexpected status 503"`
+	NextAttemptAt  *time.Time               `json:"nextAttemptAt,omitempty"`
+	CreatedAt      time.Time                `json:"createdAt"`
+	DeliveredAt    *time.Time               `json:"deliveredAt,omitempty"`
+	AttemptHistory []WebhookDeliveryAttempt `json:"attemptHistory,omitempty"`
+}
+
+// WebhookDeliveryAttempt is one try at sending a delivery.
+type WebhookDeliveryAttempt struct {
+	Attempt        int       `json:"attempt" example:"1"`
+	ResponseStatus *int      `json:"responseStatus,omitempty" example:"503"`
+	Error          *string   `json:"error,omitempty" example:"unexpected status 503"`
+	DurationMs     int       `json:"durationMs" example:"120"`
+	AttemptedAt    time.Time `json:"attemptedAt"`
+}
+
+func WebhookFromRow(row db.Webhook) Webhook {
+	return Webhook{
+		ID:        row.ID.Bytes,
+		URL:       row.Url,
+		Events:    row.Events,
+		CreatedAt: row.CreatedAt.Time,
+	}
+}
+
+func WebhookDeliveryFromRow(row db.WebhookDelivery) WebhookDelivery {
+	delivery := WebhookDelivery{
+		ID:        row.ID.Bytes,
+		EventID:   row.EventID.Bytes,
+		EventType: row.EventType,
+		Payload:   row.Payload,
+		Status:    string(row.Status),
+		Attempts:  int(row.Attempts),
+		CreatedAt: row.CreatedAt.Time,
+	}
+	if row.ResponseStatus.Valid {
+		status := int(row.ResponseStatus.Int32)
+		delivery.ResponseStatus = &status
+	}
+	if row.LastError.Valid {
+		delivery.LastError = &row.LastError.String
+	}
+	if row.Status == db.DeliveryStatusTPending {
+		delivery.NextAttemptAt = &row.NextAttemptAt.Time
+	}
+	if row.DeliveredAt.Valid {
+		delivery.DeliveredAt = &row.DeliveredAt.Time
+	}
+	return delivery
+}
+
+func WebhookDeliveryAttemptFromRow(row db.WebhookDeliveryAttempt) WebhookDeliveryAttempt {
+	attempt := WebhookDeliveryAttempt{
+		Attempt:     int(row.Attempt),
+		DurationMs:  int(row.DurationMs),
+		AttemptedAt: row.AttemptedAt.Time,
+	}
+	if row.ResponseStatus.Valid {
+		status := int(row.ResponseStatus.Int32)
+		attempt.ResponseStatus = &status
+	}
+	if row.Error.Valid {
+		attempt.Error = &row.Error.String
+	}
+	return attempt
+}
@@ -0,0 +1,194 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+)
+
+type Workout struct {
+	ID         uuid.UUID       `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	ProgramID  uuid.UUID       `json:"programId" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Day        int             `json:"day" example:"1"`
+	StartedAt  time.Time       `json:"startedAt"`
+	FinishedAt *time.Time      `json:"finishedAt,omitempty"`
+	Notes      *string         `json:"notes,omitempty" example:"Felt strong"`
+	Plan       []ProgramRecord `json:"plan"`
+	Sets       []WorkoutSet    `json:"sets"`
+	// NewRecords lists the personal records broken by the workout, only
+	// set in the response to finishing it.
+	NewRecords []PersonalRecord `json:"newRecords,omitempty"`
+}
+
+type WorkoutSummary struct {
+	ID         uuid.UUID  `json:"id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	ProgramID  uuid.UUID  `json:"programId" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Day        int        `json:"day" example:"1"`
+	StartedAt  time.Time  `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	Notes      *string    `json:"notes,omitempty" example:"Felt strong"`
+	SetCount   int        `json:"setCount" example:"15"`
+}
+
+// WorkoutSet is a set actually performed during a workout. ProgramIdx links
+// it to the program item it fulfils, it is empty for exercises done off-plan.
+type WorkoutSet struct {
+	ID              int64     `json:"id" example:"1"`
+	ProgramIdx      *int      `json:"programIdx,omitempty" example:"1"`
+	ExerciseId      int       `json:"exerciseId" example:"12"`
+	SetNumber       int       `json:"setNumber" example:"1"`
+	Reps            *int      `json:"reps,omitempty" example:"8"`
+	WeightKg        *float64  `json:"weightKg,omitempty" example:"80"`
+	RPE             *float64  `json:"rpe,omitempty" example:"8"`
+	DurationSeconds *int      `json:"durationSeconds,omitempty" example:"30"`
+	DistanceMeters  *float64  `json:"distanceMeters,omitempty" example:"2000"`
+	Notes           *string   `json:"notes,omitempty" example:"Grip slipped"`
+	PerformedAt     time.Time `json:"performedAt"`
+	// ClientID is the ID given by the client that logged the set offline.
+	ClientID *uuid.UUID `json:"clientId,omitempty" example:"123e4567-e89b-12d3-a456-426614174000"`
+}
+
+type StartWorkoutRequest struct {
+	ProgramID uuid.UUID `json:"programId" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Day       int       `json:"day" example:"1"`
+}
+
+// LogSetRequest records a performed set. Either ProgramIdx, to log against a
+// planned item, or ExerciseId, for an off-plan exercise, must be set.
+type LogSetRequest struct {
+	ProgramIdx      *int     `json:"programIdx,omitempty" example:"1"`
+	ExerciseId      *int     `json:"exerciseId,omitempty" example:"12"`
+	Reps            *int     `json:"reps,omitempty" example:"8"`
+	WeightKg        *float64 `json:"weightKg,omitempty" example:"80"`
+	RPE             *float64 `json:"rpe,omitempty" example:"8"`
+	DurationSeconds *int     `json:"durationSeconds,omitempty" example:"30"`
+	DistanceMeters  *float64 `json:"distanceMeters,omitempty" example:"2000"`
+	Notes           *string  `json:"notes,omitempty" example:"Grip slipped"`
+}
+
+type FinishWorkoutRequest struct {
+	Notes *string `json:"notes,omitempty" example:"Felt strong"`
+}
+
+func (s LogSetRequest) Validate() error {
+	if s.ProgramIdx == nil && s.ExerciseId == nil {
+		return errors.New("one of programIdx or exerciseId is required")
+	}
+	if s.Reps == nil && s.DurationSeconds == nil && s.DistanceMeters == nil {
+		return errors.New("one of reps, durationSeconds or distanceMeters is required")
+	}
+	if s.Reps != nil && *s.Reps < 0 {
+		return errors.New("reps can't be negative")
+	}
+	if s.WeightKg != nil && *s.WeightKg < 0 {
+		return errors.New("weightKg can't be negative")
+	}
+	if s.RPE != nil && (*s.RPE < 1 || *s.RPE > 10) {
+		return errors.New("rpe must be between 1 and 10")
+	}
+	if s.DurationSeconds != nil && *s.DurationSeconds < 0 {
+		return errors.New("durationSeconds can't be negative")
+	}
+	if s.DistanceMeters != nil && *s.DistanceMeters < 0 {
+		return errors.New("distanceMeters can't be negative")
+	}
+	return nil
+}
+
+// InsertParams maps the request to the sqlc insert parameters, once the
+// exercise and set number have been resolved by the caller.
+func (s LogSetRequest) InsertParams(workout db.Workout, exerciseID, setNumber int32) db.InsertWorkoutSetParams {
+	params := db.InsertWorkoutSetParams{
+		WorkoutID:       workout.ID,
+		ExerciseID:      exerciseID,
+		SetNumber:       setNumber,
+		ProgramIdx:      toInt4(s.ProgramIdx),
+		Reps:            toInt4(s.Reps),
+		WeightKg:        toFloat8(s.WeightKg),
+		Rpe:             toFloat8(s.RPE),
+		DurationSeconds: toInt4(s.DurationSeconds),
+		DistanceMeters:  toFloat8(s.DistanceMeters),
+		Notes:           toText(s.Notes),
+	}
+	if s.ProgramIdx != nil {
+		params.ProgramID = workout.ProgramID
+	}
+	return params
+}
+
+func (f FinishWorkoutRequest) Params(workoutID pgtype.UUID) db.FinishWorkoutParams {
+	return db.FinishWorkoutParams{ID: workoutID, Notes: toText(f.Notes)}
+}
+
+// NextSetNumber numbers a new set after the sets already logged for the
+// same exercise and program item.
+func NextSetNumber(logged []db.WorkoutSet, exerciseID int32, programIdx *int) int32 {
+	next := int32(1)
+	for _, s := range logged {
+		if s.ExerciseID == exerciseID && s.ProgramIdx == toInt4(programIdx) {
+			next++
+		}
+	}
+	return next
+}
+
+func WorkoutSetFromRow(row db.WorkoutSet) WorkoutSet {
+	set := WorkoutSet{
+		ID:              row.ID,
+		ProgramIdx:      fromInt4(row.ProgramIdx),
+		ExerciseId:      int(row.ExerciseID),
+		SetNumber:       int(row.SetNumber),
+		Reps:            fromInt4(row.Reps),
+		WeightKg:        fromFloat8(row.WeightKg),
+		RPE:             fromFloat8(row.Rpe),
+		DurationSeconds: fromInt4(row.DurationSeconds),
+		DistanceMeters:  fromFloat8(row.DistanceMeters),
+		Notes:           fromText(row.Notes),
+		PerformedAt:     row.PerformedAt.Time,
+	}
+	if row.ClientID.Valid {
+		id := uuid.UUID(row.ClientID.Bytes)
+		set.ClientID = &id
+	}
+	return set
+}
+
+// WorkoutFromRows builds a workout with its plan, the program items of the
+// workout's day, and the sets logged so far.
+func WorkoutFromRows(row db.Workout, plan []ProgramRecord, setRows []db.WorkoutSet) *Workout {
+	sets := make([]WorkoutSet, 0, len(setRows))
+	for _, s := range setRows {
+		sets = append(sets, WorkoutSetFromRow(s))
+	}
+
+	return &Workout{
+		ID:         row.ID.Bytes,
+		ProgramID:  row.ProgramID.Bytes,
+		Day:        int(row.Day),
+		StartedAt:  row.StartedAt.Time,
+		FinishedAt: fromTimestamptz(row.FinishedAt),
+		Notes:      fromText(row.Notes),
+		Plan:       plan,
+		Sets:       sets,
+	}
+}
+
+func WorkoutSummariesFromRows(rows []db.GetWorkoutsByUserRow) *[]WorkoutSummary {
+	workouts := make([]WorkoutSummary, 0, len(rows))
+	for _, row := range rows {
+		workouts = append(workouts, WorkoutSummary{
+			ID:         row.ID.Bytes,
+			ProgramID:  row.ProgramID.Bytes,
+			Day:        int(row.Day),
+			StartedAt:  row.StartedAt.Time,
+			FinishedAt: fromTimestamptz(row.FinishedAt),
+			Notes:      fromText(row.Notes),
+			SetCount:   int(row.SetCount),
+		})
+	}
+	return &workouts
+}
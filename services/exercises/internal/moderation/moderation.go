@@ -0,0 +1,84 @@
+package moderation
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+)
+
+// Action moves a submission from one status to the next.
+type Action string
+
+const (
+	// Submit hands a draft to the reviewers.
+	Submit Action = "submit"
+	// Withdraw takes a pending submission back to a draft, e.g. to edit
+	// the exercise before it gets reviewed.
+	Withdraw Action = "withdraw"
+	// Approve publishes the submitted exercise into the catalog.
+	Approve Action = "approve"
+	// Reject closes the submission with the reasons in a comment.
+	Reject Action = "reject"
+)
+
+// Actor is the side of a submission taking an action.
+type Actor int
+
+const (
+	Author Actor = iota
+	Reviewer
+)
+
+var (
+	ErrUnknownAction = errors.New("unknown action")
+	// ErrNotAllowed is returned when the actor can't take the action.
+	ErrNotAllowed = errors.New("action not allowed")
+	// ErrInvalidTransition is returned when the submission isn't in the
+	// status the action starts from.
+	ErrInvalidTransition = errors.New("invalid transition")
+)
+
+type transition struct {
+	from  db.SubmissionStatusT
+	to    db.SubmissionStatusT
+	actor Actor
+}
+
+// transitions is the state machine of submissions:
+//
+//	draft → pending → approved
+//	          ↓ ↑   ↘ rejected
+//	         draft
+//
+// Approved and rejected submissions are final, authors start a new one to
+// try again.
+var transitions = map[Action]transition{
+	Submit:   {from: db.SubmissionStatusTDraft, to: db.SubmissionStatusTPending, actor: Author},
+	Withdraw: {from: db.SubmissionStatusTPending, to: db.SubmissionStatusTDraft, actor: Author},
+	Approve:  {from: db.SubmissionStatusTPending, to: db.SubmissionStatusTApproved, actor: Reviewer},
+	Reject:   {from: db.SubmissionStatusTPending, to: db.SubmissionStatusTRejected, actor: Reviewer},
+}
+
+// Next returns the status a submission in status from moves to when actor
+// takes action.
+func Next(from db.SubmissionStatusT, action Action, actor Actor) (db.SubmissionStatusT, error) {
+	t, ok := transitions[action]
+	if !ok {
+		return "", fmt.Errorf("%w %q", ErrUnknownAction, action)
+	}
+	if t.actor != actor {
+		return "", fmt.Errorf("%w: only the %s can %s a submission", ErrNotAllowed, t.actor, action)
+	}
+	if from != t.from {
+		return "", fmt.Errorf("%w: can't %s a %s submission", ErrInvalidTransition, action, from)
+	}
+	return t.to, nil
+}
+
+func (a Actor) String() string {
+	if a == Reviewer {
+		return "reviewer"
+	}
+	return "author"
+}
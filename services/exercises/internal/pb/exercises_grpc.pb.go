@@ -0,0 +1,253 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v4.25.3
+// source: exercises.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Exercises_GetExercises_FullMethodName       = "/exercises.v1.Exercises/GetExercises"
+	Exercises_GetProgram_FullMethodName         = "/exercises.v1.Exercises/GetProgram"
+	Exercises_GetCompleteProgram_FullMethodName = "/exercises.v1.Exercises/GetCompleteProgram"
+	Exercises_PostProgram_FullMethodName        = "/exercises.v1.Exercises/PostProgram"
+)
+
+// ExercisesClient is the client API for Exercises service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Exercises serves the exercise catalog and programs to other services. It
+// mirrors the HTTP endpoints of the same names. The calling user, if any, is
+// sent in the x-user-id metadata.
+type ExercisesClient interface {
+	// GetExercises lists the exercises matching every given filter.
+	GetExercises(ctx context.Context, in *GetExercisesRequest, opts ...grpc.CallOption) (*GetExercisesResponse, error)
+	// GetProgram returns the items of a program.
+	GetProgram(ctx context.Context, in *GetProgramRequest, opts ...grpc.CallOption) (*Program, error)
+	// GetCompleteProgram returns a program with the details of its exercises
+	// and the estimated duration of its sessions.
+	GetCompleteProgram(ctx context.Context, in *GetProgramRequest, opts ...grpc.CallOption) (*CompleteProgram, error)
+	// PostProgram creates a program, owned by the calling user if any.
+	PostProgram(ctx context.Context, in *PostProgramRequest, opts ...grpc.CallOption) (*PostProgramResponse, error)
+}
+
+type exercisesClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewExercisesClient(cc grpc.ClientConnInterface) ExercisesClient {
+	return &exercisesClient{cc}
+}
+
+func (c *exercisesClient) GetExercises(ctx context.Context, in *GetExercisesRequest, opts ...grpc.CallOption) (*GetExercisesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetExercisesResponse)
+	err := c.cc.Invoke(ctx, Exercises_GetExercises_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *exercisesClient) GetProgram(ctx context.Context, in *GetProgramRequest, opts ...grpc.CallOption) (*Program, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Program)
+	err := c.cc.Invoke(ctx, Exercises_GetProgram_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *exercisesClient) GetCompleteProgram(ctx context.Context, in *GetProgramRequest, opts ...grpc.CallOption) (*CompleteProgram, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CompleteProgram)
+	err := c.cc.Invoke(ctx, Exercises_GetCompleteProgram_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *exercisesClient) PostProgram(ctx context.Context, in *PostProgramRequest, opts ...grpc.CallOption) (*PostProgramResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PostProgramResponse)
+	err := c.cc.Invoke(ctx, Exercises_PostProgram_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ExercisesServer is the server API for Exercises service.
+// All implementations must embed UnimplementedExercisesServer
+// for forward compatibility.
+//
+// Exercises serves the exercise catalog and programs to other services. It
+// mirrors the HTTP endpoints of the same names. The calling user, if any, is
+// sent in the x-user-id metadata.
+type ExercisesServer interface {
+	// GetExercises lists the exercises matching every given filter.
+	GetExercises(context.Context, *GetExercisesRequest) (*GetExercisesResponse, error)
+	// GetProgram returns the items of a program.
+	GetProgram(context.Context, *GetProgramRequest) (*Program, error)
+	// GetCompleteProgram returns a program with the details of its exercises
+	// and the estimated duration of its sessions.
+	GetCompleteProgram(context.Context, *GetProgramRequest) (*CompleteProgram, error)
+	// PostProgram creates a program, owned by the calling user if any.
+	PostProgram(context.Context, *PostProgramRequest) (*PostProgramResponse, error)
+	mustEmbedUnimplementedExercisesServer()
+}
+
+// UnimplementedExercisesServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedExercisesServer struct{}
+
+func (UnimplementedExercisesServer) GetExercises(context.Context, *GetExercisesRequest) (*GetExercisesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetExercises not implemented")
+}
+func (UnimplementedExercisesServer) GetProgram(context.Context, *GetProgramRequest) (*Program, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProgram not implemented")
+}
+func (UnimplementedExercisesServer) GetCompleteProgram(context.Context, *GetProgramRequest) (*CompleteProgram, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCompleteProgram not implemented")
+}
+func (UnimplementedExercisesServer) PostProgram(context.Context, *PostProgramRequest) (*PostProgramResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PostProgram not implemented")
+}
+func (UnimplementedExercisesServer) mustEmbedUnimplementedExercisesServer() {}
+func (UnimplementedExercisesServer) testEmbeddedByValue()                   {}
+
+// UnsafeExercisesServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ExercisesServer will
+// result in compilation errors.
+type UnsafeExercisesServer interface {
+	mustEmbedUnimplementedExercisesServer()
+}
+
+func RegisterExercisesServer(s grpc.ServiceRegistrar, srv ExercisesServer) {
+	// If the following call pancis, it indicates UnimplementedExercisesServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Exercises_ServiceDesc, srv)
+}
+
+func _Exercises_GetExercises_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetExercisesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExercisesServer).GetExercises(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Exercises_GetExercises_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExercisesServer).GetExercises(ctx, req.(*GetExercisesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Exercises_GetProgram_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProgramRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExercisesServer).GetProgram(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Exercises_GetProgram_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExercisesServer).GetProgram(ctx, req.(*GetProgramRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Exercises_GetCompleteProgram_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProgramRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExercisesServer).GetCompleteProgram(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Exercises_GetCompleteProgram_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExercisesServer).GetCompleteProgram(ctx, req.(*GetProgramRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Exercises_PostProgram_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PostProgramRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExercisesServer).PostProgram(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Exercises_PostProgram_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExercisesServer).PostProgram(ctx, req.(*PostProgramRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Exercises_ServiceDesc is the grpc.ServiceDesc for Exercises service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Exercises_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "exercises.v1.Exercises",
+	HandlerType: (*ExercisesServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetExercises",
+			Handler:    _Exercises_GetExercises_Handler,
+		},
+		{
+			MethodName: "GetProgram",
+			Handler:    _Exercises_GetProgram_Handler,
+		},
+		{
+			MethodName: "GetCompleteProgram",
+			Handler:    _Exercises_GetCompleteProgram_Handler,
+		},
+		{
+			MethodName: "PostProgram",
+			Handler:    _Exercises_PostProgram_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "exercises.proto",
+}
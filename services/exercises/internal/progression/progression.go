@@ -0,0 +1,286 @@
+package progression
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/analytics"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/models"
+)
+
+// Rule is a strategy to derive the next prescription from the last one and
+// what was actually performed.
+type Rule string
+
+const (
+	// Linear adds a fixed increment once every planned set was completed.
+	Linear Rule = "linear"
+	// Double climbs reps within a range at the same weight, then adds the
+	// increment and starts again at the bottom of the range.
+	Double Rule = "double"
+	// RPE derives the weight from the estimated 1RM of the logged sets and
+	// their RPE, so the next session lands on the target effort.
+	RPE Rule = "rpe"
+)
+
+// Config tunes a rule. Zero values fall back to the defaults below or to the
+// prescription of the item.
+type Config struct {
+	Rule        Rule
+	IncrementKg float64
+	RoundingKg  float64
+	MinReps     int
+	MaxReps     int
+	TargetRPE   float64
+}
+
+const (
+	defaultIncrementKg = 2.5
+	defaultRoundingKg  = 2.5
+	defaultRepRange    = 4
+	defaultTargetRPE   = 8
+)
+
+// ParseConfig reads a Config from query parameters: rule, increment,
+// rounding, minReps, maxReps and rpe.
+func ParseConfig(query url.Values) (Config, error) {
+	cfg := Config{
+		Rule:        Linear,
+		IncrementKg: defaultIncrementKg,
+		RoundingKg:  defaultRoundingKg,
+		TargetRPE:   defaultTargetRPE,
+	}
+
+	switch rule := Rule(query.Get("rule")); rule {
+	case "":
+	case Linear, Double, RPE:
+		cfg.Rule = rule
+	default:
+		return cfg, fmt.Errorf("unknown rule %q, expected linear, double or rpe", rule)
+	}
+
+	floats := map[string]*float64{"increment": &cfg.IncrementKg, "rounding": &cfg.RoundingKg, "rpe": &cfg.TargetRPE}
+	for name, dst := range floats {
+		if v := query.Get(name); v != "" {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil || f <= 0 {
+				return cfg, fmt.Errorf("invalid %s %q", name, v)
+			}
+			*dst = f
+		}
+	}
+	ints := map[string]*int{"minReps": &cfg.MinReps, "maxReps": &cfg.MaxReps}
+	for name, dst := range ints {
+		if v := query.Get(name); v != "" {
+			i, err := strconv.Atoi(v)
+			if err != nil || i <= 0 {
+				return cfg, fmt.Errorf("invalid %s %q", name, v)
+			}
+			*dst = i
+		}
+	}
+
+	if cfg.TargetRPE > 10 {
+		return cfg, fmt.Errorf("invalid rpe %v", cfg.TargetRPE)
+	}
+	if cfg.MinReps > 0 && cfg.MaxReps > 0 && cfg.MinReps > cfg.MaxReps {
+		return cfg, fmt.Errorf("minReps can't be above maxReps")
+	}
+	return cfg, nil
+}
+
+// Propose derives the next prescription of a program item from the sets
+// performed for it in the last workout, in the order they were performed.
+func Propose(cfg Config, item models.ProgramRecord, performed []models.WorkoutSet) models.ProgressionItem {
+	current := item.SetList()
+	proposal := models.ProgressionItem{
+		Idx:        item.Idx,
+		ExerciseId: item.ExerciseId,
+		Current:    current,
+	}
+
+	if len(performed) == 0 {
+		proposal.Proposed = current
+		proposal.Reason = "no logged sets, repeating the prescription"
+		return proposal
+	}
+
+	switch cfg.Rule {
+	case Double:
+		proposal.Proposed, proposal.Reason = double(cfg, current, performed)
+	case RPE:
+		proposal.Proposed, proposal.Reason = rpeBased(cfg, current, performed)
+	default:
+		proposal.Proposed, proposal.Reason = linear(cfg, current, performed)
+	}
+	return proposal
+}
+
+func linear(cfg Config, current []models.SetPrescription, performed []models.WorkoutSet) ([]models.SetPrescription, string) {
+	proposed := make([]models.SetPrescription, len(current))
+	completed := allCompleted(current, performed)
+
+	for i, set := range current {
+		next := set
+		weight, ok := baseWeight(set, performed, i)
+		switch {
+		case !ok && completed && set.Reps != nil:
+			// Nothing to load, progress on reps instead
+			next.Reps = intPtr(*set.Reps + 1)
+		case ok && completed:
+			next = withWeight(next, round(weight+cfg.IncrementKg, cfg.RoundingKg))
+		case ok:
+			next = withWeight(next, weight)
+		}
+		proposed[i] = next
+	}
+
+	if !completed {
+		return proposed, "not every set was completed, repeating the load"
+	}
+	return proposed, fmt.Sprintf("all sets completed, adding %gkg", cfg.IncrementKg)
+}
+
+func double(cfg Config, current []models.SetPrescription, performed []models.WorkoutSet) ([]models.SetPrescription, string) {
+	minReps, maxReps := cfg.MinReps, cfg.MaxReps
+	if minReps == 0 {
+		minReps = 1
+		if len(current) > 0 && current[0].Reps != nil {
+			minReps = *current[0].Reps
+		}
+	}
+	if maxReps == 0 || maxReps < minReps {
+		maxReps = minReps + defaultRepRange
+	}
+
+	lowest := math.MaxInt
+	for i := range current {
+		reps := 0
+		if i < len(performed) && performed[i].Reps != nil {
+			reps = *performed[i].Reps
+		}
+		lowest = min(lowest, reps)
+	}
+
+	proposed := make([]models.SetPrescription, len(current))
+	topped := lowest >= maxReps
+	for i, set := range current {
+		next := set
+		weight, ok := baseWeight(set, performed, i)
+		if topped {
+			next.Reps = intPtr(minReps)
+			if ok {
+				next = withWeight(next, round(weight+cfg.IncrementKg, cfg.RoundingKg))
+			}
+		} else {
+			next.Reps = intPtr(min(maxReps, max(minReps, lowest+1)))
+			if ok {
+				next = withWeight(next, weight)
+			}
+		}
+		proposed[i] = next
+	}
+
+	if topped {
+		return proposed, fmt.Sprintf("every set reached %d reps, adding %gkg and going back to %d reps", maxReps, cfg.IncrementKg, minReps)
+	}
+	return proposed, fmt.Sprintf("climbing the %d-%d rep range at the same weight", minReps, maxReps)
+}
+
+func rpeBased(cfg Config, current []models.SetPrescription, performed []models.WorkoutSet) ([]models.SetPrescription, string) {
+	// Reps in reserve are reps that could have been done, so they count
+	// towards the estimate. Half-step RPEs leave half a rep.
+	var e1rm float64
+	for _, set := range performed {
+		if set.Reps == nil || set.WeightKg == nil || set.RPE == nil {
+			continue
+		}
+		if estimate, ok := analytics.Epley.EstimateWithReserve(*set.WeightKg, *set.Reps, 10-*set.RPE); ok {
+			e1rm = max(e1rm, estimate)
+		}
+	}
+	if e1rm == 0 {
+		proposed, reason := linear(cfg, current, performed)
+		return proposed, "no sets logged with weight and RPE, " + reason
+	}
+
+	// The target effort is the prescribed RPE or RIR, RPE being 10 - RIR,
+	// and is kept in the form it was prescribed in.
+	proposed := make([]models.SetPrescription, len(current))
+	kept := 0
+	for i, set := range current {
+		next := set
+		targetRPE := cfg.TargetRPE
+		switch {
+		case set.RPE != nil:
+			targetRPE = *set.RPE
+		case set.RIR != nil:
+			targetRPE = float64(10 - *set.RIR)
+		default:
+			next.RPE = floatPtr(targetRPE)
+		}
+		if set.Reps != nil {
+			if weight, ok := analytics.Epley.Weight(e1rm, *set.Reps, 10-targetRPE); ok {
+				next = withWeight(next, round(weight, cfg.RoundingKg))
+			} else {
+				kept++
+			}
+		}
+		proposed[i] = next
+	}
+
+	reason := fmt.Sprintf("estimated 1RM of %.1fkg from the logged RPE", e1rm)
+	if kept > 0 {
+		reason += fmt.Sprintf(", %d of %d sets keep their weight as their reps and reserve are above %d", kept, len(current), analytics.MaxEstimateReps)
+	}
+	return proposed, reason
+}
+
+// allCompleted reports whether every planned set has a logged counterpart
+// that met its reps, duration or distance.
+func allCompleted(current []models.SetPrescription, performed []models.WorkoutSet) bool {
+	if len(performed) < len(current) {
+		return false
+	}
+	for i, set := range current {
+		done := performed[i]
+		switch {
+		case set.Reps != nil && (done.Reps == nil || *done.Reps < *set.Reps):
+			return false
+		case set.DurationSeconds != nil && (done.DurationSeconds == nil || *done.DurationSeconds < *set.DurationSeconds):
+			return false
+		case set.DistanceMeters != nil && (done.DistanceMeters == nil || *done.DistanceMeters < *set.DistanceMeters):
+			return false
+		}
+	}
+	return true
+}
+
+// baseWeight is the weight the next set builds on: the one actually used for
+// the matching logged set, or the prescribed one.
+func baseWeight(set models.SetPrescription, performed []models.WorkoutSet, i int) (float64, bool) {
+	if i < len(performed) && performed[i].WeightKg != nil {
+		return *performed[i].WeightKg, true
+	}
+	if set.WeightKg != nil {
+		return *set.WeightKg, true
+	}
+	return 0, false
+}
+
+// withWeight sets an absolute load, which replaces a load relative to a 1RM.
+func withWeight(set models.SetPrescription, weight float64) models.SetPrescription {
+	set.WeightKg = floatPtr(weight)
+	set.OneRmPercent = nil
+	return set
+}
+
+func round(weight, step float64) float64 {
+	return math.Round(weight/step) * step
+}
+
+func intPtr(i int) *int { return &i }
+
+func floatPtr(f float64) *float64 { return &f }
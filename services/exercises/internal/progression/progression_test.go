@@ -0,0 +1,50 @@
+package progression
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/models"
+)
+
+func TestRPEBased(t *testing.T) {
+	cfg := Config{Rule: RPE, RoundingKg: 0.5, TargetRPE: 8}
+	logged := func(weight float64, reps int, rpe float64) []models.WorkoutSet {
+		return []models.WorkoutSet{{Reps: &reps, WeightKg: &weight, RPE: &rpe}}
+	}
+
+	tests := []struct {
+		name      string
+		set       models.SetPrescription
+		performed []models.WorkoutSet
+		weight    float64
+		kept      bool
+	}{
+		// 100kg x 5 @ 8 estimates a 1RM of 123.3kg
+		{name: "same target", set: models.SetPrescription{Reps: intPtr(5), RPE: floatPtr(8)}, performed: logged(100, 5, 8), weight: 100},
+		{name: "half-step target", set: models.SetPrescription{Reps: intPtr(5), RPE: floatPtr(7.5)}, performed: logged(100, 5, 8), weight: 98.5},
+		{name: "rir target", set: models.SetPrescription{Reps: intPtr(5), RIR: intPtr(2)}, performed: logged(100, 5, 8), weight: 100},
+		{name: "default target", set: models.SetPrescription{Reps: intPtr(3)}, performed: logged(100, 5, 8), weight: 105.5},
+		// 100kg x 5 @ 8.5 leaves 1.5 reps, a 1RM of 121.7kg
+		{name: "half-step logged", set: models.SetPrescription{Reps: intPtr(5), RPE: floatPtr(8)}, performed: logged(100, 5, 8.5), weight: 98.5},
+		{name: "past the estimate range", set: models.SetPrescription{Reps: intPtr(10), RIR: intPtr(3), WeightKg: floatPtr(60)}, performed: logged(100, 5, 8), weight: 60, kept: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := models.ProgramRecord{Idx: 1, ExerciseId: 1, Prescriptions: []models.SetPrescription{tt.set}}
+			got := Propose(cfg, item, tt.performed)
+
+			next := got.Proposed[0]
+			if next.WeightKg == nil || *next.WeightKg != tt.weight {
+				t.Errorf("proposed = %+v, want %vkg", next, tt.weight)
+			}
+			if (tt.set.RIR != nil) != (next.RIR != nil) || (tt.set.RIR != nil && next.RPE != nil) {
+				t.Errorf("rpe = %v, rir = %v, want the target kept in the form of %+v", next.RPE, next.RIR, tt.set)
+			}
+			if kept := strings.Contains(got.Reason, "keep their weight"); kept != tt.kept {
+				t.Errorf("reason = %q, want kept sets reported: %v", got.Reason, tt.kept)
+			}
+		})
+	}
+}
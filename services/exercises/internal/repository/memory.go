@@ -0,0 +1,363 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/auth"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/models"
+)
+
+// Memory keeps exercises and programs in maps, for tests. It implements
+// both Exercises and Programs the way the queries do, except that custom
+// exercises of coaches aren't visible to their clients.
+type Memory struct {
+	mu          sync.Mutex
+	exercises   map[int32]memoryExercise
+	favorites   map[int64][]int32
+	collections map[[16]byte]*memoryCollection
+	programs    map[[16]byte]*memoryProgram
+}
+
+type memoryExercise struct {
+	exercise models.Exercise
+	category db.NullCategoryT
+}
+
+type memoryCollection struct {
+	collection db.ExerciseCollection
+	exercises  []int32
+}
+
+type memoryProgram struct {
+	access      db.ProgramAccess
+	records     []models.ProgramRecord
+	shareTokens []string
+	clients     []int64
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		exercises:   make(map[int32]memoryExercise),
+		favorites:   make(map[int64][]int32),
+		collections: make(map[[16]byte]*memoryCollection),
+		programs:    make(map[[16]byte]*memoryProgram),
+	}
+}
+
+// AddExercise stores an exercise, replacing the one with the same ID.
+func (m *Memory) AddExercise(exercise models.Exercise, category db.NullCategoryT) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.exercises[exercise.Id] = memoryExercise{exercise: exercise, category: category}
+}
+
+func (m *Memory) AddFavorite(userID int64, exerciseID int32) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.favorites[userID] = append(m.favorites[userID], exerciseID)
+}
+
+// AddCollection stores an empty collection, replacing the one with the
+// same ID.
+func (m *Memory) AddCollection(collection db.ExerciseCollection) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.collections[collection.ID.Bytes] = &memoryCollection{collection: collection}
+}
+
+func (m *Memory) AddToCollection(collectionID pgtype.UUID, exerciseID int32) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if c, ok := m.collections[collectionID.Bytes]; ok {
+		c.exercises = append(c.exercises, exerciseID)
+	}
+}
+
+// AddProgram stores a program with the given access row, replacing the one
+// with the same ID.
+func (m *Memory) AddProgram(access db.ProgramAccess, records []models.ProgramRecord) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.programs[access.ProgramID.Bytes] = &memoryProgram{access: access, records: normalized(records)}
+}
+
+func (m *Memory) AddShareToken(programID pgtype.UUID, token string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if p, ok := m.programs[programID.Bytes]; ok {
+		p.shareTokens = append(p.shareTokens, token)
+	}
+}
+
+func (m *Memory) AssignProgram(programID pgtype.UUID, clientID int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if p, ok := m.programs[programID.Bytes]; ok {
+		p.clients = append(p.clients, clientID)
+	}
+}
+
+func (m *Memory) GetExercises(ctx context.Context, params db.GetExercisesParams) ([]models.Exercise, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ids := make([]int32, 0, len(m.exercises))
+	for id := range m.exercises {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	exercises := make([]models.Exercise, 0)
+	for _, id := range ids {
+		ex := m.exercises[id].exercise
+		match, err := m.matches(ex, params)
+		if err != nil {
+			return nil, err
+		}
+		if match {
+			exercises = append(exercises, ex)
+		}
+	}
+
+	offset, limit := 0, 50
+	if v, ok := intArg(params.Offset); ok {
+		offset = v
+	}
+	if v, ok := intArg(params.Limit); ok {
+		limit = v
+	}
+	start := min(offset, len(exercises))
+	end := min(start+limit, len(exercises))
+	return exercises[start:end], nil
+}
+
+// matches reports whether ex passes the filters of params, which are set
+// the way sqlc.narg arguments are: nil or unset matches everything.
+func (m *Memory) matches(ex models.Exercise, params db.GetExercisesParams) (bool, error) {
+	if !visible(ex.OwnerID, params.ViewerID) {
+		return false, nil
+	}
+	if name, ok := textArg(params.Name); ok {
+		found := slices.ContainsFunc(ex.Names, func(n string) bool {
+			return strings.Contains(strings.ToLower(n), strings.ToLower(name))
+		})
+		if !found {
+			return false, nil
+		}
+	}
+	if equipment, ok := textArg(params.Equipment); ok && string(ex.Equipment) != equipment {
+		return false, nil
+	}
+	if muscle, ok := textArg(params.Muscle); ok && !slices.Contains(ex.Muscles, muscle) {
+		return false, nil
+	}
+	if id, ok := textArg(params.ExerciseID); ok {
+		n, err := strconv.Atoi(id)
+		if err != nil {
+			return false, fmt.Errorf("invalid exercise_id %q", id)
+		}
+		if ex.Id != int32(n) {
+			return false, nil
+		}
+	}
+	if collection, ok := params.CollectionID.(pgtype.UUID); ok && collection.Valid {
+		c, ok := m.collections[collection.Bytes]
+		if !ok || !slices.Contains(c.exercises, ex.Id) {
+			return false, nil
+		}
+	}
+	if userID, ok := params.FavoritesOf.(int64); ok {
+		if !slices.Contains(m.favorites[userID], ex.Id) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (m *Memory) GetExerciseCategories(ctx context.Context, ids []int32, viewerID pgtype.Int8) (map[int32]db.NullCategoryT, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	categories := make(map[int32]db.NullCategoryT, len(ids))
+	for _, id := range ids {
+		ex, ok := m.exercises[id]
+		if ok && visible(ex.exercise.OwnerID, viewerID) {
+			categories[id] = ex.category
+		}
+	}
+	return categories, nil
+}
+
+func (m *Memory) GetExerciseCollection(ctx context.Context, collectionID pgtype.UUID, userID int64) (db.ExerciseCollection, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, ok := m.collections[collectionID.Bytes]
+	if !ok || c.collection.UserID != userID {
+		return db.ExerciseCollection{}, pgx.ErrNoRows
+	}
+	return c.collection, nil
+}
+
+func (m *Memory) GetProgramAccess(ctx context.Context, programID pgtype.UUID) (db.ProgramAccess, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, ok := m.programs[programID.Bytes]
+	if !ok {
+		return db.ProgramAccess{}, pgx.ErrNoRows
+	}
+	return p.access, nil
+}
+
+func (m *Memory) HasShareToken(ctx context.Context, programID pgtype.UUID, token string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, ok := m.programs[programID.Bytes]
+	return ok && slices.Contains(p.shareTokens, token), nil
+}
+
+func (m *Memory) IsProgramAssigned(ctx context.Context, programID pgtype.UUID, clientID int64) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, ok := m.programs[programID.Bytes]
+	return ok && slices.Contains(p.clients, clientID), nil
+}
+
+func (m *Memory) GetUserPrograms(ctx context.Context, ownerID int64) ([]db.ProgramAccess, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	programs := make([]db.ProgramAccess, 0)
+	for _, p := range m.programs {
+		if p.access.OwnerID.Valid && p.access.OwnerID.Int64 == ownerID {
+			programs = append(programs, p.access)
+		}
+	}
+	slices.SortFunc(programs, func(a, b db.ProgramAccess) int {
+		return b.CreatedAt.Time.Compare(a.CreatedAt.Time)
+	})
+	return programs, nil
+}
+
+func (m *Memory) GetUserProgramItems(ctx context.Context, ownerID int64) (map[[16]byte]*models.Program, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	programs := make(map[[16]byte]*models.Program)
+	for id, p := range m.programs {
+		if p.access.OwnerID.Valid && p.access.OwnerID.Int64 == ownerID && len(p.records) > 0 {
+			programs[id] = &models.Program{UUID: id, Exercises: slices.Clone(p.records)}
+		}
+	}
+	return programs, nil
+}
+
+func (m *Memory) GetProgram(ctx context.Context, programID pgtype.UUID) (*models.Program, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, ok := m.programs[programID.Bytes]
+	if !ok || len(p.records) == 0 {
+		return nil, pgx.ErrNoRows
+	}
+	return &models.Program{UUID: programID.Bytes, Exercises: slices.Clone(p.records)}, nil
+}
+
+func (m *Memory) GetCompleteProgram(ctx context.Context, programID pgtype.UUID) (*models.CompleteProgram, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	program := &models.CompleteProgram{UUID: programID.Bytes, Exercises: make([]models.ProgramExercise, 0)}
+	p, ok := m.programs[programID.Bytes]
+	if !ok {
+		return program, nil
+	}
+	for _, rec := range p.records {
+		ex, ok := m.exercises[int32(rec.ExerciseId)]
+		if !ok {
+			continue
+		}
+		program.Exercises = append(program.Exercises, models.ProgramExercise{
+			Exercise:      ex.exercise,
+			Idx:           rec.Idx,
+			Day:           rec.Day,
+			Sets:          rec.Sets,
+			Reps:          rec.Reps,
+			Prescriptions: rec.Prescriptions,
+		})
+	}
+	return program, nil
+}
+
+func (m *Memory) CreateProgram(ctx context.Context, programID pgtype.UUID, records []models.ProgramRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.programs[programID.Bytes]; ok {
+		return fmt.Errorf("program %x already exists", programID.Bytes)
+	}
+
+	now := pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	access := db.ProgramAccess{
+		ProgramID:  programID,
+		Visibility: db.VisibilityTPublic,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if userID, ok := auth.UserID(ctx); ok {
+		access.OwnerID = pgtype.Int8{Int64: userID, Valid: true}
+		access.Visibility = db.VisibilityTPrivate
+	}
+	m.programs[programID.Bytes] = &memoryProgram{access: access, records: normalized(records)}
+	return nil
+}
+
+// normalized returns the records normalized and ordered by idx, as they
+// come out of the database.
+func normalized(records []models.ProgramRecord) []models.ProgramRecord {
+	out := make([]models.ProgramRecord, 0, len(records))
+	for _, rec := range records {
+		rec.Normalize()
+		out = append(out, rec)
+	}
+	slices.SortFunc(out, func(a, b models.ProgramRecord) int { return a.Idx - b.Idx })
+	return out
+}
+
+// visible reports whether an exercise with the given owner is in the
+// catalog of the viewer.
+func visible(ownerID *int64, viewerID pgtype.Int8) bool {
+	return ownerID == nil || (viewerID.Valid && *ownerID == viewerID.Int64)
+}
+
+func textArg(v any) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case *string:
+		if v != nil {
+			return *v, true
+		}
+	case db.EquipmentT:
+		return string(v), true
+	case int32:
+		return strconv.Itoa(int(v)), true
+	}
+	return "", false
+}
+
+func intArg(v any) (int, bool) {
+	switch v := v.(type) {
+	case int:
+		return v, true
+	case int32:
+		return int(v), true
+	case int64:
+		return int(v), true
+	}
+	return 0, false
+}
@@ -0,0 +1,272 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/auth"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/events"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/models"
+)
+
+// Postgres stores exercises and programs in the database through the sqlc
+// queries. It implements both Exercises and Programs.
+type Postgres struct {
+	pool *pgxpool.Pool
+	q    *db.Queries
+}
+
+func NewPostgres(pool *pgxpool.Pool, q *db.Queries) *Postgres {
+	return &Postgres{pool: pool, q: q}
+}
+
+func (p *Postgres) GetExercises(ctx context.Context, params db.GetExercisesParams) ([]models.Exercise, error) {
+	rows, err := p.q.GetExercises(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return *models.ExerciseFromRows(rows), nil
+}
+
+func (p *Postgres) GetExerciseCategories(ctx context.Context, ids []int32, viewerID pgtype.Int8) (map[int32]db.NullCategoryT, error) {
+	rows, err := p.q.GetExerciseCategories(ctx, db.GetExerciseCategoriesParams{ExerciseIds: ids, ViewerID: viewerID})
+	if err != nil {
+		return nil, err
+	}
+	categories := make(map[int32]db.NullCategoryT, len(rows))
+	for _, row := range rows {
+		categories[row.ID] = row.Category
+	}
+	return categories, nil
+}
+
+func (p *Postgres) GetExerciseCollection(ctx context.Context, collectionID pgtype.UUID, userID int64) (db.ExerciseCollection, error) {
+	return p.q.GetExerciseCollection(ctx, db.GetExerciseCollectionParams{ID: collectionID, UserID: userID})
+}
+
+func (p *Postgres) GetProgramAccess(ctx context.Context, programID pgtype.UUID) (db.ProgramAccess, error) {
+	return p.q.GetProgramAccess(ctx, programID)
+}
+
+func (p *Postgres) HasShareToken(ctx context.Context, programID pgtype.UUID, token string) (bool, error) {
+	return p.q.HasShareToken(ctx, db.HasShareTokenParams{ProgramID: programID, Token: token})
+}
+
+func (p *Postgres) IsProgramAssigned(ctx context.Context, programID pgtype.UUID, clientID int64) (bool, error) {
+	return p.q.IsProgramAssigned(ctx, db.IsProgramAssignedParams{ProgramID: programID, ClientID: clientID})
+}
+
+func (p *Postgres) GetUserPrograms(ctx context.Context, ownerID int64) ([]db.ProgramAccess, error) {
+	return p.q.GetUserPrograms(ctx, ownerID)
+}
+
+func (p *Postgres) GetUserProgramItems(ctx context.Context, ownerID int64) (map[[16]byte]*models.Program, error) {
+	return FetchUserPrograms(ctx, p.q, ownerID)
+}
+
+func (p *Postgres) GetProgram(ctx context.Context, programID pgtype.UUID) (*models.Program, error) {
+	return FetchProgram(ctx, p.q, programID)
+}
+
+func (p *Postgres) GetCompleteProgram(ctx context.Context, programID pgtype.UUID) (*models.CompleteProgram, error) {
+	return FetchCompleteProgram(ctx, p.q, programID)
+}
+
+func (p *Postgres) CreateProgram(ctx context.Context, programID pgtype.UUID, records []models.ProgramRecord) error {
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("couldn't start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	qtx := p.q.WithTx(tx)
+
+	if err := InsertProgramRecords(ctx, qtx, programID, records); err != nil {
+		return fmt.Errorf("couldn't insert program items: %w", err)
+	}
+	if err := InsertProgramAccess(ctx, qtx, programID, pgtype.UUID{}); err != nil {
+		return fmt.Errorf("couldn't insert program access: %w", err)
+	}
+	if _, err := RecordRevision(ctx, qtx, programID, db.RevisionSourceTCreated, pgtype.Int4{}); err != nil {
+		return fmt.Errorf("couldn't record program revision: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("couldn't commit program: %w", err)
+	}
+	return nil
+}
+
+// InsertProgramRecords writes the items of a program and their set
+// prescriptions using q, which is expected to run inside a transaction.
+func InsertProgramRecords(ctx context.Context, q *db.Queries, programID pgtype.UUID, records []models.ProgramRecord) error {
+	for _, rec := range records {
+		rec.Normalize()
+		err := q.InsertToProgramsById(ctx, db.InsertToProgramsByIdParams{
+			ID:         programID,
+			Idx:        int32(rec.Idx),
+			Day:        int32(rec.Day),
+			ExerciseID: int32(rec.ExerciseId),
+			Sets:       int32(rec.Sets),
+			Reps:       int32(rec.Reps),
+		})
+		if err != nil {
+			return err
+		}
+		for n, set := range rec.Prescriptions {
+			if err := q.InsertToProgramSets(ctx, set.InsertParams(programID, int32(rec.Idx), int32(n+1))); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// InsertProgramAccess records the caller as the owner of a new program,
+// which starts private. Programs created anonymously have no owner and are
+// public, as every program used to be.
+func InsertProgramAccess(ctx context.Context, q *db.Queries, programID, forkedFrom pgtype.UUID) error {
+	params := db.InsertProgramAccessParams{
+		ProgramID:  programID,
+		Visibility: db.VisibilityTPublic,
+		ForkedFrom: forkedFrom,
+	}
+	if userID, ok := auth.UserID(ctx); ok {
+		params.OwnerID = pgtype.Int8{Int64: userID, Valid: true}
+		params.Visibility = db.VisibilityTPrivate
+	}
+	_, err := q.InsertProgramAccess(ctx, params)
+	return err
+}
+
+// RecordRevision snapshots the current items of a program as its next
+// revision, crediting the caller, and records the matching program event.
+// q is expected to run inside the transaction that changed the program.
+func RecordRevision(ctx context.Context, q *db.Queries, programID pgtype.UUID, source db.RevisionSourceT, restoredFrom pgtype.Int4) (db.ProgramRevision, error) {
+	program, err := FetchProgram(ctx, q, programID)
+	if err != nil {
+		return db.ProgramRevision{}, err
+	}
+	items, err := json.Marshal(program.Exercises)
+	if err != nil {
+		return db.ProgramRevision{}, err
+	}
+
+	params := db.InsertProgramRevisionParams{
+		ProgramID:    programID,
+		Source:       source,
+		RestoredFrom: restoredFrom,
+		Items:        items,
+	}
+	if userID, ok := auth.UserID(ctx); ok {
+		params.CreatedBy = pgtype.Int8{Int64: userID, Valid: true}
+	}
+	revision, err := q.InsertProgramRevision(ctx, params)
+	if err != nil {
+		return revision, err
+	}
+	if err := q.TouchProgram(ctx, programID); err != nil {
+		return revision, err
+	}
+
+	eventType := events.ProgramUpdated
+	switch source {
+	case db.RevisionSourceTCreated, db.RevisionSourceTGenerated, db.RevisionSourceTImported, db.RevisionSourceTForked:
+		eventType = events.ProgramCreated
+	}
+	payload := events.ProgramPayload{
+		ProgramID: programID.Bytes,
+		Revision:  int(revision.Revision),
+		Source:    string(source),
+	}
+	if params.CreatedBy.Valid {
+		payload.UserID = &params.CreatedBy.Int64
+	}
+	return revision, events.Record(ctx, q, eventType, uuid.UUID(programID.Bytes).String(), payload)
+}
+
+// FetchProgram loads the items of a program with their set prescriptions. A
+// program without items doesn't exist.
+func FetchProgram(ctx context.Context, q *db.Queries, programID pgtype.UUID) (*models.Program, error) {
+	programRows, err := q.GetProgramById(ctx, programID)
+	if err != nil {
+		return nil, err
+	}
+	if len(programRows) == 0 {
+		return nil, pgx.ErrNoRows
+	}
+
+	setRows, err := q.GetProgramSetsById(ctx, programID)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.ProgramFromRows(programID.Bytes, programRows, setRows), nil
+}
+
+// FetchUserPrograms loads the items of every program the user owns in two
+// queries, keyed by program ID. Programs without items are left out.
+func FetchUserPrograms(ctx context.Context, q *db.Queries, ownerID int64) (map[[16]byte]*models.Program, error) {
+	itemRows, err := q.GetUserProgramItems(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	setRows, err := q.GetUserProgramSets(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	itemsByProgram := make(map[[16]byte][]db.GetProgramByIdRow)
+	for _, row := range itemRows {
+		itemsByProgram[row.ProgramID.Bytes] = append(itemsByProgram[row.ProgramID.Bytes], db.GetProgramByIdRow{
+			Idx:        row.Idx,
+			Day:        row.Day,
+			ExerciseID: row.ExerciseID,
+			Sets:       row.Sets,
+			Reps:       row.Reps,
+		})
+	}
+	setsByProgram := make(map[[16]byte][]db.GetProgramSetsByIdRow)
+	for _, row := range setRows {
+		setsByProgram[row.ProgramID.Bytes] = append(setsByProgram[row.ProgramID.Bytes], db.GetProgramSetsByIdRow{
+			Idx:             row.Idx,
+			SetNumber:       row.SetNumber,
+			Reps:            row.Reps,
+			WeightKg:        row.WeightKg,
+			OneRmPercent:    row.OneRmPercent,
+			Rpe:             row.Rpe,
+			Rir:             row.Rir,
+			Tempo:           row.Tempo,
+			RestSeconds:     row.RestSeconds,
+			DurationSeconds: row.DurationSeconds,
+			DistanceMeters:  row.DistanceMeters,
+		})
+	}
+
+	programs := make(map[[16]byte]*models.Program, len(itemsByProgram))
+	for id, rows := range itemsByProgram {
+		programs[id] = models.ProgramFromRows(id, rows, setsByProgram[id])
+	}
+	return programs, nil
+}
+
+// FetchCompleteProgram loads the items of a program along with their
+// exercises and set prescriptions.
+func FetchCompleteProgram(ctx context.Context, q *db.Queries, programID pgtype.UUID) (*models.CompleteProgram, error) {
+	programRows, err := q.GetFullProgramById(ctx, programID)
+	if err != nil {
+		return nil, err
+	}
+
+	setRows, err := q.GetProgramSetsById(ctx, programID)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.FullProgramFromRows(programID.Bytes, programRows, setRows), nil
+}
@@ -0,0 +1,49 @@
+// Package repository stores exercises and programs behind interfaces, so
+// the handlers can run against Postgres or against memory in tests.
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/models"
+)
+
+// Exercises reads the exercise catalog, including the custom exercises the
+// viewer can use.
+type Exercises interface {
+	// GetExercises returns the exercises matching params, ordered by ID.
+	GetExercises(ctx context.Context, params db.GetExercisesParams) ([]models.Exercise, error)
+	// GetExerciseCategories returns the category of each of the exercises
+	// the viewer can use, the others are left out.
+	GetExerciseCategories(ctx context.Context, ids []int32, viewerID pgtype.Int8) (map[int32]db.NullCategoryT, error)
+	// GetExerciseCollection returns a collection of the user, collections
+	// of other users are reported as pgx.ErrNoRows.
+	GetExerciseCollection(ctx context.Context, collectionID pgtype.UUID, userID int64) (db.ExerciseCollection, error)
+}
+
+// Programs stores programs and answers who can read them. Missing programs
+// are reported as pgx.ErrNoRows.
+type Programs interface {
+	GetProgramAccess(ctx context.Context, programID pgtype.UUID) (db.ProgramAccess, error)
+	HasShareToken(ctx context.Context, programID pgtype.UUID, token string) (bool, error)
+	IsProgramAssigned(ctx context.Context, programID pgtype.UUID, clientID int64) (bool, error)
+	// GetUserPrograms returns the access rows of the programs the user
+	// owns, newest first.
+	GetUserPrograms(ctx context.Context, ownerID int64) ([]db.ProgramAccess, error)
+	// GetUserProgramItems returns the items of every program the user owns,
+	// keyed by program ID. Programs without items are left out.
+	GetUserProgramItems(ctx context.Context, ownerID int64) (map[[16]byte]*models.Program, error)
+	// GetProgram returns the items of a program with their set
+	// prescriptions. A program without items doesn't exist.
+	GetProgram(ctx context.Context, programID pgtype.UUID) (*models.Program, error)
+	// GetCompleteProgram returns the items of a program along with their
+	// exercises and set prescriptions.
+	GetCompleteProgram(ctx context.Context, programID pgtype.UUID) (*models.CompleteProgram, error)
+	// CreateProgram stores a new program with its access row and first
+	// revision, owned by the user of ctx if any. The records must have
+	// been validated.
+	CreateProgram(ctx context.Context, programID pgtype.UUID, records []models.ProgramRecord) error
+}
@@ -0,0 +1,33 @@
+package router
+
+import (
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/auth"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+	service "github.com/Farzan-kh/guddy-cn/exercises/internal/handler"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/pb"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/repository"
+)
+
+// NewGRPCServer creates the gRPC server of the exercises service, serving
+// the Exercises service, the standard health service and reflection for
+// tools such as grpcurl. Callers authenticate with the bearer tokens of
+// the authn service at authnURL. The health server is returned to report
+// the shutdown.
+func NewGRPCServer(authnURL string) (*grpc.Server, *health.Server) {
+	validator := auth.NewTokenValidator(authnURL)
+	s := grpc.NewServer(grpc.UnaryInterceptor(validator.UnaryInterceptor))
+	store := repository.NewPostgres(db.GetPool(), db.Queriez)
+	pb.RegisterExercisesServer(s, service.NewExercisesServer(store, store))
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(pb.Exercises_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(s, healthServer)
+
+	reflection.Register(s)
+	return s, healthServer
+}
@@ -1,25 +1,163 @@
 package router
 
 import (
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/auth"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/config"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
 	service "github.com/Farzan-kh/guddy-cn/exercises/internal/handler"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/repository"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 )
 
-// NewRouter creates and configures the router for the exercises service
-func NewRouter() *chi.Mux {
+// NewRouter creates and configures the router for the exercises service,
+// leaving out the features turned off in cfg
+func NewRouter(cfg config.Config) *chi.Mux {
 	r := chi.NewRouter()
+	store := repository.NewPostgres(db.GetPool(), db.Queriez)
+	h := service.NewHandler(store, store)
 
 	// Middleware
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
+	r.Use(cors(cfg.CORS))
+	r.Use(auth.Middleware)
+
+	// Probes
+	r.Get("/healthz", service.Healthz)
+	r.Get("/readyz", service.Readyz)
 
 	// Routes
 	r.Route("/api", func(r chi.Router) {
-		r.Get("/exercises", service.GetExercises)
-		r.Get("/program/{uuid}", service.GetProgram)
-		r.Get("/completeProgram/{uuid}", service.GetCompleteProgram)
-		r.Post("/program", service.PostProgram)
+		r.Get("/exercises", h.GetExercises)
+		r.Get("/program/{uuid}", h.GetProgram)
+		r.Get("/completeProgram/{uuid}", h.GetCompleteProgram)
+		r.Post("/program", h.PostProgram)
+		r.Get("/program/{uuid}/export", h.ExportProgram)
+		r.Get("/program/{uuid}/analysis", h.GetProgramAnalysis)
+		r.Get("/program/{uuid}/revisions", h.GetProgramRevisions)
+		r.Get("/program/{uuid}/revisions/{revision}", h.GetProgramRevision)
+		r.Get("/program/{uuid}/diff", h.GetProgramDiff)
+		r.Post("/programs/generate", service.GenerateProgram)
+
+		// GraphQL, fields that need a user check for one themselves
+		if cfg.Features.GraphQL {
+			r.Get("/graphql", h.GraphQL)
+			r.Post("/graphql", h.GraphQL)
+		}
+
+		// The token in the path authenticates calendar apps
+		r.Get("/calendar/{token}.ics", h.GetCalendarICS)
+
+		// Workout logging and analytics, scoped to the authenticated user
+		r.Group(func(r chi.Router) {
+			r.Use(auth.RequireUser)
+			r.Post("/workouts", h.StartWorkout)
+			r.Get("/workouts", service.GetWorkouts)
+			r.Get("/workouts/{id}", service.GetWorkout)
+			r.Post("/workouts/{id}/sets", service.PostWorkoutSet)
+			r.Post("/workouts/{id}/finish", service.FinishWorkout)
+			if cfg.Features.LiveSessions {
+				r.Get("/workouts/{id}/live", service.GetLiveWorkout)
+			}
+
+			r.Get("/analytics/volume", service.GetWeeklyVolume)
+			r.Get("/analytics/e1rm/{exerciseId}", service.GetE1RMTrend)
+			r.Get("/analytics/records", service.GetPersonalRecords)
+
+			r.Put("/program/{uuid}", h.UpdateProgram)
+			r.Post("/program/{uuid}/revisions/{revision}/restore", h.RestoreProgramRevision)
+
+			r.Get("/program/{uuid}/next-session", h.GetNextSession)
+			r.Post("/program/{uuid}/next-session/accept", h.AcceptNextSession)
+
+			r.Get("/program/{uuid}/sharing", h.GetProgramSharing)
+			r.Put("/program/{uuid}/visibility", h.PutProgramVisibility)
+			r.Post("/program/{uuid}/share-links", h.PostShareLink)
+			r.Delete("/program/{uuid}/share-links/{token}", h.DeleteShareLink)
+			r.Post("/program/{uuid}/fork", h.ForkProgram)
+
+			r.Get("/programs", h.GetPrograms)
+			r.Post("/programs/import", service.ImportProgram)
+			r.Get("/programs/import/{id}", service.GetProgramImport)
+			r.Post("/programs/import/{id}/resolve", service.ResolveProgramImport)
+
+			r.Post("/schedules", h.PostSchedule)
+			r.Get("/schedules", service.GetSchedules)
+			r.Delete("/schedules/{id}", service.DeleteSchedule)
+			r.Get("/agenda", h.GetAgenda)
+			r.Post("/calendar/feed", service.PostCalendarFeed)
+			r.Get("/calendar/feed", service.GetCalendarFeed)
+
+			r.Get("/me/roles", service.GetMyRoles)
+			r.Post("/coach", service.BecomeCoach)
+			r.Post("/invitations/{code}/accept", service.AcceptCoachInvitation)
+			r.Get("/me/coaches", service.GetMyCoaches)
+			r.Put("/me/coaches/{coachId}/consent", service.PutCoachConsent)
+			r.Delete("/me/coaches/{coachId}", service.DeleteMyCoach)
+			r.Get("/me/assignments", service.GetMyAssignments)
+
+			r.Get("/exercises/custom", service.GetCustomExercises)
+			r.Post("/exercises/custom", service.PostCustomExercise)
+			r.Get("/exercises/custom/{id}", service.GetCustomExercise)
+			r.Put("/exercises/custom/{id}", service.PutCustomExercise)
+			r.Delete("/exercises/custom/{id}", service.DeleteCustomExercise)
+			r.Post("/exercises/custom/{id}/submissions", service.PostExerciseSubmission)
+			r.Get("/exercises/custom/{id}/submissions", service.GetExerciseSubmissions)
+			r.Get("/me/submissions", service.GetMySubmissions)
+			r.Get("/submissions/{id}", service.GetSubmission)
+			r.Post("/submissions/{id}/submit", service.SubmitSubmission)
+			r.Post("/submissions/{id}/withdraw", service.WithdrawSubmission)
+			r.Post("/submissions/{id}/comments", service.PostSubmissionComment)
+
+			r.Get("/me/favorites", service.GetFavorites)
+			r.Put("/me/favorites/{exerciseId}", service.PutFavorite)
+			r.Delete("/me/favorites/{exerciseId}", service.DeleteFavorite)
+			r.Get("/collections", service.GetCollections)
+			r.Post("/collections", service.PostCollection)
+			r.Get("/collections/{id}", service.GetCollection)
+			r.Put("/collections/{id}", service.PutCollection)
+			r.Delete("/collections/{id}", service.DeleteCollection)
+			r.Put("/collections/{id}/exercises/{exerciseId}", service.PutCollectionExercise)
+			r.Delete("/collections/{id}/exercises/{exerciseId}", service.DeleteCollectionExercise)
+
+			if cfg.Features.Webhooks {
+				r.Post("/webhooks", service.PostWebhook)
+				r.Get("/webhooks", service.GetWebhooks)
+				r.Delete("/webhooks/{id}", service.DeleteWebhook)
+				r.Get("/webhooks/{id}/deliveries", service.GetWebhookDeliveries)
+				r.Post("/webhooks/{id}/deliveries/{deliveryId}/replay", service.ReplayWebhookDelivery)
+				r.Post("/webhooks/{id}/replay", service.ReplayFailedWebhookDeliveries)
+			}
+
+			if cfg.Features.Sync {
+				r.Get("/sync", service.GetSync)
+				r.Post("/sync", h.PostSync)
+			}
+
+			// Coach endpoints, limited to users with the coach role
+			r.Group(func(r chi.Router) {
+				r.Use(service.RequireCoach)
+				r.Post("/coach/invitations", service.PostCoachInvitation)
+				r.Get("/coach/invitations", service.GetCoachInvitations)
+				r.Delete("/coach/invitations/{code}", service.DeleteCoachInvitation)
+				r.Get("/coach/clients", service.GetCoachClients)
+				r.Delete("/coach/clients/{clientId}", service.DeleteCoachClient)
+				r.Post("/coach/clients/{clientId}/assignments", h.PostAssignment)
+				r.Get("/coach/clients/{clientId}/assignments", service.GetClientAssignments)
+				r.Delete("/coach/clients/{clientId}/assignments/{id}", service.DeleteAssignment)
+				r.Get("/coach/clients/{clientId}/workouts", service.GetClientWorkouts)
+				r.Get("/coach/clients/{clientId}/workouts/{id}", service.GetClientWorkout)
+			})
+
+			// Admin endpoints, limited to users with the admin role
+			r.Group(func(r chi.Router) {
+				r.Use(service.RequireAdmin)
+				r.Get("/admin/submissions", service.GetSubmissionQueue)
+				r.Post("/admin/submissions/{id}/approve", service.ApproveSubmission)
+				r.Post("/admin/submissions/{id}/reject", service.RejectSubmission)
+			})
+		})
 	})
 
 	return r
@@ -0,0 +1,90 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrInternalAddress is returned for webhook URLs reaching the network of
+// the service, which users must not be able to probe through deliveries.
+var ErrInternalAddress = errors.New("url must not point at a loopback, private, link-local or cluster address")
+
+// sharedAddressSpace is the carrier-grade NAT range, which clusters use for
+// pods and services too.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// isInternal reports whether addr belongs to the local host or network.
+func isInternal(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() ||
+		sharedAddressSpace.Contains(addr)
+}
+
+// CheckURL checks rawURL is an absolute http(s) URL whose host resolves to
+// public addresses only. Deliveries check the address they dial again, as
+// the host may resolve differently by then.
+func CheckURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return errors.New("url must be an absolute http or https URL")
+	}
+
+	if addr, err := netip.ParseAddr(u.Hostname()); err == nil {
+		if isInternal(addr) {
+			return ErrInternalAddress
+		}
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", u.Hostname())
+	if err != nil || len(addrs) == 0 {
+		return fmt.Errorf("url host %s can't be resolved", u.Hostname())
+	}
+	for _, addr := range addrs {
+		if isInternal(addr) {
+			return ErrInternalAddress
+		}
+	}
+	return nil
+}
+
+// NewClient returns the client deliveries are posted with. It refuses to
+// connect to internal addresses and doesn't follow redirects, which would
+// lead it anywhere; a redirect fails the delivery like any other non-2xx
+// response.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if isInternal(addrPort.Addr()) {
+				return ErrInternalAddress
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			// No proxy, the dialed address has to be the one of the webhook
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,161 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+)
+
+// Sender posts the queued deliveries to their webhooks. A delivery succeeds
+// on any 2xx response; otherwise it is retried with exponential backoff, from
+// 30 seconds up to an hour apart, and marked failed after MaxAttempts. Every
+// attempt is recorded.
+type Sender struct {
+	Client      *http.Client
+	Interval    time.Duration
+	BatchSize   int32
+	MaxAttempts int32
+	// Lease is how long claimed deliveries are held for this sender, it
+	// has to cover sending a whole batch.
+	Lease time.Duration
+}
+
+func NewSender() *Sender {
+	return &Sender{
+		Client:      NewClient(10 * time.Second),
+		Interval:    5 * time.Second,
+		BatchSize:   20,
+		MaxAttempts: 8,
+		Lease:       5 * time.Minute,
+	}
+}
+
+// Run sends the due deliveries every Interval until ctx is done.
+func (s *Sender) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.Flush(ctx); err != nil {
+				log.Printf("Error at sending webhook deliveries: %v", err)
+			}
+		}
+	}
+}
+
+// Flush sends one batch of due deliveries and returns how many succeeded.
+// The batch is leased in one statement and sent outside of any transaction,
+// each outcome is recorded on its own as soon as it is known. Deliveries
+// left unsent when ctx is done are retried once their lease ends.
+func (s *Sender) Flush(ctx context.Context) (int, error) {
+	rows, err := db.Queriez.ClaimWebhookDeliveries(ctx, db.ClaimWebhookDeliveriesParams{
+		Limit:        s.BatchSize,
+		LeaseSeconds: int32(s.Lease.Seconds()),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	succeeded := 0
+	for _, row := range rows {
+		if ctx.Err() != nil {
+			// Shutting down, the rest are retried once their lease ends
+			break
+		}
+		started := time.Now()
+		status, sendErr := s.send(ctx, row)
+		if sendErr != nil && ctx.Err() != nil {
+			// Cut short by the shutdown, retried as well
+			break
+		}
+		if sendErr != nil {
+			log.Printf("Error at delivering webhook: %v, delivery: %s", sendErr, uuid.UUID(row.ID.Bytes))
+		}
+		// Recorded even while shutting down, so deliveries that went through
+		// aren't sent again
+		if err := s.record(context.WithoutCancel(ctx), row, status, sendErr, time.Since(started)); err != nil {
+			return succeeded, err
+		}
+		if sendErr == nil {
+			succeeded++
+		}
+	}
+	return succeeded, nil
+}
+
+// record stores the outcome of an attempt on the delivery along with the
+// attempt itself.
+func (s *Sender) record(ctx context.Context, row db.ClaimWebhookDeliveriesRow, status int, sendErr error, took time.Duration) error {
+	tx, err := db.GetPool().Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	qtx := db.Queriez.WithTx(tx)
+
+	attempt := db.InsertWebhookDeliveryAttemptParams{
+		DeliveryID:     row.ID,
+		Attempt:        row.Attempts + 1,
+		ResponseStatus: pgtype.Int4{Int32: int32(status), Valid: status != 0},
+		DurationMs:     int32(took.Milliseconds()),
+	}
+	if sendErr != nil {
+		attempt.Error = pgtype.Text{String: sendErr.Error(), Valid: true}
+		err = qtx.MarkWebhookDeliveryFailed(ctx, db.MarkWebhookDeliveryFailedParams{
+			MaxAttempts:    s.MaxAttempts,
+			ResponseStatus: attempt.ResponseStatus,
+			LastError:      sendErr.Error(),
+			ID:             row.ID,
+		})
+	} else {
+		err = qtx.MarkWebhookDeliverySucceeded(ctx, db.MarkWebhookDeliverySucceededParams{ResponseStatus: int32(status), ID: row.ID})
+	}
+	if err != nil {
+		return err
+	}
+	if err := qtx.InsertWebhookDeliveryAttempt(ctx, attempt); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// send posts a delivery and returns the status of the response, 0 when
+// there was none.
+func (s *Sender) send(ctx context.Context, row db.ClaimWebhookDeliveriesRow) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, row.Url, bytes.NewReader(row.Payload))
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "guddy-webhooks/1")
+	req.Header.Set(EventHeader, row.EventType)
+	req.Header.Set(DeliveryHeader, uuid.UUID(row.ID.Bytes).String())
+	req.Header.Set(TimestampHeader, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(SignatureHeader, Sign(row.Secret, now, row.Payload))
+
+	res, err := s.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+	return res.StatusCode, nil
+}
@@ -0,0 +1,77 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/events"
+)
+
+// Headers sent with every delivery. The signature is the hex HMAC-SHA256 of
+// the timestamp, a dot and the body, keyed with the secret of the webhook.
+const (
+	EventHeader     = "X-Guddy-Event"
+	DeliveryHeader  = "X-Guddy-Delivery"
+	TimestampHeader = "X-Guddy-Timestamp"
+	SignatureHeader = "X-Guddy-Signature"
+)
+
+// Sign returns the value of the signature header of a body sent at t.
+func Sign(secret string, t time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(t.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Broker queues a delivery of each event for the webhooks of the user it
+// concerns that subscribed to its type. Events handed over again by the
+// relay are dropped by the database.
+type Broker struct{}
+
+func (Broker) Publish(ctx context.Context, event events.Event) error {
+	var payload struct {
+		UserID *int64 `json:"userId"`
+	}
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return err
+	}
+	if payload.UserID == nil {
+		// Programs created anonymously
+		return nil
+	}
+
+	hooks, err := db.Queriez.GetWebhooksForEvent(ctx, db.GetWebhooksForEventParams{UserID: *payload.UserID, EventType: event.Type})
+	if err != nil {
+		return err
+	}
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	for _, hook := range hooks {
+		err := db.Queriez.InsertWebhookDelivery(ctx, db.InsertWebhookDeliveryParams{
+			WebhookID: hook.ID,
+			EventID:   pgtype.UUID{Bytes: event.ID, Valid: true},
+			EventType: event.Type,
+			Payload:   body,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
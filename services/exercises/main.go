@@ -1,25 +1,164 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
 
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/config"
 	"github.com/Farzan-kh/guddy-cn/exercises/internal/db"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/events"
+	service "github.com/Farzan-kh/guddy-cn/exercises/internal/handler"
 	"github.com/Farzan-kh/guddy-cn/exercises/internal/router"
+	"github.com/Farzan-kh/guddy-cn/exercises/internal/webhooks"
+	"github.com/go-chi/chi/v5/middleware"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
 )
 
 func main() {
-	logger, _ := zap.NewProduction()
+	configPath := flag.String("config", os.Getenv("EXERCISES_CONFIG"), "YAML config file, overridden by the environment")
+	printConfig := flag.Bool("print-config", false, "Print the resulting config, with secrets redacted, and exit")
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if *printConfig {
+		out, yamlErr := cfg.Redacted().YAML()
+		if yamlErr != nil {
+			log.Fatalf("Couldn't render config: %v", yamlErr)
+		}
+		fmt.Print(string(out))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid config:\n%v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if err != nil {
+		log.Fatalf("Invalid config:\n%v", err)
+	}
+
+	zapConfig := zap.NewProductionConfig()
+	level, _ := zap.ParseAtomicLevel(cfg.LogLevel)
+	zapConfig.Level = level
+	logger, _ := zapConfig.Build()
 	defer logger.Sync()
+	// The handlers and the request log write to the standard logger, route
+	// it through zap so log_level applies to them as well
+	log.SetFlags(0)
+	log.SetOutput(stdLogWriter{logger.WithOptions(zap.AddCallerSkip(3))})
+	middleware.DefaultLogger = middleware.RequestLogger(&middleware.DefaultLogFormatter{Logger: log.Default(), NoColor: true})
 
 	// Initialize database connection
-	db.InitFromEnv()
+	db.Init(cfg.Database)
 	defer db.CloseDB()
 
+	// Publish the domain events written to the outbox, and call the
+	// webhooks subscribed to them
+	workers, stopWorkers := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	brokers := events.Brokers{events.NewBroker(cfg.RedisAddr)}
+	if cfg.Features.Webhooks {
+		brokers = append(brokers, webhooks.Broker{})
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			webhooks.NewSender().Run(workers)
+		}()
+	}
+	relay := events.NewRelay(brokers)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		relay.Run(workers)
+	}()
+
 	// Initialize router
-	r := router.NewRouter()
+	r := router.NewRouter(cfg)
+
+	// Serve gRPC next to the HTTP API
+	var grpcServer *grpc.Server
+	var grpcHealth *health.Server
+	if cfg.Features.GRPC {
+		lis, err := net.Listen("tcp", cfg.GRPCAddr)
+		if err != nil {
+			log.Fatalf("Couldn't listen for gRPC: %v", err)
+		}
+		grpcServer, grpcHealth = router.NewGRPCServer(cfg.AuthnURL)
+		go func() {
+			logger.Info("Exercises gRPC service starting on " + cfg.GRPCAddr)
+			if err := grpcServer.Serve(lis); err != nil {
+				log.Fatal(err)
+			}
+		}()
+	}
+
+	srv := &http.Server{Addr: cfg.HTTPAddr, Handler: r}
+	go func() {
+		logger.Info("Exercises service starting on " + cfg.HTTPAddr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+
+	stop, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+	<-stop.Done()
+
+	// Fail the probes first, then wait for the requests in flight. Live
+	// workout connections aren't waited for, devices resume on another
+	// replica.
+	logger.Info("Exercises service shutting down", zap.Duration("timeout", cfg.ShutdownTimeout))
+	service.Drain()
+	ctx, cancelShutdown := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancelShutdown()
+
+	if grpcServer != nil {
+		grpcHealth.Shutdown()
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		defer func() {
+			select {
+			case <-stopped:
+			case <-ctx.Done():
+				grpcServer.Stop()
+			}
+		}()
+	}
+	if err := srv.Shutdown(ctx); err != nil {
+		logger.Error("Couldn't drain HTTP requests", zap.Error(err))
+	}
+
+	stopWorkers()
+	wg.Wait()
+}
+
+// stdLogWriter writes the lines of the standard logger to zap, at error
+// level for the "Error at ..." and "Couldn't ..." lines of the handlers and
+// at info level otherwise.
+type stdLogWriter struct {
+	logger *zap.Logger
+}
 
-	logger.Info("Exercises service starting on :8081")
-	log.Fatal(http.ListenAndServe(":8081", r))
+func (w stdLogWriter) Write(p []byte) (int, error) {
+	msg := strings.TrimSpace(string(p))
+	if strings.HasPrefix(msg, "Error") || strings.HasPrefix(msg, "Couldn't") {
+		w.logger.Error(msg)
+	} else {
+		w.logger.Info(msg)
+	}
+	return len(p), nil
 }
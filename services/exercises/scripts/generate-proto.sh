@@ -0,0 +1,6 @@
+#!/bin/sh
+# Needs protoc with protoc-gen-go v1.34.2 and protoc-gen-go-grpc v1.5.1 on the PATH
+protoc -I services/exercises/proto \
+  --go_out=services/exercises/internal/pb --go_opt=paths=source_relative \
+  --go-grpc_out=services/exercises/internal/pb --go-grpc_opt=paths=source_relative \
+  exercises.proto
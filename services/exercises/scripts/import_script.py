@@ -24,6 +24,44 @@ eq_mapper = {
 
 DB_URL = os.getenv("DATABASE_URL")
 
+
+def advance_exercise_ids(conn):
+    # Exercises are inserted with explicit ids, which doesn't advance the
+    # sequence custom exercises take their ids from.
+    conn.execute(text("SELECT setval('exercises_id_seq', (SELECT max(id) FROM exercises))"))
+
+
+def backfill_metadata(conn, exercises):
+    # Older databases were imported before the catalog kept the category,
+    # level, mechanic and force of each exercise and which of its muscles are
+    # primary, fill them in by name.
+    for ex in exercises:
+        conn.execute(
+            text("""
+                UPDATE exercises SET
+                  category = coalesce(exercises.category, :category),
+                  level = coalesce(exercises.level, :level),
+                  mechanic = coalesce(exercises.mechanic, :mechanic),
+                  force = coalesce(exercises.force, :force)
+                FROM exercise_names e_names
+                WHERE e_names.exercise_id = exercises.id
+                  AND e_names.name = :name
+            """),
+            {"name": ex["name"], "category": ex["category"], "level": ex["level"],
+             "mechanic": ex["mechanic"], "force": ex["force"]}
+        )
+        conn.execute(
+            text("""
+                UPDATE exercise_muscle SET is_primary = TRUE
+                FROM exercise_names e_names, muscles m
+                WHERE e_names.exercise_id = exercise_muscle.exercise_id
+                  AND m.id = exercise_muscle.muscle_id
+                  AND e_names.name = :name
+                  AND m.name = ANY(:muscles)
+            """),
+            {"name": ex["name"], "muscles": ex["primaryMuscles"]}
+        )
+
 def main():
     # Load exercises from JSON
     with open("../internal/db/data/exercises.json", "r") as f:
@@ -38,7 +76,10 @@ def main():
         result = conn.execute(text("SELECT COUNT(*) FROM exercises"))
         count = result.scalar()
         if count and count > 0:
-            print("Data Already exists, exiting.")
+            print("Data Already exists, backfilling metadata.")
+            backfill_metadata(conn, exercises)
+            advance_exercise_ids(conn)
+            conn.commit()
             return
         for idx, ex in enumerate(exercises):
             print('Inserting row: ', idx)
@@ -48,14 +89,19 @@ def main():
             if ex['equipment'] != None:
                 equipment = eq_mapper[ex['equipment']]
             instructions = ex['instructions']
+            category = ex['category']
+            level = ex['level']
+            mechanic = ex['mechanic']
+            force = ex['force']
             exercise_id = idx + 1  # Using integer IDs as per updated schema
             # 1. Insert into exercises table
             result = conn.execute(
                 text("""
-                    INSERT INTO exercises (id, instructions, equipment, visuals_id)
-                    VALUES (:id, :instructions, :equipment, NULL)
+                    INSERT INTO exercises (id, instructions, equipment, category, level, mechanic, force, visuals_id)
+                    VALUES (:id, :instructions, :equipment, :category, :level, :mechanic, :force, NULL)
                 """),
-                {"id": exercise_id, "instructions": instructions, "equipment": equipment}
+                {"id": exercise_id, "instructions": instructions, "equipment": equipment, "category": category,
+                 "level": level, "mechanic": mechanic, "force": force}
             )
             # 2. Insert into exercise_names table
             conn.execute(
@@ -85,12 +131,14 @@ def main():
                 # Link exercise and muscle
                 conn.execute(
                     text("""
-                        INSERT INTO exercise_muscle (exercise_id, muscle_id)
-                        VALUES (:exercise_id, :muscle_id)
+                        INSERT INTO exercise_muscle (exercise_id, muscle_id, is_primary)
+                        VALUES (:exercise_id, :muscle_id, :is_primary)
                         ON CONFLICT DO NOTHING
                     """),
-                    {"exercise_id": exercise_id, "muscle_id": muscle_id}
+                    {"exercise_id": exercise_id, "muscle_id": muscle_id,
+                     "is_primary": muscle in ex['primaryMuscles']}
                 )
+        advance_exercise_ids(conn)
         conn.commit()
         print("Import complete.")
 
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// userIDHeader carries the authenticated user's ID to the backend services.
+const userIDHeader = "X-User-ID"
+
+var errInvalidToken = errors.New("invalid token")
+
+var authnClient = &http.Client{Timeout: 5 * time.Second}
+
+// Authenticate resolves the bearer token of a request into a user ID using the
+// authn service and forwards it in the X-User-ID header. Requests without an
+// Authorization header pass through anonymously, and a client supplied
+// X-User-ID is always dropped so it can't be spoofed. WebSocket upgrades may
+// carry the token in the access_token query parameter instead.
+func Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Header.Del(userIDHeader)
+
+		authorization := r.Header.Get("Authorization")
+		if authorization == "" && isWebSocket(r) {
+			// Browsers can't set headers on WebSocket connections
+			if token := r.URL.Query().Get("access_token"); token != "" {
+				authorization = "Bearer " + token
+				query := r.URL.Query()
+				query.Del("access_token")
+				r.URL.RawQuery = query.Encode()
+			}
+		}
+		if authorization == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		userID, err := validateToken(r, authorization)
+		if errors.Is(err, errInvalidToken) {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if err != nil {
+			logger.Error("Failed to validate token", zap.Error(err))
+			http.Error(w, "Failed to validate token", http.StatusBadGateway)
+			return
+		}
+
+		r.Header.Set(userIDHeader, userID)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// validateToken asks the authn service for the subject of the token.
+func validateToken(r *http.Request, authorization string) (string, error) {
+	service := services["authn"]
+	target := "http://" + service.Host + ":" + service.Port + "/validate"
+
+	req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, target, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", authorization)
+
+	resp, err := authnClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return "", errInvalidToken
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.New("unexpected authn status: " + resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	userID := strings.TrimSpace(string(body))
+	if userID == "" {
+		return "", errInvalidToken
+	}
+	return userID, nil
+}
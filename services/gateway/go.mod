@@ -4,6 +4,7 @@ go 1.24.6
 
 require (
 	github.com/go-chi/chi/v5 v5.0.12
+	github.com/redis/go-redis/v9 v9.0.4
 	github.com/ulule/limiter/v3 v3.11.2
 	go.uber.org/zap v1.27.0
 )
@@ -12,6 +13,5 @@ require (
 	github.com/cespare/xxhash/v2 v2.2.0 // indirect
 	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
 	github.com/pkg/errors v0.9.1 // indirect
-	github.com/redis/go-redis/v9 v9.0.4 // indirect
 	go.uber.org/multierr v1.10.0 // indirect
 )
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// readinessUpstreams are the services the gateway can't serve requests
+// without, /readyz checks their /healthz.
+var readinessUpstreams = []string{"exercises", "authn"}
+
+// draining is set once the gateway is shutting down
+var draining atomic.Bool
+
+var healthClient = &http.Client{Timeout: 2 * time.Second}
+
+type readiness struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// Healthz reports that the process is up.
+func Healthz(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("ok"))
+}
+
+// Readyz reports whether the gateway can take requests: not once it is
+// shutting down, nor while Redis or an upstream service is unreachable.
+func Readyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+	defer cancel()
+
+	resp := readiness{Status: "ok", Checks: make(map[string]string)}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	check := func(name string, fn func(context.Context) error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := "ok"
+			if err := fn(ctx); err != nil {
+				result = err.Error()
+			}
+			mu.Lock()
+			resp.Checks[name] = result
+			mu.Unlock()
+		}()
+	}
+
+	check("redis", func(ctx context.Context) error {
+		if redisClient == nil {
+			return errors.New("not connected")
+		}
+		return redisClient.Ping(ctx).Err()
+	})
+	for _, name := range readinessUpstreams {
+		service := services[name]
+		check(name, func(ctx context.Context) error {
+			return checkUpstream(ctx, service)
+		})
+	}
+	wg.Wait()
+
+	status := http.StatusOK
+	for _, result := range resp.Checks {
+		if result != "ok" {
+			resp.Status = "unavailable"
+			status = http.StatusServiceUnavailable
+		}
+	}
+	if draining.Load() {
+		resp.Status = "shutting down"
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// checkUpstream asks a service whether it is up. Its liveness rather than
+// its readiness is checked, so a service draining on deploy doesn't take
+// every gateway replica out with it.
+func checkUpstream(ctx context.Context, service ServiceConfig) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+service.Host+":"+service.Port+"/healthz", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := healthClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return nil
+}
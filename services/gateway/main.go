@@ -1,8 +1,14 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -16,31 +22,68 @@ func main() {
 	InitLogger()
 	defer logger.Sync()
 
+	// Time given to in-flight requests once SIGTERM is received
+	shutdownTimeout := 15 * time.Second
+	if v := os.Getenv("GATEWAY_SHUTDOWN_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			log.Fatal("GATEWAY_SHUTDOWN_TIMEOUT must be a positive duration, e.g. 15s")
+		}
+		shutdownTimeout = d
+	}
+
 	r := chi.NewRouter()
 
 	// Middleware
 	r.Use(RequestLogger())
-	// Initialize and register rate limiter (10 reqs per minute per user)
-	if err := InitRateLimiter(); err != nil {
-		logger.Error("failed to initialize rate limiter", zap.Error(err))
-	} else {
-		r.Use(RateLimitMiddleware)
-	}
 	r.Use(middleware.Recoverer)
 
-	// Route all /api/* requests
-	r.Route("/api", func(r chi.Router) {
-		// Specific routes first (higher priority)
-		r.HandleFunc("/docs/*", createProxyHandler("docs"))
-		r.HandleFunc("/logger/*", createProxyHandler("logger"))
-		r.HandleFunc("/authn/*", createProxyHandler("authn"))
+	// Probes, neither rate limited nor authenticated
+	r.Get("/healthz", Healthz)
+	r.Get("/readyz", Readyz)
+
+	r.Group(func(r chi.Router) {
+		// Initialize and register rate limiter (10 reqs per minute per user)
+		if err := InitRateLimiter(); err != nil {
+			logger.Error("failed to initialize rate limiter", zap.Error(err))
+		} else {
+			r.Use(RateLimitMiddleware)
+		}
+		// Resolve bearer tokens into X-User-ID for the backend services
+		r.Use(Authenticate)
 
-		// Catch-all for all other /api/* requests to exercises service
-		r.HandleFunc("/*", createProxyHandler("exercises"))
+		// Route all /api/* requests
+		r.Route("/api", func(r chi.Router) {
+			// Specific routes first (higher priority)
+			r.HandleFunc("/docs/*", createProxyHandler("docs"))
+			r.HandleFunc("/logger/*", createProxyHandler("logger"))
+			r.HandleFunc("/authn/*", createProxyHandler("authn"))
+
+			// Catch-all for all other /api/* requests to exercises service
+			r.HandleFunc("/*", createProxyHandler("exercises"))
+		})
 	})
 
-	logger.Info("Gateway starting on :8080")
-	log.Fatal(http.ListenAndServe(":8080", r))
+	srv := &http.Server{Addr: ":8080", Handler: r}
+	go func() {
+		logger.Info("Gateway starting on :8080")
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+
+	stop, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+	<-stop.Done()
+
+	// Fail readiness first, then wait for the requests in flight
+	logger.Info("Gateway shutting down", zap.Duration("timeout", shutdownTimeout))
+	draining.Store(true)
+	ctx, cancelShutdown := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancelShutdown()
+	if err := srv.Shutdown(ctx); err != nil {
+		logger.Error("Failed to drain requests", zap.Error(err))
+	}
 }
 
 // proxy functions are implemented in proxy.go
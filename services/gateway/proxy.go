@@ -3,6 +3,8 @@ package main
 import (
 	"io"
 	"net/http"
+	"net/http/httputil"
+	"net/url"
 	"strings"
 
 	"go.uber.org/zap"
@@ -50,6 +52,11 @@ func createProxyHandler(serviceName string) http.HandlerFunc {
 }
 
 func proxyToService(w http.ResponseWriter, r *http.Request, target string) {
+	if isWebSocket(r) {
+		proxyWebSocket(w, r, target)
+		return
+	}
+
 	// Create a new request to the target service
 	req, err := http.NewRequest(r.Method, target, r.Body)
 	if err != nil {
@@ -99,3 +106,35 @@ func proxyToService(w http.ResponseWriter, r *http.Request, target string) {
 	// Write response body
 	w.Write(body)
 }
+
+// isWebSocket reports whether the client asks to upgrade to a WebSocket.
+func isWebSocket(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket") &&
+		strings.Contains(strings.ToLower(r.Header.Get("Connection")), "upgrade")
+}
+
+// proxyWebSocket hands a WebSocket upgrade to the target service and pipes
+// the connection both ways until either side closes it.
+func proxyWebSocket(w http.ResponseWriter, r *http.Request, target string) {
+	targetURL, err := url.Parse(target)
+	if err != nil {
+		logger.Error("Failed to parse target", zap.Error(err), zap.String("target", target))
+		http.Error(w, "Failed to create request", http.StatusInternalServerError)
+		return
+	}
+
+	proxy := &httputil.ReverseProxy{
+		Director: func(req *http.Request) {
+			req.URL.Scheme = targetURL.Scheme
+			req.URL.Host = targetURL.Host
+			req.URL.Path = targetURL.Path
+			req.URL.RawQuery = r.URL.RawQuery
+			req.Host = targetURL.Host
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			logger.Error("Failed to proxy WebSocket", zap.Error(err), zap.String("target", target))
+			http.Error(w, "Failed to send request", http.StatusBadGateway)
+		},
+	}
+	proxy.ServeHTTP(w, r)
+}
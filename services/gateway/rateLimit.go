@@ -16,6 +16,9 @@ import (
 // limiterInstance is initialized in InitRateLimiter
 var limiterInstance *limiterpkg.Limiter
 
+// redisClient backs the limiter, /readyz pings it
+var redisClient *goredis.Client
+
 // InitRateLimiter initializes a sliding-window limiter with 10 requests per minute
 // per-IP. It uses Redis as the backing store at redis:6379 so limits are shared
 // across gateway replicas.
@@ -28,17 +31,17 @@ func InitRateLimiter() error {
 
 	// Create go-redis client pointing to the k8s service name `redis:6379`.
 	// The zero value context is fine for initialization.
-	client := goredis.NewClient(&goredis.Options{
+	redisClient = goredis.NewClient(&goredis.Options{
 		Addr: "redis:6379",
 	})
 
 	// Test the connection briefly
 	ctx := context.Background()
-	if err := client.Ping(ctx).Err(); err != nil {
+	if err := redisClient.Ping(ctx).Err(); err != nil {
 		return err
 	}
 
-	store, err := redstore.NewStoreWithOptions(client, limiterpkg.StoreOptions{Prefix: "gateway_limiter"})
+	store, err := redstore.NewStoreWithOptions(redisClient, limiterpkg.StoreOptions{Prefix: "gateway_limiter"})
 	if err != nil {
 		return err
 	}